@@ -0,0 +1,91 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+)
+
+// CountValue runs the query as SELECT COUNT(*) and returns the number of
+// matching rows. Grouped and DISTINCT queries are counted from a subquery,
+// so they return the number of groups or distinct rows.
+func (b *Builder) CountValue(ctx context.Context) (int64, error) {
+	q, err := b.countQuery(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := q.aggregateInto(ctx, "COUNT(*)", &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// countQuery returns the query to select COUNT(*) from, without the
+// columns, orders and limit of b
+func (b *Builder) countQuery(ctx context.Context) (*Builder, error) {
+	q := b.aggregateQuery()
+	if q.distinct || len(q.distinctOn) > 0 || len(q.groups) > 0 {
+		// The selected columns define the distinct rows and must stay
+		// valid for GROUP BY
+		q.columns = slices.Clone(b.columns)
+		q.selectBindings = slices.Clone(b.selectBindings)
+		if err := q.materializeInModels(ctx); err != nil {
+			return nil, err
+		}
+		q = b.newQuery().FromSub(q, "aggregate")
+	}
+	return q, nil
+}
+
+// SumValue returns SUM(column) over the matching rows, 0 when there are none
+func (b *Builder) SumValue(ctx context.Context, column string) (float64, error) {
+	var sum sql.NullFloat64
+	if err := b.aggregateQuery().aggregateInto(ctx, "SUM("+b.quote(column)+")", &sum); err != nil {
+		return 0, err
+	}
+	return sum.Float64, nil
+}
+
+// AvgValue returns AVG(column) over the matching rows, NULL when there are none
+func (b *Builder) AvgValue(ctx context.Context, column string) (sql.NullFloat64, error) {
+	var avg sql.NullFloat64
+	err := b.aggregateQuery().aggregateInto(ctx, "AVG("+b.quote(column)+")", &avg)
+	return avg, err
+}
+
+// MaxValue returns MAX(column) as scanned by the driver, nil when no rows match
+func (b *Builder) MaxValue(ctx context.Context, column string) (interface{}, error) {
+	var max interface{}
+	err := b.aggregateQuery().aggregateInto(ctx, "MAX("+b.quote(column)+")", &max)
+	return max, err
+}
+
+// MinValue returns MIN(column) as scanned by the driver, nil when no rows match
+func (b *Builder) MinValue(ctx context.Context, column string) (interface{}, error) {
+	var min interface{}
+	err := b.aggregateQuery().aggregateInto(ctx, "MIN("+b.quote(column)+")", &min)
+	return min, err
+}
+
+// aggregateQuery copies the query without its columns, orders, limit and
+// offset, which don't change an aggregate; wheres, joins and groups are kept
+func (b *Builder) aggregateQuery() *Builder {
+	q := b.Clone()
+	q.columns = nil
+	q.selectBindings = nil
+	q.orders = nil
+	q.limit, q.offset = nil, nil
+	q.lock = lockNone
+	return q
+}
+
+// aggregateInto scans the aggregate expression of the first row into dest,
+// leaving dest unchanged when the query returns no rows
+func (b *Builder) aggregateInto(ctx context.Context, expression string, dest interface{}) error {
+	if err := b.ValueInto(ctx, expression, dest); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
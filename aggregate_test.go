@@ -0,0 +1,69 @@
+package qix
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateValues(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		switch query {
+		case "SELECT COUNT(*) FROM orders WHERE status = ? LIMIT ?":
+			return &MockResultSet{Columns: []string{"count"}, Rows: [][]interface{}{{int64(7)}}}, nil
+		case "SELECT SUM(total) FROM orders WHERE status = ? LIMIT ?":
+			return &MockResultSet{Columns: []string{"sum"}, Rows: [][]interface{}{{[]byte("125.50")}}}, nil
+		case "SELECT AVG(total) FROM orders WHERE status = ? LIMIT ?":
+			return &MockResultSet{Columns: []string{"avg"}, Rows: [][]interface{}{{nil}}}, nil
+		case "SELECT MAX(created_at) FROM orders WHERE status = ? LIMIT ?":
+			return &MockResultSet{Columns: []string{"max"}, Rows: [][]interface{}{{"2024-05-01"}}}, nil
+		}
+		return &MockResultSet{Columns: []string{"value"}}, nil
+	})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("orders").Select("id").Where("status", "=", "paid").OrderBy("id", "DESC").Limit(5)
+
+	if count, err := builder.CountValue(ctx); err != nil || count != 7 {
+		t.Errorf("Expected count 7, got %d (%v)", count, err)
+	}
+	if sum, err := builder.SumValue(ctx, "total"); err != nil || sum != 125.5 {
+		t.Errorf("Expected sum 125.5, got %v (%v)", sum, err)
+	}
+	if avg, err := builder.AvgValue(ctx, "total"); err != nil || avg.Valid {
+		t.Errorf("Expected a NULL average, got %v (%v)", avg, err)
+	}
+	if max, err := builder.MaxValue(ctx, "created_at"); err != nil || max != "2024-05-01" {
+		t.Errorf("Expected the max date, got %v (%v)", max, err)
+	}
+	// A grouped query over no rows returns none
+	if min, err := builder.Clone().GroupBy("customer_id").MinValue(ctx, "total"); err != nil || min != nil {
+		t.Errorf("Expected nil without rows, got %v (%v)", min, err)
+	}
+
+	if sql := builder.ToSQL(); sql != "SELECT id FROM orders WHERE status = ? ORDER BY id DESC LIMIT ?" {
+		t.Errorf("Aggregates changed the builder: %s", sql)
+	}
+	if got := builder.GetBindings(); !reflect.DeepEqual(got, []interface{}{"paid", 5}) {
+		t.Errorf("Aggregates changed the builder bindings: %v", got)
+	}
+}
+
+func TestCountValueGrouped(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"count"}, []interface{}{int64(3)})
+	defer mock.DB.Close()
+
+	count, err := New(mock.DB).Table("orders").Select("customer_id").Where("status", "=", "paid").
+		GroupBy("customer_id").CountValue(ctx)
+	if err != nil || count != 3 {
+		t.Fatalf("Expected 3 groups, got %d (%v)", count, err)
+	}
+
+	calls := mock.Calls()
+	expected := "SELECT COUNT(*) FROM (SELECT customer_id FROM orders WHERE status = ? GROUP BY customer_id) AS aggregate LIMIT ?"
+	if len(calls) != 1 || calls[0].Query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %+v", expected, calls)
+	}
+}
@@ -0,0 +1,271 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+)
+
+// ErrBatchWriterClosed is returned when adding rows to a closed batch writer
+var ErrBatchWriterClosed = errors.New("batch writer is closed")
+
+// BatchWriter buffers rows pushed one at a time and writes them in chunks
+// through BatchInsert, or Upsert when WithBatchUpsert is used. It is safe
+// for concurrent use.
+type BatchWriter struct {
+	builder  *Builder
+	size     int
+	interval time.Duration
+	onError  func(err error, rows []map[string]interface{})
+
+	upsert        bool
+	uniqueBy      []string
+	updateColumns []string
+
+	mu     sync.Mutex
+	rows   []map[string]interface{}
+	timer  *time.Timer
+	closed bool
+}
+
+// BatchOption configures a BatchWriter created with NewBatchWriter
+type BatchOption interface {
+	applyBatch(*BatchWriter)
+}
+
+// batchOptionFunc adapts a function to the BatchOption interface
+type batchOptionFunc func(*BatchWriter)
+
+func (f batchOptionFunc) applyBatch(w *BatchWriter) {
+	f(w)
+}
+
+// WithBatchUpsert writes batches with Upsert instead of BatchInsert
+func WithBatchUpsert(uniqueBy []string, updateColumns []string) BatchOption {
+	return batchOptionFunc(func(w *BatchWriter) {
+		w.upsert = true
+		w.uniqueBy = uniqueBy
+		w.updateColumns = updateColumns
+	})
+}
+
+// WithFlushInterval flushes buffered rows once they have waited for d,
+// even when the batch is not full
+func WithFlushInterval(d time.Duration) BatchOption {
+	return batchOptionFunc(func(w *BatchWriter) {
+		w.interval = d
+	})
+}
+
+// WithBatchErrorHandler calls fn with the error and the rows of every batch
+// that failed to be written, e.g. to send them to a dead-letter queue.
+// Failed rows are dropped from the writer once fn returns.
+func WithBatchErrorHandler(fn func(err error, rows []map[string]interface{})) BatchOption {
+	return batchOptionFunc(func(w *BatchWriter) {
+		w.onError = fn
+	})
+}
+
+// NewBatchWriter creates a writer that flushes a chunk into the builder's
+// table every batchSize rows. A batchSize below 1 is treated as 1.
+func NewBatchWriter(b *Builder, batchSize int, opts ...BatchOption) *BatchWriter {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	w := &BatchWriter{
+		builder: b,
+		size:    batchSize,
+		rows:    make([]map[string]interface{}, 0, batchSize),
+	}
+	for _, opt := range opts {
+		opt.applyBatch(w)
+	}
+	return w
+}
+
+// Add buffers a row and flushes the batch once it is full
+func (w *BatchWriter) Add(ctx context.Context, row map[string]interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrBatchWriterClosed
+	}
+
+	w.rows = append(w.rows, row)
+	if len(w.rows) >= w.size {
+		return w.flush(ctx)
+	}
+	if w.interval > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.interval, w.flushOnTimer)
+	}
+	return nil
+}
+
+// AddSeq adds every row produced by rows, stopping at the first error
+func (w *BatchWriter) AddSeq(ctx context.Context, rows iter.Seq[map[string]interface{}]) error {
+	for row := range rows {
+		if err := w.Add(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered rows immediately
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush(ctx)
+}
+
+// Close flushes the remaining rows and stops the writer. Closing twice is
+// a no-op.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(ctx)
+}
+
+// flushOnTimer flushes the rows that waited for the flush interval, errors
+// are only reported through the error handler
+func (w *BatchWriter) flushOnTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush(context.Background())
+}
+
+// flush writes and clears the buffered rows, callers must hold w.mu
+func (w *BatchWriter) flush(ctx context.Context) error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.rows) == 0 {
+		return nil
+	}
+
+	rows := w.rows
+	w.rows = make([]map[string]interface{}, 0, w.size)
+
+	var err error
+	if w.upsert {
+		err = w.builder.Upsert(ctx, rows, w.uniqueBy, w.updateColumns)
+	} else {
+		err = w.builder.BatchInsert(ctx, rows)
+	}
+	if err != nil && w.onError != nil {
+		w.onError(err, rows)
+	}
+	return err
+}
+
+// BatchError reports the chunk of a BatchInsert that failed and, when the
+// driver error names it, the offending row and unique key
+type BatchError struct {
+	ChunkIndex int    // Index of the failed chunk
+	ApproxRow  int    // Index in the inserted data of the offending row, -1 when unknown
+	Key        string // Unique key or constraint reported by the driver
+	Isolated   bool   // ApproxRow was isolated by BisectOnError and is exact
+	Err        error
+}
+
+func (e *BatchError) Error() string {
+	if e.ApproxRow < 0 {
+		return fmt.Sprintf("batch insert chunk %d: %v", e.ChunkIndex, e.Err)
+	}
+	return fmt.Sprintf("batch insert chunk %d, row %d: %v", e.ChunkIndex, e.ApproxRow, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchInsertOption configures a BatchInsert call
+type BatchInsertOption interface {
+	applyBatchInsert(*batchInsertConfig)
+}
+
+// batchInsertConfig holds the options of a BatchInsert call
+type batchInsertConfig struct {
+	chunkSize int
+	bisect    bool
+}
+
+// batchInsertOptionFunc adapts a function to the BatchInsertOption interface
+type batchInsertOptionFunc func(*batchInsertConfig)
+
+func (f batchInsertOptionFunc) applyBatchInsert(c *batchInsertConfig) {
+	f(c)
+}
+
+// WithInsertChunkSize inserts size rows per statement
+func WithInsertChunkSize(size int) BatchInsertOption {
+	return batchInsertOptionFunc(func(c *batchInsertConfig) {
+		c.chunkSize = size
+	})
+}
+
+// BisectOnError splits a failing chunk in halves until the offending rows
+// are isolated, inserting the valid rows and reporting a *BatchError for
+// each offending row, joined with errors.Join. A chunk of n rows with one
+// bad row costs about 2*log2(n) extra statements.
+func BisectOnError() BatchInsertOption {
+	return batchInsertOptionFunc(func(c *batchInsertConfig) {
+		c.bisect = true
+	})
+}
+
+// bisectInsert inserts the halves of rows, which failed with err, and
+// recurses into the halves that fail again
+func (b *Builder) bisectInsert(ctx context.Context, columns []string, rows []map[string]interface{}, chunk, offset int, err error) []error {
+	if len(rows) == 1 {
+		batchErr := newBatchError(chunk, offset, rows, err)
+		batchErr.ApproxRow, batchErr.Isolated = offset, true
+		return []error{batchErr}
+	}
+
+	var errs []error
+	mid := len(rows) / 2
+	halves := [][]map[string]interface{}{rows[:mid], rows[mid:]}
+	for i, half := range halves {
+		start := offset + i*mid
+		if err := b.insertRows(ctx, columns, half); err != nil {
+			errs = append(errs, b.bisectInsert(ctx, columns, half, chunk, start, err)...)
+		}
+	}
+	return errs
+}
+
+// newBatchError locates the row of rows, inserted from offset, whose value
+// is named in a duplicate key error
+func newBatchError(chunk, offset int, rows []map[string]interface{}, err error) *BatchError {
+	batchErr := &BatchError{ChunkIndex: chunk, ApproxRow: -1, Err: err}
+
+	dup, ok := parseDuplicateKey(err)
+	if !ok {
+		return batchErr
+	}
+	batchErr.Key = dup.key
+	if dup.entry == "" {
+		return batchErr
+	}
+
+	for i, row := range rows {
+		for _, value := range row {
+			if value != nil && fmt.Sprint(value) == dup.entry {
+				batchErr.ApproxRow = offset + i
+				return batchErr
+			}
+		}
+	}
+	return batchErr
+}
@@ -0,0 +1,230 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// execRecorder is a MockDB that records every executed statement
+type execRecorder struct {
+	MockDB
+	mu      sync.Mutex
+	queries []string
+	args    [][]interface{}
+	err     error
+}
+
+func newExecRecorder() *execRecorder {
+	r := &execRecorder{}
+	r.execFunc = func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.queries = append(r.queries, query)
+		r.args = append(r.args, args)
+		return MockResult{rowsAffected: 1}, r.err
+	}
+	return r
+}
+
+func (r *execRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.queries)
+}
+
+func TestBatchWriterFlushesOnSize(t *testing.T) {
+	ctx := context.Background()
+	db := newExecRecorder()
+	w := NewBatchWriter(New(db).Table("events"), 2)
+
+	for i := 1; i <= 5; i++ {
+		if err := w.Add(ctx, map[string]interface{}{"id": i}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if n := db.count(); n != 2 {
+		t.Fatalf("Expected 2 flushes before Close, got %d", n)
+	}
+	if db.queries[0] != "INSERT INTO events (id) VALUES (?), (?)" {
+		t.Errorf("Unexpected query: %s", db.queries[0])
+	}
+
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := db.count(); n != 3 {
+		t.Fatalf("Expected Close to flush the remaining row, got %d flushes", n)
+	}
+	if db.queries[2] != "INSERT INTO events (id) VALUES (?)" || db.args[2][0] != 5 {
+		t.Errorf("Unexpected final batch %s %v", db.queries[2], db.args[2])
+	}
+
+	if err := w.Close(ctx); err != nil {
+		t.Errorf("Second Close should be a no-op, got %v", err)
+	}
+	if err := w.Add(ctx, map[string]interface{}{"id": 6}); !errors.Is(err, ErrBatchWriterClosed) {
+		t.Errorf("Expected ErrBatchWriterClosed, got %v", err)
+	}
+	if n := db.count(); n != 3 {
+		t.Errorf("Expected no writes after Close, got %d flushes", n)
+	}
+}
+
+func TestBatchWriterFlushInterval(t *testing.T) {
+	ctx := context.Background()
+	db := newExecRecorder()
+	w := NewBatchWriter(New(db).Table("events"), 100, WithFlushInterval(10*time.Millisecond))
+	defer w.Close(ctx)
+
+	if err := w.Add(ctx, map[string]interface{}{"id": 1}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for db.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := db.count(); n != 1 {
+		t.Fatalf("Expected the timer to flush once, got %d flushes", n)
+	}
+}
+
+func TestBatchWriterConcurrentAdd(t *testing.T) {
+	ctx := context.Background()
+	db := newExecRecorder()
+	w := NewBatchWriter(New(db).Table("events"), 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 95; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := w.Add(ctx, map[string]interface{}{"id": i}); err != nil {
+				t.Errorf("Add failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	written := 0
+	for _, args := range db.args {
+		written += len(args)
+	}
+	if written != 95 || db.count() != 10 {
+		t.Errorf("Expected 95 rows in 10 batches, got %d rows in %d batches", written, db.count())
+	}
+}
+
+func TestBatchWriterUpsertAndErrorHandler(t *testing.T) {
+	ctx := context.Background()
+	db := newExecRecorder()
+	db.err = errors.New("deadlock")
+
+	var failed [][]map[string]interface{}
+	w := NewBatchWriter(New(db, PostgresDialect).Table("events"), 2,
+		WithBatchUpsert([]string{"id"}, nil),
+		WithBatchErrorHandler(func(err error, rows []map[string]interface{}) {
+			failed = append(failed, rows)
+		}))
+
+	rows := []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}}
+	if err := w.AddSeq(ctx, slices.Values(rows)); err == nil {
+		t.Fatal("Expected the failed flush to be returned")
+	}
+
+	expected := `INSERT INTO events (id, name) VALUES ($1, $2), ($3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name`
+	if db.queries[0] != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, db.queries[0])
+	}
+	if len(failed) != 1 || len(failed[0]) != 2 || !maps.Equal(failed[0][1], rows[1]) {
+		t.Fatalf("Expected the failed batch to reach the handler, got %v", failed)
+	}
+
+	// Failed rows are handed off, not retried
+	db.err = nil
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := db.count(); n != 1 {
+		t.Errorf("Expected failed rows to be dropped, got %d flushes", n)
+	}
+}
+
+// duplicateRecorder fails inserts binding the email dup@example.com like a
+// MySQL unique key
+func duplicateRecorder() (*execRecorder, *[]interface{}) {
+	db := newExecRecorder()
+	var inserted []interface{}
+	db.execFunc = func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		db.queries = append(db.queries, query)
+		if slices.Contains(args, interface{}("dup@example.com")) {
+			return nil, errors.New("Error 1062 (23000): Duplicate entry 'dup@example.com' for key 'users.email_unique'")
+		}
+		for i := 0; i < len(args); i += 2 {
+			inserted = append(inserted, args[i+1])
+		}
+		return MockResult{rowsAffected: int64(len(args) / 2)}, nil
+	}
+	return db, &inserted
+}
+
+func TestBatchInsertError(t *testing.T) {
+	ctx := context.Background()
+	rows := make([]map[string]interface{}, 8)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"email": "user" + string(rune('a'+i)) + "@example.com", "id": i + 1}
+	}
+	rows[5]["email"] = "dup@example.com"
+
+	db, inserted := duplicateRecorder()
+	err := New(db).Table("users").BatchInsert(ctx, rows, WithInsertChunkSize(4))
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *BatchError, got %v", err)
+	}
+	if batchErr.ChunkIndex != 1 || batchErr.ApproxRow != 5 || batchErr.Key != "users.email_unique" || batchErr.Isolated {
+		t.Errorf("Unexpected batch error %+v", batchErr)
+	}
+	if db.count() != 2 || len(*inserted) != 4 {
+		t.Errorf("Expected the first chunk inserted and a stop, got %d statements and %v", db.count(), *inserted)
+	}
+
+	db, inserted = duplicateRecorder()
+	err = New(db).Table("users").BatchInsert(ctx, rows, BisectOnError())
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *BatchError, got %v", err)
+	}
+	if batchErr.ChunkIndex != 0 || batchErr.ApproxRow != 5 || !batchErr.Isolated {
+		t.Errorf("Unexpected batch error %+v", batchErr)
+	}
+	// The failed statement, then two halves per level down to the bad row
+	if n := db.count(); n > 1+2*3 {
+		t.Errorf("Expected at most 7 statements, got %d", n)
+	}
+	if len(*inserted) != 7 || slices.Contains(*inserted, interface{}(6)) {
+		t.Errorf("Expected every row but the duplicate inserted, got %v", *inserted)
+	}
+}
+
+func TestBatchErrorPostgres(t *testing.T) {
+	rows := []map[string]interface{}{{"email": "a@example.com"}, {"email": "b@example.com"}}
+	err := errors.New(`pq: duplicate key value violates unique constraint "users_email_key" (Key (email)=(b@example.com) already exists.)`)
+	batchErr := newBatchError(2, 10, rows, err)
+	if batchErr.Key != "users_email_key" || batchErr.ApproxRow != 11 || !errors.Is(batchErr, err) {
+		t.Errorf("Unexpected batch error %+v", batchErr)
+	}
+	if got := newBatchError(0, 0, rows, errors.New("connection reset")); got.ApproxRow != -1 || got.Key != "" {
+		t.Errorf("Expected an unknown row, got %+v", got)
+	}
+}
@@ -0,0 +1,447 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Stress test composing nested builders whose clauses are added out of
+// lexical order, placeholders and arguments must still line up
+func TestNestedBuilderBindingOrder(t *testing.T) {
+	db := &MockDB{}
+
+	audit := New(db).Table("audit_log").
+		Select("1").
+		Where("audit_log.action", "=", "login").
+		WhereColumn("audit_log.user_id", "=", "sessions.user_id")
+
+	sessions := New(db).Table("sessions").
+		Select("user_id", "COUNT(*) AS logins").
+		GroupBy("user_id").
+		Having("COUNT(*)", ">", 2).
+		Where("sessions.created_at", ">", "2024-01-01").
+		WhereExists(audit)
+
+	orders := New(db).Table("orders").
+		Select("user_id", "SUM(total) AS spent").
+		Limit(100).
+		Where("status", "=", "paid").
+		GroupBy("user_id")
+
+	spenders := New(db).AutoAlias().
+		Where("sq1.spent", ">", 50).
+		FromSub(orders, "").
+		JoinSub(sessions, "", "sq1.user_id = sq2.user_id").
+		Select("sq1.user_id")
+
+	badges := New(db).Table("badges").
+		Select("COUNT(*)").
+		WhereColumn("badges.user_id", "=", "users.id").
+		Where("badges.kind", "=", "gold")
+
+	archived := New(db).Table("archived_users").
+		Select("id", "name", "0").
+		Where("archived_at", ">", "2023-01-01")
+
+	query := New(db).Table("users").AutoAlias().
+		Limit(10).
+		Where("users.active", "=", true).
+		JoinSub(spenders, "", "users.id = sq1.user_id").
+		Select("users.id", "users.name").
+		SubSelect(badges, "").
+		Union(archived)
+
+	expectedSQL := "SELECT users.id, users.name, (SELECT COUNT(*) FROM badges WHERE badges.user_id = users.id AND badges.kind = ?) as sq2 " +
+		"FROM users INNER JOIN (" +
+		"SELECT sq1.user_id FROM (SELECT user_id, SUM(total) AS spent FROM orders WHERE status = ? GROUP BY user_id LIMIT ?) AS sq1 " +
+		"INNER JOIN (SELECT user_id, COUNT(*) AS logins FROM sessions WHERE sessions.created_at > ? AND EXISTS " +
+		"(SELECT 1 FROM audit_log WHERE audit_log.action = ? AND audit_log.user_id = sessions.user_id) GROUP BY user_id HAVING COUNT(*) > ?) AS sq2 " +
+		"ON sq1.user_id = sq2.user_id WHERE sq1.spent > ?" +
+		") AS sq1 ON users.id = sq1.user_id WHERE users.active = ? LIMIT ? " +
+		"UNION SELECT id, name, 0 FROM archived_users WHERE archived_at > ?"
+
+	expectedBindings := []interface{}{
+		"gold",      // sub-select
+		"paid", 100, // FROM sub of the joined query
+		"2024-01-01", "login", 2, // joined sessions: WHERE, EXISTS, HAVING
+		50,           // joined query WHERE
+		true,         // outer WHERE
+		10,           // outer LIMIT
+		"2023-01-01", // UNION
+	}
+
+	for i := 0; i < 2; i++ {
+		sql := query.ToSQL()
+		if sql != expectedSQL {
+			t.Fatalf("Expected SQL:\n%s\nGot:\n%s", expectedSQL, sql)
+		}
+
+		bindings := query.GetBindings()
+		if !reflect.DeepEqual(bindings, expectedBindings) {
+			t.Fatalf("Expected bindings:\n%v\nGot:\n%v", expectedBindings, bindings)
+		}
+		if n := countPlaceholders(sql); n != len(bindings) {
+			t.Fatalf("Expected %d placeholders to match %d bindings", n, len(bindings))
+		}
+	}
+}
+
+func TestAutoAlias(t *testing.T) {
+	db := &MockDB{}
+	sub := New(db).Table("orders").Where("total", ">", 10)
+
+	sql := New(db).AutoAlias().FromSub(sub, "").Select("COUNT(*)").ToSQL()
+	if sql != "SELECT COUNT(*) FROM (SELECT * FROM orders WHERE total > ?) AS sq1" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	// Explicit aliases are kept and don't consume a generated name
+	sql = New(db).Table("users").AutoAlias().
+		JoinSub(sub, "o", "users.id = o.user_id").
+		LeftJoinSub(sub, "", "users.id = sq1.user_id").
+		ToSQL()
+	expected := "SELECT * FROM users INNER JOIN (SELECT * FROM orders WHERE total > ?) AS o ON users.id = o.user_id " +
+		"LEFT JOIN (SELECT * FROM orders WHERE total > ?) AS sq1 ON users.id = sq1.user_id"
+	if sql != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, sql)
+	}
+}
+
+func TestToSQLWithBindingsIsRepeatable(t *testing.T) {
+	archived := New(&MockDB{}).Table("archived_users").Where("active", "=", false).Limit(5)
+	builder := New(&MockDB{}, PostgresDialect).Table("users").
+		Where("active", "=", true).
+		Union(archived).
+		Limit(10).
+		Offset(20)
+
+	expectedSQL := "SELECT * FROM users WHERE active = $1 LIMIT $2 OFFSET $3 UNION SELECT * FROM archived_users WHERE active = $4 LIMIT $5"
+	expectedBindings := []interface{}{true, 10, 20, false, 5}
+
+	for i := 0; i < 3; i++ {
+		query, bindings := builder.ToSQLWithBindings()
+		if query != expectedSQL {
+			t.Errorf("Call %d: expected SQL %s, got %s", i, expectedSQL, query)
+		}
+		if !reflect.DeepEqual(bindings, expectedBindings) {
+			t.Errorf("Call %d: expected bindings %v, got %v", i, expectedBindings, bindings)
+		}
+	}
+}
+
+func TestRenderingDoesNotDuplicateBindings(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"count"}, []interface{}{int64(3)})
+	defer mock.DB.Close()
+
+	archived := New(mock.DB).Table("archived_users").Where("active", "=", false).Limit(5)
+	builder := New(mock.DB).Table("users").Where("active", "=", true).Union(archived).Limit(10).Offset(20)
+
+	for i := 0; i < 3; i++ {
+		builder.ToSQL()
+	}
+	builder.Debug()
+
+	rows, err := builder.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	rows.Close()
+
+	if _, err := New(mock.DB).Table("users").Where("active", "=", true).Paginate(2, 10); err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected 3 queries, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if n := countPlaceholders(call.Query); len(call.Args) != n {
+			t.Errorf("Expected %d args for %s, got %v", n, call.Query, call.Args)
+		}
+	}
+	if got := len(calls[0].Args); got != 5 {
+		t.Errorf("Expected 5 args for the union query, got %d", got)
+	}
+}
+
+// Bindings must follow the placeholders of the executed statement whatever
+// order the clauses were added in
+func TestWriteBindingOrderMatrix(t *testing.T) {
+	ctx := context.Background()
+	type call struct {
+		query string
+		args  []interface{}
+	}
+
+	tests := []struct {
+		name     string
+		run      func(db DB) error
+		expected call
+	}{
+		{
+			name: "UpdateWhereFirst",
+			run: func(db DB) error {
+				_, err := New(db).Table("users").Where("id", "=", 5).UpdateWithContext(ctx, map[string]interface{}{"name": "x"})
+				return err
+			},
+			expected: call{"UPDATE users SET name = ? WHERE id = ?", []interface{}{"x", 5}},
+		},
+		{
+			name: "UpdateWhereLast",
+			run: func(db DB) error {
+				_, err := New(db).Table("users").Update(map[string]interface{}{"name": "x"}).Where("id", "=", 5).UpdateWithContext(ctx, map[string]interface{}{"email": "e"})
+				return err
+			},
+			expected: call{"UPDATE users SET name = ?, email = ? WHERE id = ?", []interface{}{"x", "e", 5}},
+		},
+		{
+			name: "UpdateMixedConditions",
+			run: func(db DB) error {
+				_, err := New(db).Table("users").
+					WhereIn("role", "admin", "staff").
+					WhereBetween("age", 18, 65).
+					UpdateWithContext(ctx, map[string]interface{}{"active": false, "note": "n"})
+				return err
+			},
+			expected: call{"UPDATE users SET active = ?, note = ? WHERE role IN (?, ?) AND age BETWEEN ? AND ?",
+				[]interface{}{false, "n", "admin", "staff", 18, 65}},
+		},
+		{
+			name: "DeleteWithLimit",
+			run: func(db DB) error {
+				_, err := New(db).Table("logs").Limit(10).Where("level", "=", "debug").DeleteWithContext(ctx)
+				return err
+			},
+			expected: call{"DELETE FROM logs WHERE level = ? LIMIT ?", []interface{}{"debug", 10}},
+		},
+		{
+			name: "InsertAfterWhere",
+			run: func(db DB) error {
+				_, err := New(db).Table("users").Where("ignored", "=", 1).InsertGetId(ctx, map[string]interface{}{"name": "x"})
+				return err
+			},
+			expected: call{"INSERT INTO users (name) VALUES (?)", []interface{}{"x"}},
+		},
+		{
+			name: "BatchInsertAfterWhere",
+			run: func(db DB) error {
+				return New(db).Table("users").Where("ignored", "=", 1).BatchInsert(ctx, []map[string]interface{}{{"name": "x"}})
+			},
+			expected: call{"INSERT INTO users (name) VALUES (?)", []interface{}{"x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got call
+			db := &MockDB{
+				execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+					got = call{query, args}
+					return MockResult{lastID: 1, rowsAffected: 1}, nil
+				},
+			}
+			if err := tt.run(db); err != nil {
+				t.Fatalf("Statement failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v\nGot %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSelectBindingOrderMatrix(t *testing.T) {
+	builder := New(&MockDB{}).Table("orders").
+		Select("user_id").
+		Having("COUNT(*)", ">", 2).
+		GroupBy("user_id").
+		Limit(5).
+		Where("status", "=", "paid").
+		Join("users", "users.id = orders.user_id").
+		Where("total", ">", 10)
+
+	expected := "SELECT user_id FROM orders INNER JOIN users ON users.id = orders.user_id WHERE status = ? AND total > ? GROUP BY user_id HAVING COUNT(*) > ? LIMIT ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{"paid", 10, 2, 5}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	update := New(&MockDB{}).Table("users").Where("id", "=", 5).Update(map[string]interface{}{"name": "x"})
+	if bindings := update.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{"x", 5}) {
+		t.Errorf("Unexpected update bindings %v", bindings)
+	}
+}
+
+func TestOrderByRaw(t *testing.T) {
+	builder := New(&MockDB{}).Table("tickets").
+		Limit(10).
+		OrderBy("priority", "DESC").
+		OrderByRaw("FIELD(status, ?, ?, ?)", "new", "open", "closed").
+		Where("team_id", "=", 3).
+		Having("COUNT(*)", ">", 1).
+		GroupBy("status").
+		OrderBy("id", "ASC").
+		OrderByRaw("CASE WHEN owner_id = ? THEN 0 ELSE 1 END", 9).
+		Offset(20)
+
+	expected := "SELECT * FROM tickets WHERE team_id = ? GROUP BY status HAVING COUNT(*) > ? " +
+		"ORDER BY priority DESC, FIELD(status, ?, ?, ?), id ASC, CASE WHEN owner_id = ? THEN 0 ELSE 1 END LIMIT ? OFFSET ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{3, 1, "new", "open", "closed", 9, 10, 20}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	clone := builder.Clone()
+	clone.OrderByRaw("created_at DESC")
+	if bindings := clone.GetBindings(); len(bindings) != 8 {
+		t.Errorf("Unexpected clone bindings %v", bindings)
+	}
+
+	query, bindings := New(&MockDB{}).Table("jobs").Where("done", "=", true).
+		OrderByRaw("FIELD(queue, ?)", "low").Limit(100).deleteSQL()
+	if query != "DELETE FROM jobs WHERE done = ? ORDER BY FIELD(queue, ?) LIMIT ?" {
+		t.Errorf("Unexpected delete SQL: %s", query)
+	}
+	if !reflect.DeepEqual(bindings, []interface{}{true, "low", 100}) {
+		t.Errorf("Unexpected delete bindings %v", bindings)
+	}
+}
+
+func TestSelectRaw(t *testing.T) {
+	builder := New(&MockDB{}, WithQuotedIdentifiers(true)).Table("users").
+		Select("id").
+		Where("active", "=", true).
+		SelectRaw("COALESCE(nickname, ?) AS label", "anonymous").
+		AddSelectRaw("score * ?", 2).
+		AddSelectRaw("total")
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "SELECT `id`, COALESCE(nickname, ?) AS label, score * ?, total FROM `users` WHERE `active` = ?"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if !reflect.DeepEqual(bindings, []interface{}{"anonymous", 2, true}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	clone := builder.Clone().SelectRaw("name")
+	if sql := clone.ToSQL(); !strings.Contains(sql, ", total, name FROM") {
+		t.Errorf("Expected raw columns to survive Clone, got %s", sql)
+	}
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Clone changed the builder: %s", sql)
+	}
+}
+
+func TestGroupByRaw(t *testing.T) {
+	builder := New(&MockDB{}, WithQuotedIdentifiers(true)).Table("orders").
+		Having("COUNT(*)", ">", 2).
+		GroupByRaw("CASE WHEN total > ? THEN ? ELSE ? END", 100, "large", "small").
+		SelectRaw("CASE WHEN total > ? THEN ? ELSE ? END AS size", 100, "large", "small").
+		AddSelectRaw("COUNT(*)").
+		GroupBy("region").
+		OrderByRaw("FIELD(region, ?, ?)", "eu", "us").
+		Where("status", "=", "paid").
+		Limit(3)
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "SELECT CASE WHEN total > ? THEN ? ELSE ? END AS size, COUNT(*) FROM `orders` WHERE `status` = ? " +
+		"GROUP BY CASE WHEN total > ? THEN ? ELSE ? END, `region` HAVING COUNT(*) > ? ORDER BY FIELD(region, ?, ?) LIMIT ?"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	want := []interface{}{100, "large", "small", "paid", 100, "large", "small", 2, "eu", "us", 3}
+	if !reflect.DeepEqual(bindings, want) {
+		t.Errorf("Expected bindings %v, got %v", want, bindings)
+	}
+	if clone := builder.Clone(); !reflect.DeepEqual(clone.GetBindings(), want) {
+		t.Errorf("Clone lost group bindings: %v", clone.GetBindings())
+	}
+}
+
+func TestHavingRaw(t *testing.T) {
+	builder := New(&MockDB{}, WithQuotedIdentifiers(true)).Table("orders").
+		Select("customer_id").
+		Where("status", "=", "paid").
+		GroupBy("customer_id").
+		Having("customer_id", ">", 10).
+		HavingRaw("SUM(total) > ?", 500).
+		OrHavingRaw("COUNT(*) BETWEEN ? AND ?", 3, 5).
+		OrderBy("customer_id", "ASC")
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "SELECT `customer_id` FROM `orders` WHERE `status` = ? GROUP BY `customer_id` " +
+		"HAVING `customer_id` > ? AND SUM(total) > ? OR COUNT(*) BETWEEN ? AND ? ORDER BY `customer_id` ASC"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if want := []interface{}{"paid", 10, 500, 3, 5}; !reflect.DeepEqual(bindings, want) {
+		t.Errorf("Expected bindings %v, got %v", want, bindings)
+	}
+}
+
+func TestBetweenVariantsBindingOrder(t *testing.T) {
+	builder := New(&MockDB{}).Table("orders").
+		Where("status", "=", "paid").
+		WhereNotBetween("total", 10, 20).
+		OrWhereBetween("created_at", "2023-01-01", "2023-12-31").
+		OrWhereNotBetween("discount", 0, 5)
+
+	want := []interface{}{"paid", 10, 20, "2023-01-01", "2023-12-31", 0, 5}
+	if got := builder.GetBindings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected bindings %v, got %v", want, got)
+	}
+}
+
+func TestIncrementDecrement(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := New(mock.DB, PostgresDialect).Table("posts").Where("id", "=", 7).
+		Increment(ctx, "views", 2, map[string]interface{}{"updated_at": now, "last_viewer": "ada"}); err != nil {
+		t.Fatalf("Increment failed: %v", err)
+	}
+	if _, err := New(mock.DB).Table("users").Where("login_attempts", ">", 0).Decrement(ctx, "login_attempts"); err != nil {
+		t.Fatalf("Decrement failed: %v", err)
+	}
+	monotonic := time.Now()
+	if _, err := New(mock.DB).Table("scores").WhereIn("id", 3, 4).
+		Increment(ctx, "points", json.Number("5"), map[string]interface{}{"scored_at": monotonic}); err != nil {
+		t.Fatalf("Increment failed: %v", err)
+	}
+	if _, err := New(mock.DB).Table("users").Increment(ctx, "a", 1, 2); err == nil {
+		t.Error("Expected an error for two amounts")
+	}
+	if _, err := New(mock.DB).Table("users").Increment(ctx, "a", 1, map[string]interface{}{"tags": map[string]int{}}); !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("Expected ErrUnsupportedValue for an extra column, got %v", err)
+	}
+
+	calls := mock.Calls()
+	expected := []MockCall{
+		{Query: "UPDATE posts SET views = views + $1, last_viewer = $2, updated_at = $3 WHERE id = $4", Args: []interface{}{int64(2), "ada", now, int64(7)}},
+		{Query: "UPDATE users SET login_attempts = login_attempts - ? WHERE login_attempts > ?", Args: []interface{}{int64(1), int64(0)}},
+		{Query: "UPDATE scores SET points = points + ?, scored_at = ? WHERE id IN (?, ?)", Args: []interface{}{int64(5), monotonic.Round(0), int64(3), int64(4)}},
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %d statements, got %+v", len(expected), calls)
+	}
+	for i, want := range expected {
+		if calls[i].Query != want.Query || !reflect.DeepEqual(calls[i].Args, want.Args) {
+			t.Errorf("Statement %d: expected %+v, got %+v", i, want, calls[i])
+		}
+	}
+}
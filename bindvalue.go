@@ -0,0 +1,87 @@
+package qix
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrUnsupportedValue is returned when a bound value has a type no driver
+// can store, such as a channel, a func or a map
+var ErrUnsupportedValue = errors.New("unsupported value")
+
+// normalizeValue converts a bound value of column to a type every driver
+// binds the same way: monotonic clock readings are stripped from times,
+// json.Number becomes int64 or float64 and named string, number and bool
+// types become their underlying kind. Channels, funcs and maps are
+// rejected unless they implement driver.Valuer; other values are unchanged.
+func normalizeValue(column string, value interface{}) (interface{}, error) {
+	// Common types are returned without reflection
+	switch v := value.(type) {
+	case nil, string, int, int64, float64, bool, []byte:
+		return value, nil
+	case time.Time:
+		return v.Round(0), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: column %q: invalid number %q", ErrUnsupportedValue, column, v.String())
+		}
+		return f, nil
+	case driver.Valuer:
+		return value, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return nil, fmt.Errorf("%w: column %q: cannot bind %T", ErrUnsupportedValue, column, value)
+	}
+	if rv.Type().PkgPath() == "" {
+		// Unnamed types such as int32 or []int are left to the driver
+		return value, nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return value, nil
+}
+
+// bind normalizes a value bound for column, recording the error on the
+// builder so it is returned when the query runs
+func (b *Builder) bind(column string, value interface{}) interface{} {
+	normalized, err := normalizeValue(column, value)
+	if err != nil {
+		b.setErr(err)
+		return value
+	}
+	return normalized
+}
+
+// normalizeRow returns the values of row in the order of columns, normalized
+func normalizeRow(columns []string, row map[string]interface{}) ([]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		value, err := normalizeValue(column, row[column])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,122 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type status string
+type level int8
+type ratio float32
+type flag bool
+type counter uint16
+
+func TestNormalizeValue(t *testing.T) {
+	now := time.Now()
+	if now.Round(0) == now {
+		t.Fatal("Expected time.Now to carry a monotonic reading")
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"Nil", nil, nil},
+		{"String", "ann", "ann"},
+		{"Int", 7, 7},
+		{"UnnamedInt32", int32(7), int32(7)},
+		{"Bytes", []byte("raw"), []byte("raw")},
+		{"Slice", []int{1, 2}, []int{1, 2}},
+		{"Monotonic", now, now.Round(0)},
+		{"JSONInteger", json.Number("42"), int64(42)},
+		{"JSONFloat", json.Number("4.5"), 4.5},
+		{"NamedString", status("active"), "active"},
+		{"NamedInt", level(3), int64(3)},
+		{"NamedUint", counter(9), uint64(9)},
+		{"NamedFloat", ratio(0.5), 0.5},
+		{"NamedBool", flag(true), true},
+		{"Valuer", sql.NullString{String: "x", Valid: true}, sql.NullString{String: "x", Valid: true}},
+		{"Pointer", &now, &now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeValue("col", tt.value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeValueRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"Channel", make(chan int)},
+		{"Func", func() {}},
+		{"Map", map[string]interface{}{"a": 1}},
+		{"Complex", complex(1, 2)},
+		{"InvalidNumber", json.Number("1e")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeValue("payload", tt.value)
+			if !errors.Is(err, ErrUnsupportedValue) {
+				t.Fatalf("Expected ErrUnsupportedValue, got %v", err)
+			}
+			if !strings.Contains(err.Error(), `column "payload"`) {
+				t.Errorf("Expected the column in the error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteAndWhereBindingsNormalized(t *testing.T) {
+	ctx := context.Background()
+	var args []interface{}
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, a ...interface{}) (sql.Result, error) {
+			args = a
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	_, err := New(db).Table("users").Where("level", ">", level(2)).
+		UpdateWithContext(ctx, map[string]interface{}{"status": status("active"), "score": json.Number("10")})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if want := []interface{}{int64(10), "active", int64(2)}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Expected bindings %#v, got %#v", want, args)
+	}
+
+	rows := []map[string]interface{}{{"status": status("new")}, {"status": status("old")}}
+	if err := New(db).Table("users").BatchInsert(ctx, rows); err != nil {
+		t.Fatalf("BatchInsert failed: %v", err)
+	}
+	if want := []interface{}{"new", "old"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Expected bindings %#v, got %#v", want, args)
+	}
+
+	_, err = New(db).Table("users").InsertGetId(ctx, map[string]interface{}{"tags": map[string]int{"a": 1}})
+	if !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("Expected ErrUnsupportedValue from InsertGetId, got %v", err)
+	}
+	_, err = New(db).Table("users").WhereIn("id", 1, func() {}).Get(ctx)
+	if !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("Expected ErrUnsupportedValue from WhereIn, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package qix
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// killTimeout bounds the KILL QUERY statement sent on cancellation
+const killTimeout = 5 * time.Second
+
+// ConnectionIDer is implemented by connections that can report the id of
+// the server session their queries run on, as returned by CONNECTION_ID()
+type ConnectionIDer interface {
+	ConnectionID(ctx context.Context) (uint64, error)
+}
+
+// WithServerSideCancel stops running MySQL queries on the server when their
+// context is cancelled. It requires a DB implementing ConnectionIDer; the
+// KILL QUERY statement is sent through the same DB, which has to run it on
+// another connection while the query is busy. Other dialects are unaffected.
+func (b *Builder) WithServerSideCancel() *Builder {
+	b.serverCancel = true
+	return b
+}
+
+// watchCancel starts a watcher that kills the server side query when ctx
+// is cancelled while it runs. The returned stop function is called with the
+// query error once the driver returns; it waits for the watcher to exit and
+// returns ctx.Err() when the query was killed. Drivers usually return as
+// soon as the context is done, so a query that failed after cancellation
+// is killed by stop itself.
+func (b *Builder) watchCancel(ctx context.Context) (stop func(queryErr error) error) {
+	noop := func(error) error { return nil }
+
+	if !b.serverCancel || ctx.Done() == nil {
+		return noop
+	}
+	switch b.dialect.(type) {
+	case nil, mysqlDialect:
+	default:
+		return noop
+	}
+	conn, ok := b.db.(ConnectionIDer)
+	if !ok {
+		return noop
+	}
+	id, err := conn.ConnectionID(ctx)
+	if err != nil {
+		return noop
+	}
+
+	kill := func() {
+		killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+		if _, err := b.db.ExecContext(killCtx, fmt.Sprintf("KILL QUERY %d", id)); err != nil {
+			currentLogger().Warnf("KILL QUERY %d failed: %v", id, err)
+		}
+	}
+
+	done := make(chan struct{})
+	killed := false
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			select {
+			case <-done:
+				// Returned at the same time, stop decides
+				return
+			default:
+			}
+			kill()
+			killed = true
+		}
+	}()
+
+	return func(queryErr error) error {
+		close(done)
+		wg.Wait()
+		if !killed && queryErr != nil && ctx.Err() != nil {
+			kill()
+			killed = true
+		}
+		if killed {
+			return ctx.Err()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sessionDB reports a fixed connection id and blocks statements marked
+// SLEEP until their context is cancelled
+type sessionDB struct {
+	id uint64
+
+	mu    sync.Mutex
+	kills []string
+}
+
+func (d *sessionDB) ConnectionID(ctx context.Context) (uint64, error) {
+	return d.id, nil
+}
+
+func (d *sessionDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if strings.Contains(query, "SLEEP") {
+		<-ctx.Done()
+		return nil, errors.New("query interrupted")
+	}
+	return nil, nil
+}
+
+func (d *sessionDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if strings.HasPrefix(query, "KILL QUERY") {
+		d.mu.Lock()
+		d.kills = append(d.kills, query)
+		d.mu.Unlock()
+		return MockResult{}, nil
+	}
+	if strings.Contains(query, "SLEEP") {
+		<-ctx.Done()
+		return nil, errors.New("query interrupted")
+	}
+	return MockResult{rowsAffected: 1}, nil
+}
+
+func (d *sessionDB) Kills() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.kills...)
+}
+
+func TestServerSideCancelKillsQuery(t *testing.T) {
+	db := &sessionDB{id: 42}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := New(db).WithServerSideCancel().Table("reports").WhereRaw("SLEEP(60)").Get(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	kills := db.Kills()
+	if len(kills) != 1 || kills[0] != "KILL QUERY 42" {
+		t.Errorf("Expected a single KILL QUERY 42, got %v", kills)
+	}
+}
+
+func TestServerSideCancelExec(t *testing.T) {
+	db := &sessionDB{id: 7}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := New(db).WithServerSideCancel().Table("reports").WhereRaw("SLEEP(60)").DeleteWithContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if kills := db.Kills(); len(kills) != 1 || kills[0] != "KILL QUERY 7" {
+		t.Errorf("Expected a single KILL QUERY 7, got %v", kills)
+	}
+}
+
+func TestServerSideCancelNoKillOnSuccess(t *testing.T) {
+	db := &sessionDB{id: 42}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		if _, err := New(db).WithServerSideCancel().Table("reports").Where("id", "=", i).DeleteWithContext(ctx); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+	}
+	cancel()
+
+	if kills := db.Kills(); len(kills) != 0 {
+		t.Errorf("Expected no KILL statements, got %v", kills)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected watchers to exit, goroutines went from %d to %d", before, after)
+	}
+}
+
+func TestServerSideCancelSkippedForPostgres(t *testing.T) {
+	db := &sessionDB{id: 42}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	New(db, PostgresDialect).WithServerSideCancel().Table("reports").WhereRaw("SLEEP(60)").Get(ctx)
+	if kills := db.Kills(); len(kills) != 0 {
+		t.Errorf("Expected no KILL statements, got %v", kills)
+	}
+}
@@ -0,0 +1,124 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrChunkOrderRequired is returned by Chunk on a query without ORDER BY,
+// whose batches would overlap or skip rows
+var ErrChunkOrderRequired = errors.New("chunk requires an order by clause")
+
+// Chunk runs the query in batches of size rows using LIMIT and OFFSET,
+// calling fn with the rows of each batch. The matching rows are counted
+// once up front and iteration stops after the last batch or at the first
+// error returned by fn. The query must be ordered; its own Limit and Offset
+// are ignored. Rows are closed after fn returns.
+func (b *Builder) Chunk(ctx context.Context, size int, fn func(rows *sql.Rows) error) error {
+	if size < 1 {
+		return errors.New("chunk size must be positive")
+	}
+	if len(b.orders) == 0 {
+		return ErrChunkOrderRequired
+	}
+
+	sub := b.Clone()
+	sub.orders = nil
+	sub.limit, sub.offset = nil, nil
+	sub.lock = lockNone
+	if err := sub.materializeInModels(ctx); err != nil {
+		return err
+	}
+	total, err := ValueAs[int64](ctx, b.newQuery().FromSub(sub, "chunk"), "COUNT(*)")
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; int64(offset) < total; offset += size {
+		q := b.Clone().Limit(size).Offset(offset)
+		if err := q.chunk(ctx, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChunkById runs the query in batches of size rows using keyset
+// pagination on idColumn: each batch selects the rows after the last id of
+// the previous one, ordered by idColumn. Other orders, Limit and Offset are
+// ignored. Unlike Chunk it stays stable when rows are inserted or deleted
+// while iterating and doesn't slow down on deep batches.
+func (b *Builder) ChunkById(ctx context.Context, size int, idColumn string, fn func(rows *sql.Rows) error) error {
+	if size < 1 {
+		return errors.New("chunk size must be positive")
+	}
+
+	var last interface{}
+	for {
+		// Find the bounds of the next batch, then fetch exactly its rows
+		ids := b.Clone()
+		ids.columns = []string{idColumn + " AS chunk_id"}
+		ids.selectBindings = nil
+		ids.orders = nil
+		ids.offset = nil
+		ids.lock = lockNone
+		if last != nil {
+			ids.Where(idColumn, ">", last)
+		}
+		ids.OrderBy(idColumn, "ASC").Limit(size)
+		if err := ids.materializeInModels(ctx); err != nil {
+			return err
+		}
+
+		var count int64
+		var max interface{}
+		rows, err := b.newQuery().FromSub(ids, "chunk").Select("COUNT(*)", "MAX(chunk_id)").Get(ctx)
+		if err != nil {
+			return err
+		}
+		if rows.Next() {
+			err = rows.Scan(&count, &max)
+		} else {
+			err = rows.Err()
+		}
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("chunk bounds: %w", err)
+		}
+		if count == 0 {
+			return nil
+		}
+
+		q := b.Clone()
+		q.orders = nil
+		q.limit, q.offset = nil, nil
+		if last != nil {
+			q.Where(idColumn, ">", last)
+		}
+		q.Where(idColumn, "<=", max).OrderBy(idColumn, "ASC")
+		if err := q.chunk(ctx, fn); err != nil {
+			return err
+		}
+
+		if count < int64(size) {
+			return nil
+		}
+		last = max
+	}
+}
+
+// chunk runs the query and passes its rows to fn
+func (b *Builder) chunk(ctx context.Context, fn func(rows *sql.Rows) error) error {
+	rows, err := b.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if err := fn(rows); err != nil {
+		return err
+	}
+	return rows.Err()
+}
@@ -0,0 +1,153 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chunkIDs reads the id column of a batch
+func chunkIDs(rows *sql.Rows) ([]int64, error) {
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func TestChunk(t *testing.T) {
+	ctx := context.Background()
+	table := []int64{1, 2, 3, 4, 5}
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.HasPrefix(query, "SELECT COUNT(*) FROM") {
+			return &MockResultSet{Columns: []string{"count"}, Rows: [][]interface{}{{int64(len(table))}}}, nil
+		}
+		limit, offset := args[1].(int64), args[2].(int64)
+		set := &MockResultSet{Columns: []string{"id"}}
+		for i := offset; i < offset+limit && i < int64(len(table)); i++ {
+			set.Rows = append(set.Rows, []interface{}{table[i]})
+		}
+		return set, nil
+	})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("users").Select("id").Where("active", "=", true).OrderBy("id", "ASC")
+
+	var batches [][]int64
+	err := builder.Chunk(ctx, 2, func(rows *sql.Rows) error {
+		ids, err := chunkIDs(rows)
+		batches = append(batches, ids)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	if !reflect.DeepEqual(batches, [][]int64{{1, 2}, {3, 4}, {5}}) {
+		t.Errorf("Unexpected batches %v", batches)
+	}
+
+	calls := mock.Calls()
+	expected := []string{
+		"SELECT COUNT(*) FROM (SELECT id FROM users WHERE active = ?) AS chunk LIMIT ?",
+		"SELECT id FROM users WHERE active = ? ORDER BY id ASC LIMIT ? OFFSET ?",
+	}
+	if len(calls) != 4 || calls[0].Query != expected[0] || calls[1].Query != expected[1] {
+		t.Fatalf("Unexpected queries %v", calls)
+	}
+	if builder.limit != nil || builder.offset != nil {
+		t.Error("Chunk changed the builder")
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = builder.Chunk(ctx, 2, func(rows *sql.Rows) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Errorf("Expected the callback error to stop after 1 batch, got %v after %d", err, count)
+	}
+
+	if err := New(mock.DB).Table("users").Chunk(ctx, 2, func(*sql.Rows) error { return nil }); !errors.Is(err, ErrChunkOrderRequired) {
+		t.Errorf("Expected ErrChunkOrderRequired, got %v", err)
+	}
+}
+
+func TestChunkById(t *testing.T) {
+	ctx := context.Background()
+	table := []int64{3, 7, 8, 12, 20}
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		// Bounds query: [tenant, (last,) limit]
+		if strings.HasPrefix(query, "SELECT COUNT(*), MAX(chunk_id) FROM") {
+			last, limit := int64(0), args[len(args)-1].(int64)
+			if len(args) == 3 {
+				last = args[1].(int64)
+			}
+			var count, max int64
+			for _, id := range table {
+				if id > last && count < limit {
+					count, max = count+1, id
+				}
+			}
+			if count == 0 {
+				return &MockResultSet{Columns: []string{"count", "max"}, Rows: [][]interface{}{{int64(0), nil}}}, nil
+			}
+			return &MockResultSet{Columns: []string{"count", "max"}, Rows: [][]interface{}{{count, max}}}, nil
+		}
+
+		// Batch query: [tenant, (last,) max]
+		last, max := int64(0), args[len(args)-1].(int64)
+		if len(args) == 3 {
+			last = args[1].(int64)
+		}
+		set := &MockResultSet{Columns: []string{"id"}}
+		for _, id := range table {
+			if id > last && id <= max {
+				set.Rows = append(set.Rows, []interface{}{id})
+			}
+		}
+		return set, nil
+	})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("users").Select("id").Where("tenant_id", "=", 1).OrderBy("name", "DESC")
+
+	var batches [][]int64
+	err := builder.ChunkById(ctx, 2, "id", func(rows *sql.Rows) error {
+		ids, err := chunkIDs(rows)
+		batches = append(batches, ids)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ChunkById failed: %v", err)
+	}
+	if !reflect.DeepEqual(batches, [][]int64{{3, 7}, {8, 12}, {20}}) {
+		t.Errorf("Unexpected batches %v", batches)
+	}
+
+	calls := mock.Calls()
+	expected := []string{
+		"SELECT COUNT(*), MAX(chunk_id) FROM (SELECT id AS chunk_id FROM users WHERE tenant_id = ? ORDER BY id ASC LIMIT ?) AS chunk",
+		"SELECT id FROM users WHERE tenant_id = ? AND id <= ? ORDER BY id ASC",
+		"SELECT COUNT(*), MAX(chunk_id) FROM (SELECT id AS chunk_id FROM users WHERE tenant_id = ? AND id > ? ORDER BY id ASC LIMIT ?) AS chunk",
+		"SELECT id FROM users WHERE tenant_id = ? AND id > ? AND id <= ? ORDER BY id ASC",
+	}
+	if len(calls) != 6 {
+		t.Fatalf("Expected 6 queries, got %d: %v", len(calls), calls)
+	}
+	for i, query := range expected {
+		if calls[i].Query != query {
+			t.Errorf("Query %d: expected %s\nGot: %s", i, query, calls[i].Query)
+		}
+	}
+	if got := builder.GetBindings(); !reflect.DeepEqual(got, []interface{}{1}) {
+		t.Errorf("ChunkById changed the builder bindings: %v", got)
+	}
+}
@@ -0,0 +1,177 @@
+package qix
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores values for a limited time, such as the totals of
+// WithCachedCount. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+// PaginateOption configures a Paginate call
+type PaginateOption interface {
+	applyPaginate(*paginateConfig)
+}
+
+// paginateConfig holds the options of a Paginate call
+type paginateConfig struct {
+	countCache Cache
+	countTTL   time.Duration
+}
+
+// paginateOptionFunc adapts a function to the PaginateOption interface
+type paginateOptionFunc func(*paginateConfig)
+
+func (f paginateOptionFunc) applyPaginate(c *paginateConfig) {
+	f(c)
+}
+
+// WithCachedCount serves the Total of Paginate from cache for ttl, keyed
+// by the fingerprint and bindings of the count query, while the page itself
+// is always queried. Totals may be stale until the TTL expires or a write
+// on the table through qix invalidates them; Paginator.TotalFromCache
+// tells which pages used a cached total.
+func WithCachedCount(cache Cache, ttl time.Duration) PaginateOption {
+	return paginateOptionFunc(func(c *paginateConfig) {
+		c.countCache = cache
+		c.countTTL = ttl
+	})
+}
+
+// countGenerations is bumped for a table when its cached counts become
+// invalid, retiring every key built with the previous generation
+var (
+	countGenerationsMu sync.Mutex
+	countGenerations   = make(map[string]uint64)
+)
+
+// InvalidateCountCache discards the totals cached by WithCachedCount for
+// table. Inserts, updates and deletes run by a Builder or a PreparedQuery
+// invalidate their table automatically; call it after writes made outside
+// qix.
+func InvalidateCountCache(table string) {
+	countGenerationsMu.Lock()
+	defer countGenerationsMu.Unlock()
+	countGenerations[countCacheTable(table)]++
+}
+
+// countGeneration returns the current generation of table
+func countGeneration(table string) uint64 {
+	countGenerationsMu.Lock()
+	defer countGenerationsMu.Unlock()
+	return countGenerations[countCacheTable(table)]
+}
+
+// countCacheTable strips the alias of a table so writes and paginated
+// queries agree on its name
+func countCacheTable(table string) string {
+	cr, err := ParseColumnRef(table)
+	if err != nil || cr.IsExpression() {
+		return table
+	}
+	return strings.Join(cr.Parts, ".")
+}
+
+// countCacheKey identifies the count query of b in the current generation
+// of its table. The exact SQL is hashed: inlined literals and IN lists are
+// part of the filter, so a fingerprint would mix up totals.
+func (b *Builder) countCacheKey(query string, bindings []interface{}) string {
+	sum := sha256.New()
+	sum.Write([]byte(query))
+	for _, binding := range bindings {
+		fmt.Fprintf(sum, "\x00%T:%v", binding, binding)
+	}
+	return "qix:count:" + countCacheTable(b.table) + ":" +
+		strconv.FormatUint(countGeneration(b.table), 10) + ":" + hex.EncodeToString(sum.Sum(nil)[:16])
+}
+
+// paginateTotal counts the rows of the query, from cfg's cache when set
+func (b *Builder) paginateTotal(ctx context.Context, cfg paginateConfig) (total int64, cached bool, err error) {
+	countQuery, err := b.countQuery(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var key string
+	if cfg.countCache != nil {
+		keyQuery := countQuery.Clone()
+		keyQuery.selectOnly("COUNT(*)")
+		key = b.countCacheKey(keyQuery.ToSQLWithBindings())
+		if value, ok := cfg.countCache.Get(key); ok {
+			if total, ok := value.(int64); ok {
+				return total, true, nil
+			}
+		}
+	}
+
+	if err := countQuery.aggregateInto(ctx, "COUNT(*)", &total); err != nil {
+		return 0, false, err
+	}
+
+	if cfg.countCache != nil {
+		cfg.countCache.Set(key, total, cfg.countTTL)
+	}
+	return total, false, nil
+}
+
+// memoryCacheSweep is how often MemoryCache drops expired entries that
+// are never read again
+const memoryCacheSweep = time.Minute
+
+// MemoryCache is an in-process Cache
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	nextSweep time.Time
+}
+
+type memoryCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty in-process cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get returns the value stored for key unless it expired
+func (c *MemoryCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key during ttl
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.After(c.nextSweep) {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(memoryCacheSweep)
+	}
+	c.entries[key] = memoryCacheEntry{value: value, expires: now.Add(ttl)}
+}
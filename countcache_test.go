@@ -0,0 +1,108 @@
+package qix
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPaginateCachedCount(t *testing.T) {
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.HasPrefix(query, "SELECT COUNT(*)") {
+			return &MockResultSet{Columns: []string{"count"}, Rows: [][]interface{}{{int64(42)}}}, nil
+		}
+		return &MockResultSet{Columns: []string{"id"}, Rows: [][]interface{}{{int64(1)}}}, nil
+	})
+	defer mock.DB.Close()
+
+	cache := NewMemoryCache()
+	paginate := func(status string) *Paginator {
+		t.Helper()
+		page, err := New(mock.DB).Table("cached_orders").Where("status", "=", status).
+			Paginate(2, 10, WithCachedCount(cache, time.Minute))
+		if err != nil {
+			t.Fatalf("Paginate failed: %v", err)
+		}
+		return page
+	}
+	counts := func() int {
+		n := 0
+		for _, call := range mock.Calls() {
+			if strings.HasPrefix(call.Query, "SELECT COUNT(*)") {
+				n++
+			}
+		}
+		return n
+	}
+
+	for i, fromCache := range []bool{false, true, true} {
+		page := paginate("paid")
+		if page.Total != 42 || page.TotalFromCache != fromCache || len(page.Items) != 1 {
+			t.Errorf("Pagination %d: unexpected page %+v", i, page)
+		}
+	}
+	if n := counts(); n != 1 {
+		t.Errorf("Expected 1 count query across three paginations, got %d", n)
+	}
+	if n := len(mock.Calls()); n != 4 {
+		t.Errorf("Expected every page queried, got %d queries", n)
+	}
+
+	if paginate("refunded").TotalFromCache {
+		t.Error("Other bindings should not share the cached total")
+	}
+
+	if _, err := New(mock.DB).Table("cached_orders").Where("status", "=", "paid").DeleteWithContext(context.Background()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if paginate("paid").TotalFromCache {
+		t.Error("Expected the insert to invalidate the cached total")
+	}
+
+	InvalidateCountCache("cached_orders AS o")
+	if paginate("paid").TotalFromCache {
+		t.Error("Expected InvalidateCountCache to discard the cached total")
+	}
+
+	update, err := New(mock.DB).Table("cached_orders").Update(map[string]interface{}{"status": nil}).
+		Where("id", "=", 0).Prepare(context.Background())
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	defer update.Close()
+	if !paginate("paid").TotalFromCache {
+		t.Error("Expected preparing the update to keep the cached total")
+	}
+	if _, err := update.Exec(context.Background(), "paid", 1); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if paginate("paid").TotalFromCache {
+		t.Error("Expected the prepared update to invalidate the cached total")
+	}
+	if n := counts(); n != 5 {
+		t.Errorf("Expected 5 count queries, got %d", n)
+	}
+}
+
+func TestPaginateCachedCountInlinedLiterals(t *testing.T) {
+	mock := NewMockSQL().Returning([]string{"count"}, []interface{}{int64(3)})
+	defer mock.DB.Close()
+
+	cache := NewMemoryCache()
+	tests := []func() *Builder{
+		func() *Builder { return New(mock.DB).Table("literal_orders").WhereRaw("status = 'open'") },
+		func() *Builder { return New(mock.DB).Table("literal_orders").WhereRaw("status = 'closed'") },
+		func() *Builder { return New(mock.DB).Table("literal_orders").WhereIntegerInRaw("id", []int64{1, 2, 3}) },
+		func() *Builder { return New(mock.DB).Table("literal_orders").WhereIntegerInRaw("id", []int64{7}) },
+	}
+	for i, build := range tests {
+		page, err := build().Paginate(1, 10, WithCachedCount(cache, time.Minute))
+		if err != nil {
+			t.Fatalf("Paginate %d failed: %v", i, err)
+		}
+		if page.TotalFromCache {
+			t.Errorf("Filter %d should not share the total of another inlined literal", i)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package qix
+
+import "strings"
+
+// cte is a common table expression rendered before the query
+type cte struct {
+	name      string
+	query     string // Rendered with ? placeholders
+	bindings  []interface{}
+	recursive bool
+}
+
+// WithCTE defines a common table expression the query can select from,
+// rendering WITH name AS (query) before it. Each call adds another CTE in
+// order; their bindings come before those of the query. The name may carry
+// a column list, such as "totals(user_id, amount)".
+func (b *Builder) WithCTE(name string, query *Builder) *Builder {
+	b.ctes = append(b.ctes, cte{name: name, query: query.toSQL(), bindings: query.GetBindings()})
+	return b
+}
+
+// WithRecursiveCTE defines a recursive common table expression whose
+// recursive query selects from name, rendering
+// WITH RECURSIVE name AS (anchor UNION ALL recursive).
+func (b *Builder) WithRecursiveCTE(name string, anchor *Builder, recursive *Builder) *Builder {
+	b.ctes = append(b.ctes, cte{
+		name:      name,
+		query:     anchor.toSQL() + " UNION ALL " + recursive.toSQL(),
+		bindings:  append(anchor.GetBindings(), recursive.GetBindings()...),
+		recursive: true,
+	})
+	return b
+}
+
+// cteSQL renders the WITH clause, or nothing without CTEs
+func (b *Builder) cteSQL() string {
+	if len(b.ctes) == 0 {
+		return ""
+	}
+	keyword := "WITH "
+	definitions := make([]string, len(b.ctes))
+	for i, c := range b.ctes {
+		if c.recursive {
+			keyword = "WITH RECURSIVE "
+		}
+		definitions[i] = b.quote(c.name) + " AS (" + c.query + ")"
+	}
+	return keyword + strings.Join(definitions, ", ") + " "
+}
+
+// cteBindings returns the bindings of the CTEs in definition order
+func (b *Builder) cteBindings() []interface{} {
+	var bindings []interface{}
+	for _, c := range b.ctes {
+		bindings = append(bindings, c.bindings...)
+	}
+	return bindings
+}
@@ -0,0 +1,54 @@
+package qix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithCTE(t *testing.T) {
+	db := &MockDB{}
+	totals := New(db).Table("orders").Select("user_id", "SUM(amount) AS total").
+		Where("status", "=", "paid").GroupBy("user_id")
+	recent := New(db).Table("logins").Select("user_id").Where("created_at", ">", "2024-01-01")
+
+	builder := New(db).WithCTE("totals", totals).WithCTE("recent", recent).
+		Table("totals").Join("recent", "recent.user_id = totals.user_id").
+		Where("total", ">", 100).Limit(10)
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "WITH totals AS (SELECT user_id, SUM(amount) AS total FROM orders WHERE status = ? GROUP BY user_id), " +
+		"recent AS (SELECT user_id FROM logins WHERE created_at > ?) " +
+		"SELECT * FROM totals INNER JOIN recent ON recent.user_id = totals.user_id WHERE total > ? LIMIT ?"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if !reflect.DeepEqual(bindings, []interface{}{"paid", "2024-01-01", 100, 10}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	pg := New(db, PostgresDialect).WithCTE("totals", totals).Table("totals").Where("total", ">", 100)
+	if sql := pg.ToSQL(); sql != "WITH totals AS (SELECT user_id, SUM(amount) AS total FROM orders WHERE status = $1 GROUP BY user_id) SELECT * FROM totals WHERE total > $2" {
+		t.Errorf("Unexpected Postgres SQL: %s", sql)
+	}
+}
+
+func TestWithRecursiveCTE(t *testing.T) {
+	db := &MockDB{}
+	anchor := New(db).Table("categories").Select("id", "parent_id").Where("id", "=", 7)
+	recursive := New(db).Table("categories AS c").Select("c.id", "c.parent_id").
+		Join("tree", "c.parent_id = tree.id").Where("c.active", "=", true)
+
+	builder := New(db).WithRecursiveCTE("tree(id, parent_id)", anchor, recursive).
+		Table("tree").Select("id").Where("id", "!=", 7)
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "WITH RECURSIVE tree(id, parent_id) AS (SELECT id, parent_id FROM categories WHERE id = ? " +
+		"UNION ALL SELECT c.id, c.parent_id FROM categories AS c INNER JOIN tree ON c.parent_id = tree.id WHERE c.active = ?) " +
+		"SELECT id FROM tree WHERE id != ?"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if !reflect.DeepEqual(bindings, []interface{}{7, true, 7}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+}
@@ -0,0 +1,346 @@
+package qix
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCursorOrderRequired is returned by CursorPaginate on a query without ORDER BY
+	ErrCursorOrderRequired = errors.New("cursor pagination requires an order by clause")
+	// ErrInvalidCursor is returned for cursors that were tampered with, were
+	// issued for another query or by a process with another cursor secret
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// CursorPaginator is a page of results with opaque cursors to the
+// neighbouring pages
+type CursorPaginator struct {
+	Items          []map[string]interface{}
+	PerPage        int
+	NextCursor     string // Empty on the last page
+	PreviousCursor string // Empty on the first page
+	HasMore        bool   // Whether rows follow this page
+}
+
+// cursorPayload is the encrypted content of a cursor
+type cursorPayload struct {
+	Previous bool          `json:"p,omitempty"`
+	Query    string        `json:"q"` // Digest of the table and orders
+	Values   []cursorValue `json:"v"`
+}
+
+// cursorValue is an order column value with its Go type
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+var (
+	defaultCursorKey     []byte
+	defaultCursorKeyOnce sync.Once
+)
+
+// WithCursorSecret sets the secret cursors are encrypted with. Without it
+// a random per-process key is used, so cursors don't survive restarts and
+// aren't accepted by other instances.
+func WithCursorSecret(secret []byte) Option {
+	return optionFunc(func(b *Builder) {
+		key := sha256.Sum256(secret)
+		b.cursorKey = key[:]
+	})
+}
+
+// CursorPaginate returns a page of perPage rows using keyset pagination on
+// the ORDER BY columns. Pass an empty cursor for the first page and the
+// NextCursor or PreviousCursor of a page to move from it. Cursors are
+// encrypted and tied to the table and orders of the query, so they don't
+// reveal column values and are rejected with ErrInvalidCursor elsewhere.
+// Order columns must be selected and not NULL, and together identify a row.
+func (b *Builder) CursorPaginate(ctx context.Context, perPage int, cursor string) (*CursorPaginator, error) {
+	if perPage < 1 {
+		return nil, errors.New("per page must be positive")
+	}
+	if len(b.orders) == 0 {
+		return nil, ErrCursorOrderRequired
+	}
+	for _, o := range b.orders {
+		if o.random || o.raw {
+			return nil, errors.New("cursor pagination requires column orders")
+		}
+	}
+
+	var payload cursorPayload
+	if cursor != "" {
+		if err := b.decodeCursor(cursor, &payload); err != nil {
+			return nil, err
+		}
+	}
+
+	q := b.Clone()
+	q.limit, q.offset = nil, nil
+	if cursor != "" {
+		values := make([]interface{}, len(payload.Values))
+		for i, v := range payload.Values {
+			values[i] = v.decode()
+		}
+		q.whereAfter(values, payload.Previous)
+	}
+	if payload.Previous {
+		// Walk backwards from the cursor, the page is reversed below
+		for i := range q.orders {
+			q.orders[i].direction = reverseDirection(q.orders[i].direction)
+		}
+	}
+
+	rows, err := q.Limit(perPage + 1).Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := scanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > perPage
+	if more {
+		items = items[:perPage]
+	}
+	if payload.Previous {
+		slices.Reverse(items)
+	}
+
+	page := &CursorPaginator{Items: items, PerPage: perPage}
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	// Going forward there are earlier rows unless this is the first page,
+	// going backwards there are later rows we came from
+	hasNext, hasPrevious := more, cursor != ""
+	if payload.Previous {
+		hasNext, hasPrevious = true, more
+	}
+	if hasNext {
+		if page.NextCursor, err = b.encodeCursor(items[len(items)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrevious {
+		if page.PreviousCursor, err = b.encodeCursor(items[0], true); err != nil {
+			return nil, err
+		}
+	}
+	page.HasMore = hasNext
+	return page, nil
+}
+
+// whereAfter restricts the query to the rows after values in the order of
+// the query, or before them when previous is set:
+// (a > ?) OR (a = ? AND b > ?) OR ...
+func (b *Builder) whereAfter(values []interface{}, previous bool) {
+	var groups []string
+	var bindings []interface{}
+	for i, o := range b.orders {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, b.quote(b.orders[j].column)+" = ?")
+			bindings = append(bindings, values[j])
+		}
+
+		operator := ">"
+		if strings.EqualFold(o.direction, "DESC") != previous {
+			operator = "<"
+		}
+		parts = append(parts, b.quote(o.column)+" "+operator+" ?")
+		bindings = append(bindings, values[i])
+		groups = append(groups, "("+strings.Join(parts, " AND ")+")")
+	}
+	b.WhereRaw("("+strings.Join(groups, " OR ")+")", bindings...)
+}
+
+// reverseDirection swaps ASC and DESC
+func reverseDirection(direction string) string {
+	if strings.EqualFold(direction, "DESC") {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// cursorDigest identifies the table and orders a cursor belongs to
+func (b *Builder) cursorDigest() string {
+	var s strings.Builder
+	s.WriteString(b.table)
+	for _, o := range b.orders {
+		s.WriteString("|" + o.column + " " + strings.ToUpper(o.direction))
+	}
+	sum := sha256.Sum256([]byte(s.String()))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// encodeCursor encrypts the order column values of item
+func (b *Builder) encodeCursor(item map[string]interface{}, previous bool) (string, error) {
+	payload := cursorPayload{Previous: previous, Query: b.cursorDigest()}
+	for _, o := range b.orders {
+		value, ok := item[resultColumn(o.column)]
+		if !ok {
+			return "", fmt.Errorf("order column %s must be selected for cursor pagination", o.column)
+		}
+		v, err := encodeCursorValue(value)
+		if err != nil {
+			return "", fmt.Errorf("order column %s: %w", o.column, err)
+		}
+		payload.Values = append(payload.Values, v)
+	}
+
+	plain, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := b.cursorCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// decodeCursor decrypts and validates a cursor issued by encodeCursor
+func (b *Builder) decodeCursor(cursor string, payload *cursorPayload) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	gcm, err := b.cursorCipher()
+	if err != nil {
+		return err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return ErrInvalidCursor
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(plain, payload); err != nil {
+		return ErrInvalidCursor
+	}
+	if payload.Query != b.cursorDigest() || len(payload.Values) != len(b.orders) {
+		return fmt.Errorf("%w: issued for another query", ErrInvalidCursor)
+	}
+	return nil
+}
+
+// cursorCipher returns the AEAD cursors are sealed with
+func (b *Builder) cursorCipher() (cipher.AEAD, error) {
+	key := b.cursorKey
+	if key == nil {
+		defaultCursorKeyOnce.Do(func() {
+			defaultCursorKey = make([]byte, 32)
+			if _, err := io.ReadFull(rand.Reader, defaultCursorKey); err != nil {
+				panic(err)
+			}
+		})
+		key = defaultCursorKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// resultColumn returns the name a selected column has in the result set
+func resultColumn(column string) string {
+	cr, err := ParseColumnRef(column)
+	if err != nil {
+		return column
+	}
+	return cr.Name()
+}
+
+// encodeCursorValue keeps the type of driver values through JSON
+func encodeCursorValue(value interface{}) (cursorValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return cursorValue{Type: "null"}, nil
+	case int64:
+		return cursorValue{Type: "int", Value: strconv.FormatInt(v, 10)}, nil
+	case int:
+		return cursorValue{Type: "int", Value: strconv.Itoa(v)}, nil
+	case float64:
+		return cursorValue{Type: "float", Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case bool:
+		return cursorValue{Type: "bool", Value: strconv.FormatBool(v)}, nil
+	case string:
+		return cursorValue{Type: "string", Value: v}, nil
+	case []byte:
+		return cursorValue{Type: "string", Value: string(v)}, nil
+	case time.Time:
+		return cursorValue{Type: "time", Value: v.Format(time.RFC3339Nano)}, nil
+	}
+	return cursorValue{}, fmt.Errorf("unsupported cursor value %T", value)
+}
+
+// decode returns the typed value, values were validated when encoded
+func (v cursorValue) decode() interface{} {
+	switch v.Type {
+	case "int":
+		n, _ := strconv.ParseInt(v.Value, 10, 64)
+		return n
+	case "float":
+		f, _ := strconv.ParseFloat(v.Value, 64)
+		return f
+	case "bool":
+		return v.Value == "true"
+	case "string":
+		return v.Value
+	case "time":
+		t, _ := time.Parse(time.RFC3339Nano, v.Value)
+		return t
+	}
+	return nil
+}
+
+// scanMaps reads every row into a column name to value map and closes rows
+func scanMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		for i := range vals {
+			vals[i] = new(interface{})
+		}
+		if err := rows.Scan(vals...); err != nil {
+			return nil, err
+		}
+		item := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			item[col] = *vals[i].(*interface{})
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
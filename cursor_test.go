@@ -0,0 +1,160 @@
+package qix
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// cursorScoresMock serves rows of (id, score) for queries ordered by
+// score and id, applying the keyset condition rendered by whereAfter
+func cursorScoresMock() *MockSQL {
+	table := [][2]int64{{1, 50}, {2, 40}, {3, 40}, {4, 30}, {5, 20}, {6, 20}, {7, 10}}
+	return NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		backwards := strings.Contains(query, "ORDER BY score ASC, id DESC")
+		rows := make([][2]int64, 0, len(table))
+		for _, row := range table {
+			if len(args) == 4 {
+				score, id := args[0].(int64), args[2].(int64)
+				after := row[1] < score || (row[1] == score && row[0] > id)
+				if backwards {
+					after = row[1] > score || (row[1] == score && row[0] < id)
+				}
+				if !after {
+					continue
+				}
+			}
+			rows = append(rows, row)
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			less := rows[i][1] > rows[j][1] || (rows[i][1] == rows[j][1] && rows[i][0] < rows[j][0])
+			return less != backwards
+		})
+
+		limit := int(args[len(args)-1].(int64))
+		set := &MockResultSet{Columns: []string{"id", "score"}}
+		for i := 0; i < len(rows) && i < limit; i++ {
+			set.Rows = append(set.Rows, []interface{}{rows[i][0], rows[i][1]})
+		}
+		return set, nil
+	})
+}
+
+func cursorPageIDs(page *CursorPaginator) []int64 {
+	ids := make([]int64, len(page.Items))
+	for i, item := range page.Items {
+		ids[i] = item["id"].(int64)
+	}
+	return ids
+}
+
+func TestCursorPaginate(t *testing.T) {
+	ctx := context.Background()
+	mock := cursorScoresMock()
+	defer mock.DB.Close()
+
+	query := func() *Builder {
+		return New(mock.DB, WithCursorSecret([]byte("secret"))).Table("players").
+			Select("id", "score").OrderBy("score", "DESC").OrderBy("id", "ASC")
+	}
+
+	steps := []struct {
+		name     string
+		cursor   func(*CursorPaginator) string
+		ids      []int64
+		next     bool
+		previous bool
+	}{
+		{"First", func(*CursorPaginator) string { return "" }, []int64{1, 2, 3}, true, false},
+		{"Second", func(p *CursorPaginator) string { return p.NextCursor }, []int64{4, 5, 6}, true, true},
+		{"Last", func(p *CursorPaginator) string { return p.NextCursor }, []int64{7}, false, true},
+		{"Back", func(p *CursorPaginator) string { return p.PreviousCursor }, []int64{4, 5, 6}, true, true},
+		{"Back to first", func(p *CursorPaginator) string { return p.PreviousCursor }, []int64{1, 2, 3}, true, false},
+	}
+
+	var page *CursorPaginator
+	for _, step := range steps {
+		var err error
+		page, err = query().CursorPaginate(ctx, 3, step.cursor(page))
+		if err != nil {
+			t.Fatalf("%s: CursorPaginate failed: %v", step.name, err)
+		}
+		if ids := cursorPageIDs(page); !reflect.DeepEqual(ids, step.ids) {
+			t.Errorf("%s: expected %v, got %v", step.name, step.ids, ids)
+		}
+		if (page.NextCursor != "") != step.next || page.HasMore != step.next {
+			t.Errorf("%s: expected next %v, got %q (has more %v)", step.name, step.next, page.NextCursor, page.HasMore)
+		}
+		if (page.PreviousCursor != "") != step.previous {
+			t.Errorf("%s: expected previous %v, got %q", step.name, step.previous, page.PreviousCursor)
+		}
+	}
+
+	expected := "SELECT id, score FROM players WHERE ((score < ?) OR (score = ? AND id > ?)) ORDER BY score DESC, id ASC LIMIT ?"
+	if calls := mock.Calls(); calls[1].Query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, calls[1].Query)
+	}
+}
+
+func TestCursorPaginateInvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	mock := cursorScoresMock()
+	defer mock.DB.Close()
+
+	builder := func(secret string) *Builder {
+		return New(mock.DB, WithCursorSecret([]byte(secret))).Table("players").
+			Select("id", "score").OrderBy("score", "DESC").OrderBy("id", "ASC")
+	}
+
+	first, err := builder("secret").CursorPaginate(ctx, 3, "")
+	if err != nil {
+		t.Fatalf("CursorPaginate failed: %v", err)
+	}
+	// The cursor is sealed: it is neither JSON nor carries the plain digest,
+	// and sealing the same position twice gives different cursors
+	sealed, err := base64.RawURLEncoding.DecodeString(first.NextCursor)
+	if err != nil {
+		t.Fatalf("Expected a base64 cursor, got %q: %v", first.NextCursor, err)
+	}
+	if json.Valid(sealed) || bytes.Contains(sealed, []byte(builder("secret").cursorDigest())) {
+		t.Errorf("Cursor should not expose its payload: %q", sealed)
+	}
+	again, err := builder("secret").CursorPaginate(ctx, 3, "")
+	if err != nil {
+		t.Fatalf("CursorPaginate failed: %v", err)
+	}
+	if again.NextCursor == first.NextCursor {
+		t.Errorf("Expected a fresh nonce per cursor, got %s twice", first.NextCursor)
+	}
+
+	tampered := []byte(first.NextCursor)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		cursor  string
+	}{
+		{"Garbage", builder("secret"), "not a cursor"},
+		{"Tampered", builder("secret"), string(tampered)},
+		{"Other secret", builder("other"), first.NextCursor},
+		{"Other order", builder("secret").OrderBy("name", "ASC"), first.NextCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.builder.CursorPaginate(ctx, 3, tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+
+	if _, err := New(mock.DB).Table("players").CursorPaginate(ctx, 3, ""); !errors.Is(err, ErrCursorOrderRequired) {
+		t.Errorf("Expected ErrCursorOrderRequired, got %v", err)
+	}
+}
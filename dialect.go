@@ -0,0 +1,206 @@
+package qix
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL flavour a builder renders. Statements are built
+// with ? placeholders and rewritten for the dialect right before they run.
+type Dialect interface {
+	Option
+
+	// Placeholder returns the bind marker of the n-th binding, starting at 1
+	Placeholder(n int) string
+
+	// QuoteIdentifier quotes a single identifier part such as a table or column name
+	QuoteIdentifier(name string) string
+
+	// LimitClause renders the LIMIT/OFFSET clause with ? placeholders for
+	// the parts that are set
+	LimitClause(limit, offset bool) string
+
+	// UpsertClause renders the conflict handling appended to an INSERT so rows
+	// matching uniqueBy update the given columns, both already quoted
+	UpsertClause(uniqueBy, update []string) string
+}
+
+var (
+	// MySQLDialect uses ? placeholders, it is the default and also fits SQLite
+	MySQLDialect Dialect = mysqlDialect{}
+
+	// PostgresDialect uses $1, $2, ... placeholders
+	PostgresDialect Dialect = postgresDialect{}
+
+	// SQLiteDialect uses ? placeholders and double quoted identifiers
+	SQLiteDialect Dialect = sqliteDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (d mysqlDialect) apply(b *Builder) { b.dialect = d }
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// UpsertClause uses ON DUPLICATE KEY UPDATE, which applies to any unique
+// key. Without columns to update it renders nothing, Upsert then uses
+// INSERT IGNORE to keep the existing rows.
+func (mysqlDialect) UpsertClause(uniqueBy, update []string) string {
+	if len(update) == 0 {
+		return ""
+	}
+	sets := make([]string, len(update))
+	for i, column := range update {
+		sets[i] = column + " = VALUES(" + column + ")"
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// LimitClause uses the largest row count for an OFFSET without LIMIT,
+// which MySQL doesn't accept on its own
+func (mysqlDialect) LimitClause(limit, offset bool) string {
+	switch {
+	case limit && offset:
+		return " LIMIT ? OFFSET ?"
+	case limit:
+		return " LIMIT ?"
+	case offset:
+		return " LIMIT 18446744073709551615 OFFSET ?"
+	}
+	return ""
+}
+
+type postgresDialect struct{}
+
+func (d postgresDialect) apply(b *Builder) { b.dialect = d }
+
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) LimitClause(limit, offset bool) string {
+	return standardLimitClause(limit, offset)
+}
+
+func (postgresDialect) UpsertClause(uniqueBy, update []string) string {
+	return onConflictClause(uniqueBy, update)
+}
+
+type sqliteDialect struct{}
+
+func (d sqliteDialect) apply(b *Builder) { b.dialect = d }
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+
+func (sqliteDialect) QuoteIdentifier(name string) string {
+	return postgresDialect{}.QuoteIdentifier(name)
+}
+
+// LimitClause uses LIMIT -1 for an OFFSET without LIMIT, which SQLite requires
+func (sqliteDialect) LimitClause(limit, offset bool) string {
+	if offset && !limit {
+		return " LIMIT -1 OFFSET ?"
+	}
+	return standardLimitClause(limit, offset)
+}
+
+func (sqliteDialect) UpsertClause(uniqueBy, update []string) string {
+	return onConflictClause(uniqueBy, update)
+}
+
+// onConflictClause renders ON CONFLICT ... DO UPDATE using the excluded row
+func onConflictClause(uniqueBy, update []string) string {
+	clause := " ON CONFLICT (" + strings.Join(uniqueBy, ", ") + ")"
+	if len(update) == 0 {
+		return clause + " DO NOTHING"
+	}
+	sets := make([]string, len(update))
+	for i, column := range update {
+		sets[i] = column + " = EXCLUDED." + column
+	}
+	return clause + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// standardLimitClause renders LIMIT and OFFSET independently
+func standardLimitClause(limit, offset bool) string {
+	var clause string
+	if limit {
+		clause += " LIMIT ?"
+	}
+	if offset {
+		clause += " OFFSET ?"
+	}
+	return clause
+}
+
+// WithDialect sets the dialect of the builder, nil restores the default
+func WithDialect(d Dialect) Option {
+	return optionFunc(func(b *Builder) {
+		b.dialect = d
+	})
+}
+
+// limitClause renders LIMIT/OFFSET for the builder's dialect
+func (b *Builder) limitClause() string {
+	if b.dialect == nil {
+		return standardLimitClause(b.limit != nil, b.offset != nil)
+	}
+	return b.dialect.LimitClause(b.limit != nil, b.offset != nil)
+}
+
+// SetDialect changes the dialect of the builder, nil restores the default
+func (b *Builder) SetDialect(d Dialect) *Builder {
+	b.dialect = d
+	return b
+}
+
+// placeholder returns the bind marker of the n-th binding for the builder's dialect
+func (b *Builder) placeholder(n int) string {
+	if b.dialect == nil {
+		return "?"
+	}
+	return b.dialect.Placeholder(n)
+}
+
+// rebind rewrites the ? placeholders of query for the builder's dialect
+func (b *Builder) rebind(query string) string {
+	if b.dialect == nil {
+		return query
+	}
+	switch b.dialect.(type) {
+	case mysqlDialect, sqliteDialect:
+		return query
+	}
+	return replacePlaceholders(query, b.placeholder)
+}
+
+// replacePlaceholders replaces each ? outside of quoted literals with the
+// result of fn, called with the 1-based position of the placeholder
+func replacePlaceholders(query string, fn func(n int) string) string {
+	var out strings.Builder
+	out.Grow(len(query) + 8)
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			n++
+			out.WriteString(fn(n))
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
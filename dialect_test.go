@@ -0,0 +1,455 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDialectPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected string
+	}{
+		{
+			name:     "MySQL",
+			dialect:  MySQLDialect,
+			expected: "SELECT * FROM users WHERE status = ? AND id IN (?, ?) AND note = '?' LIMIT ? OFFSET ?",
+		},
+		{
+			name:     "Postgres",
+			dialect:  PostgresDialect,
+			expected: "SELECT * FROM users WHERE status = $1 AND id IN ($2, $3) AND note = '?' LIMIT $4 OFFSET $5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := New(&MockDB{}, tt.dialect).Table("users").
+				Where("status", "=", "active").
+				WhereIn("id", 1, 2).
+				WhereRaw("note = '?'").
+				Limit(10).
+				Offset(20).
+				ToSQL()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestSetDialect(t *testing.T) {
+	q := New(&MockDB{}).Table("t").Where("a", "=", 1).Where("b", "=", 2)
+
+	if sql := q.SetDialect(PostgresDialect).ToSQL(); sql != "SELECT * FROM t WHERE a = $1 AND b = $2" {
+		t.Errorf("Unexpected Postgres SQL: %s", sql)
+	}
+	if sql := q.SetDialect(nil).ToSQL(); sql != "SELECT * FROM t WHERE a = ? AND b = ?" {
+		t.Errorf("Unexpected default SQL: %s", sql)
+	}
+}
+
+func TestPostgresDialectNumberingAcrossClauses(t *testing.T) {
+	db := &MockDB{}
+	sub := New(db).Table("orders").Select("user_id", "SUM(total) AS spent").Where("status", "=", "paid").GroupBy("user_id")
+	q := New(db).SetDialect(PostgresDialect).Table("users").
+		Where("users.active", "=", true).
+		JoinSub(sub, "o", "o.user_id = users.id").
+		GroupBy("users.country").
+		Having("COUNT(*)", ">", 5).
+		Limit(10).
+		Offset(20)
+
+	expected := "SELECT * FROM users INNER JOIN (SELECT user_id, SUM(total) AS spent FROM orders WHERE status = $1 GROUP BY user_id) AS o " +
+		"ON o.user_id = users.id WHERE users.active = $2 GROUP BY users.country HAVING COUNT(*) > $3 LIMIT $4 OFFSET $5"
+	if sql := q.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+
+	bindings := q.GetBindings()
+	want := []interface{}{"paid", true, 5, 10, 20}
+	if len(bindings) != len(want) {
+		t.Fatalf("Expected %d bindings, got %v", len(want), bindings)
+	}
+	for i := range want {
+		if bindings[i] != want[i] {
+			t.Errorf("Binding $%d: expected %v, got %v", i+1, want[i], bindings[i])
+		}
+	}
+}
+
+func TestPostgresDialectSubqueries(t *testing.T) {
+	db := &MockDB{}
+	sub := New(db, PostgresDialect).Table("orders").Select("user_id").Where("total", ">", 100)
+	q := New(db, PostgresDialect).Table("users").
+		JoinSub(sub, "o", "o.user_id = users.id").
+		Where("users.active", "=", true).
+		Union(New(db, PostgresDialect).Table("admins").Where("level", "=", 3))
+
+	expected := "SELECT * FROM users INNER JOIN (SELECT user_id FROM orders WHERE total > $1) AS o ON o.user_id = users.id " +
+		"WHERE users.active = $2 UNION SELECT * FROM admins WHERE level = $3"
+	if got := q.ToSQL(); got != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, got)
+	}
+}
+
+func TestPostgresDialectWrites(t *testing.T) {
+	ctx := context.Background()
+	var queries []string
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			queries = append(queries, query)
+			return MockResult{lastID: 1, rowsAffected: 1}, nil
+		},
+	}
+
+	if _, err := New(db, PostgresDialect).Table("users").InsertGetId(ctx, map[string]interface{}{"name": "ann"}); err != nil {
+		t.Fatalf("InsertGetId failed: %v", err)
+	}
+	if _, err := New(db, PostgresDialect).Table("users").Where("id", "=", 1).UpdateWithContext(ctx, map[string]interface{}{"name": "ben"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := New(db, PostgresDialect).Table("users").Where("id", "=", 1).DeleteWithContext(ctx); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	expected := []string{
+		"INSERT INTO users (name) VALUES ($1)",
+		"UPDATE users SET name = $1 WHERE id = $2",
+		"DELETE FROM users WHERE id = $1",
+	}
+	if len(queries) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(queries))
+	}
+	for i := range expected {
+		if queries[i] != expected[i] {
+			t.Errorf("Expected SQL: %s\nGot: %s", expected[i], queries[i])
+		}
+	}
+}
+
+func TestPostgresDialectPrepare(t *testing.T) {
+	pq, err := New(&MockDB{}, PostgresDialect).Table("users").Where("id", "=", 0).Where("name", "=", "").Prepare(context.Background())
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if pq.SQL() != "SELECT * FROM users WHERE id = $1 AND name = $2" {
+		t.Errorf("Unexpected SQL: %s", pq.SQL())
+	}
+	if pq.NumInput() != 2 {
+		t.Errorf("Expected 2 inputs, got %d", pq.NumInput())
+	}
+}
+
+func TestPostgresDialectModel(t *testing.T) {
+	mock := NewMockSQL().Returning([]string{"id", "article_id", "text"}, []interface{}{int64(1), int64(2), "hello"})
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, JoinComment{}, PostgresDialect)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if _, err := model.Find(context.Background(), 1); err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 || calls[0].Query != "SELECT * FROM join_comment WHERE id = $1 LIMIT $2" {
+		t.Errorf("Unexpected queries: %+v", calls)
+	}
+}
+
+func TestPostgresDialectDynamicPlaceholders(t *testing.T) {
+	ctx := context.Background()
+	var queries []string
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			queries = append(queries, query)
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	rows := []map[string]interface{}{{"name": "ann"}, {"name": "ben"}, {"name": "cid"}}
+	if err := New(db, PostgresDialect).Table("users").BatchInsert(ctx, rows); err != nil {
+		t.Fatalf("BatchInsert failed: %v", err)
+	}
+
+	updates := []map[string]interface{}{{"id": 1, "name": "ann"}, {"id": 2, "name": "ben"}}
+	if err := New(db, PostgresDialect).Table("users").BulkUpdate(ctx, updates, "id"); err != nil {
+		t.Fatalf("BulkUpdate failed: %v", err)
+	}
+
+	expected := []string{
+		"INSERT INTO users (name) VALUES ($1), ($2), ($3)",
+		"UPDATE users SET name = CASE id WHEN $1 THEN $2 WHEN $3 THEN $4 END WHERE id IN ($5,$6)",
+	}
+	if len(queries) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(queries))
+	}
+	for i := range expected {
+		if queries[i] != expected[i] {
+			t.Errorf("Expected SQL: %s\nGot: %s", expected[i], queries[i])
+		}
+	}
+
+	ids := make([]interface{}, 12)
+	for i := range ids {
+		ids[i] = i
+	}
+	sql := New(db, PostgresDialect).Table("users").WhereIn("id", ids...).Where("active", "=", true).ToSQL()
+	if !strings.HasSuffix(sql, "$11, $12) AND active = $13") {
+		t.Errorf("Unexpected WhereIn numbering: %s", sql)
+	}
+}
+
+func TestDebugWithDialect(t *testing.T) {
+	build := func(d Dialect) *Builder {
+		return New(&MockDB{}, d).Table("users").
+			Where("name", "=", "who?").
+			WhereRaw("note <> '?'").
+			WhereIn("id", 1, 2)
+	}
+
+	expected := "SELECT * FROM users WHERE name = who? AND note <> '?' AND id IN (1, 2)"
+	for _, d := range []Dialect{MySQLDialect, PostgresDialect} {
+		if got := build(d).Debug(); got != expected {
+			t.Errorf("Expected: %s\nGot: %s", expected, got)
+		}
+	}
+}
+
+func TestQuotedIdentifiers(t *testing.T) {
+	build := func(d Dialect) *Builder {
+		return New(&MockDB{}, d, WithQuotedIdentifiers(true)).
+			Table("group as g").
+			Select("g.order", "g.*", "COUNT(id) as total", "users.name AS owner").
+			Join("users", "users.id = g.owner_id").
+			Where("g.order", ">", 1).
+			WhereColumn("g.select", "<>", "users.key").
+			WhereNull("g.deleted_at").
+			WhereIn("g.desc", "a", "b").
+			GroupBy("g.order").
+			OrderBy("g.order", "DESC")
+	}
+
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{
+			dialect: MySQLDialect,
+			expected: "SELECT `g`.`order`, `g`.*, COUNT(id) as total, `users`.`name` AS `owner` FROM `group` as `g` " +
+				"INNER JOIN `users` ON `users`.`id` = `g`.`owner_id` " +
+				"WHERE `g`.`order` > ? AND `g`.`select` <> `users`.`key` AND `g`.`deleted_at` IS NULL AND `g`.`desc` IN (?, ?) " +
+				"GROUP BY `g`.`order` ORDER BY `g`.`order` DESC",
+		},
+		{
+			dialect: PostgresDialect,
+			expected: `SELECT "g"."order", "g".*, COUNT(id) as total, "users"."name" AS "owner" FROM "group" as "g" ` +
+				`INNER JOIN "users" ON "users"."id" = "g"."owner_id" ` +
+				`WHERE "g"."order" > $1 AND "g"."select" <> "users"."key" AND "g"."deleted_at" IS NULL AND "g"."desc" IN ($2, $3) ` +
+				`GROUP BY "g"."order" ORDER BY "g"."order" DESC`,
+		},
+	}
+
+	for _, tt := range tests {
+		if sql := build(tt.dialect).ToSQL(); sql != tt.expected {
+			t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+		}
+	}
+}
+
+func TestQuotedIdentifiersDisabledByDefault(t *testing.T) {
+	sql := New(&MockDB{}, MySQLDialect).Table("users").Select("name").Where("id", "=", 1).ToSQL()
+	if sql != "SELECT name FROM users WHERE id = ?" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+}
+
+func TestDialectLimitClause(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{nil, "SELECT * FROM users OFFSET ?"},
+		{MySQLDialect, "SELECT * FROM users LIMIT 18446744073709551615 OFFSET ?"},
+		{PostgresDialect, "SELECT * FROM users OFFSET $1"},
+	}
+
+	for _, tt := range tests {
+		if sql := New(&MockDB{}, WithDialect(tt.dialect)).Table("users").Offset(20).ToSQL(); sql != tt.expected {
+			t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+		}
+	}
+}
+
+func TestQuotedIdentifiersWrites(t *testing.T) {
+	ctx := context.Background()
+	var queries []string
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			queries = append(queries, query)
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	q := func() *Builder { return New(db, SQLiteDialect, WithQuotedIdentifiers(true)).Table("group") }
+	if _, err := q().InsertGetId(ctx, map[string]interface{}{"order": 1}); err != nil {
+		t.Fatalf("InsertGetId failed: %v", err)
+	}
+	if _, err := q().Where("key", "=", 1).UpdateWithContext(ctx, map[string]interface{}{"order": 2}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := q().Where("key", "=", 1).DeleteWithContext(ctx); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := q().BatchInsert(ctx, []map[string]interface{}{{"order": 1}, {"order": 2}}); err != nil {
+		t.Fatalf("BatchInsert failed: %v", err)
+	}
+	if err := q().BulkUpdate(ctx, []map[string]interface{}{{"key": 1, "order": 3}}, "key"); err != nil {
+		t.Fatalf("BulkUpdate failed: %v", err)
+	}
+
+	expected := []string{
+		`INSERT INTO "group" ("order") VALUES (?)`,
+		`UPDATE "group" SET "order" = ? WHERE "key" = ?`,
+		`DELETE FROM "group" WHERE "key" = ?`,
+		`INSERT INTO "group" ("order") VALUES (?), (?)`,
+		`UPDATE "group" SET "order" = CASE "key" WHEN ? THEN ? END WHERE "key" IN (?)`,
+	}
+	if len(queries) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(queries))
+	}
+	for i := range expected {
+		if queries[i] != expected[i] {
+			t.Errorf("Expected SQL: %s\nGot: %s", expected[i], queries[i])
+		}
+	}
+
+	pq, err := New(db, MySQLDialect, WithQuotedIdentifiers(true)).Table("group").
+		Update(map[string]interface{}{"order": nil}).Where("key", "=", 0).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if pq.SQL() != "UPDATE `group` SET `order` = ? WHERE `key` = ?" {
+		t.Errorf("Unexpected prepared SQL: %s", pq.SQL())
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		ref      string
+		expected string
+	}{
+		{nil, "order", "`order`"},
+		{MySQLDialect, "shop.users.id", "`shop`.`users`.`id`"},
+		{PostgresDialect, "users.*", `"users".*`},
+		{SQLiteDialect, "users u", `"users" "u"`},
+		{PostgresDialect, `we"ird`, `we"ird`},
+		{PostgresDialect, "COUNT(*) as c", "COUNT(*) as c"},
+		{MySQLDialect, "*", "*"},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteIdentifier(tt.dialect, tt.ref); got != tt.expected {
+			t.Errorf("QuoteIdentifier(%q) = %s, expected %s", tt.ref, got, tt.expected)
+		}
+	}
+	if got := SQLiteDialect.QuoteIdentifier(`we"ird`); got != `"we""ird"` {
+		t.Errorf("Expected embedded quotes to be doubled, got %s", got)
+	}
+}
+
+func TestDeleteWithLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected string
+	}{
+		{
+			name:     "MySQL",
+			dialect:  MySQLDialect,
+			expected: "DELETE FROM logs WHERE created_at < ? ORDER BY id ASC LIMIT ?",
+		},
+		{
+			name:     "Postgres",
+			dialect:  PostgresDialect,
+			expected: "DELETE FROM logs WHERE ctid IN (SELECT ctid FROM logs WHERE created_at < $1 ORDER BY id ASC LIMIT $2)",
+		},
+		{
+			name:     "SQLite",
+			dialect:  SQLiteDialect,
+			expected: "DELETE FROM logs WHERE rowid IN (SELECT rowid FROM logs WHERE created_at < ? ORDER BY id ASC LIMIT ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			var args []interface{}
+			db := &MockDB{
+				execFunc: func(ctx context.Context, q string, a ...interface{}) (sql.Result, error) {
+					query, args = q, a
+					return MockResult{rowsAffected: 1000}, nil
+				},
+			}
+
+			builder := New(db, tt.dialect).Table("logs").Where("created_at", "<", "2024-01-01").OrderBy("id", "ASC").Limit(1000)
+			pq, err := builder.Clone().Delete().Prepare(context.Background())
+			if err != nil {
+				t.Fatalf("Prepare failed: %v", err)
+			}
+			if pq.SQL() != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, pq.SQL())
+			}
+
+			if _, err := builder.DeleteWithContext(context.Background()); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if query != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, query)
+			}
+			if !reflect.DeepEqual(args, []interface{}{"2024-01-01", 1000}) {
+				t.Errorf("Unexpected bindings %v", args)
+			}
+		})
+	}
+}
+
+// Deleting in batches until a batch comes back short keeps each lock brief
+func TestDeleteInBatches(t *testing.T) {
+	remaining := int64(2500)
+	var batches int
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			batches++
+			limit := int64(args[len(args)-1].(int))
+			deleted := limit
+			if remaining < limit {
+				deleted = remaining
+			}
+			remaining -= deleted
+			return MockResult{rowsAffected: deleted}, nil
+		},
+	}
+
+	const batchSize = 1000
+	for {
+		deleted, err := New(db).Table("logs").Where("created_at", "<", "2024-01-01").Limit(batchSize).DeleteWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if deleted < batchSize {
+			break
+		}
+	}
+
+	if remaining != 0 || batches != 3 {
+		t.Errorf("Expected 3 batches deleting everything, got %d batches and %d remaining", batches, remaining)
+	}
+}
@@ -0,0 +1,146 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrDuplicate is returned by CreateStrict when a unique column already
+// holds the value. Column is empty when the violated key doesn't match a
+// column tagged unique.
+type ErrDuplicate struct {
+	Column string // Unique column, from the db tag
+	Value  string // Duplicated value, as reported by the driver or inserted
+	Key    string // Unique key or constraint reported by the driver
+	Err    error  // Driver error
+}
+
+func (e *ErrDuplicate) Error() string {
+	if e.Column == "" {
+		return strings.TrimSpace("duplicate value for unique key " + e.Key)
+	}
+	return fmt.Sprintf("%s %q already taken", e.Column, e.Value)
+}
+
+func (e *ErrDuplicate) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// mysqlDuplicateEntry matches Duplicate entry 'value' for key 'name'
+	mysqlDuplicateEntry = regexp.MustCompile(`Duplicate entry '(.*)' for key '([^']+)'`)
+	// postgresDuplicateKey matches the constraint and the Key (column)=(value) detail
+	postgresDuplicateKey = regexp.MustCompile(`unique constraint "([^"]+)"`)
+	postgresKeyDetail    = regexp.MustCompile(`Key \((.+?)\)=\((.*?)\)`)
+	// sqliteUniqueFailed matches UNIQUE constraint failed: table.column[, table.column]
+	sqliteUniqueFailed = regexp.MustCompile(`UNIQUE constraint failed: (.+)$`)
+)
+
+// duplicateKey is what a driver error tells about a unique violation
+type duplicateKey struct {
+	key     string   // Key or constraint name
+	columns []string // Columns named by the error, when it names them
+	entry   string   // Duplicated value, when the error reports it
+}
+
+// parseDuplicateKey recognizes the duplicate key errors of MySQL, Postgres
+// and SQLite drivers
+func parseDuplicateKey(err error) (duplicateKey, bool) {
+	if err == nil {
+		return duplicateKey{}, false
+	}
+	message := err.Error()
+	if m := mysqlDuplicateEntry.FindStringSubmatch(message); m != nil {
+		return duplicateKey{key: m[2], entry: m[1]}, true
+	}
+	if m := postgresDuplicateKey.FindStringSubmatch(message); m != nil {
+		dup := duplicateKey{key: m[1]}
+		if d := postgresKeyDetail.FindStringSubmatch(message); d != nil {
+			dup.columns = strings.Split(d[1], ", ")
+			dup.entry = d[2]
+		}
+		return dup, true
+	}
+	if m := sqliteUniqueFailed.FindStringSubmatch(message); m != nil {
+		dup := duplicateKey{key: m[1]}
+		for _, ref := range strings.Split(m[1], ", ") {
+			_, column, _ := strings.Cut(ref, ".")
+			dup.columns = append(dup.columns, column)
+		}
+		return dup, true
+	}
+
+	// Driver errors exposing the SQLSTATE, such as pgx's PgError
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && state.SQLState() == "23505" {
+		return duplicateKey{}, true
+	}
+	return duplicateKey{}, false
+}
+
+// IsDuplicateKey reports whether err is a unique key violation: MySQL
+// error 1062, Postgres SQLSTATE 23505 or a SQLite UNIQUE constraint
+func IsDuplicateKey(err error) bool {
+	_, ok := parseDuplicateKey(err)
+	return ok
+}
+
+// CreateStrict is Create returning an *ErrDuplicate instead of the driver
+// error when a unique key is violated. The key is matched against the
+// columns tagged unique, e.g. `db:"email,unique"`, so handlers can report
+// which value is taken without parsing driver messages.
+func (m *Model) CreateStrict(ctx context.Context, data interface{}) (int64, error) {
+	id, err := m.Create(ctx, data)
+	dup, ok := parseDuplicateKey(err)
+	if !ok {
+		return id, err
+	}
+
+	dupErr := &ErrDuplicate{Column: m.uniqueColumn(dup), Value: dup.entry, Key: dup.key, Err: err}
+	if dupErr.Column != "" && dupErr.Value == "" {
+		if values, err := m.extractValues(data, true); err == nil && values[dupErr.Column] != nil {
+			dupErr.Value = fmt.Sprint(values[dupErr.Column])
+		}
+	}
+	return 0, dupErr
+}
+
+// uniqueColumn returns the unique column a duplicate key error is about,
+// from the columns it names or else from the key name, such as email,
+// users.email_unique or users_email_key. The longest match wins so
+// email_domain isn't mistaken for email.
+func (m *Model) uniqueColumn(dup duplicateKey) string {
+	var unique []string
+	for _, f := range m.fields {
+		if f.unique {
+			unique = append(unique, f.column)
+		}
+	}
+
+	if len(dup.columns) == 1 {
+		for _, column := range unique {
+			if column == dup.columns[0] {
+				return column
+			}
+		}
+		return ""
+	}
+
+	// MySQL 8 prefixes the key with the table
+	key := dup.key
+	if _, name, ok := strings.Cut(key, "."); ok {
+		key = name
+	}
+	key = "_" + strings.ToLower(key) + "_"
+
+	var match string
+	for _, column := range unique {
+		if strings.Contains(key, "_"+strings.ToLower(column)+"_") && len(column) > len(match) {
+			match = column
+		}
+	}
+	return match
+}
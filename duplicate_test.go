@@ -0,0 +1,100 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+type DuplicateAccount struct {
+	ID         int64  `db:"id,pk,auto"`
+	Email      string `db:"email,unique"`
+	EmailAlias string `db:"email_alias,unique"`
+	Name       string `db:"name"`
+}
+
+// sqlStateError is a driver error exposing only its SQLSTATE, like pgx's PgError
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "driver error" }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestCreateStrict(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		err    error
+		column string
+		value  string
+		key    string
+	}{
+		{
+			name:   "MySQL 1062",
+			err:    errors.New("Error 1062 (23000): Duplicate entry 'ada@example.com' for key 'duplicate_accounts.email_unique'"),
+			column: "email", value: "ada@example.com", key: "duplicate_accounts.email_unique",
+		},
+		{
+			name:   "MySQL longest column",
+			err:    errors.New("Error 1062 (23000): Duplicate entry 'ada' for key 'duplicate_accounts_email_alias_unique'"),
+			column: "email_alias", value: "ada", key: "duplicate_accounts_email_alias_unique",
+		},
+		{
+			name:   "Postgres 23505",
+			err:    errors.New(`pq: duplicate key value violates unique constraint "duplicate_accounts_email_key"`),
+			column: "email", value: "ada@example.com", key: "duplicate_accounts_email_key",
+		},
+		{
+			name:   "Postgres detail",
+			err:    errors.New(`ERROR: duplicate key value violates unique constraint "uq_alias" (Key (email_alias)=(ada) already exists.)`),
+			column: "email_alias", value: "ada", key: "uq_alias",
+		},
+		{
+			name:   "SQLite",
+			err:    errors.New("UNIQUE constraint failed: duplicate_accounts.email"),
+			column: "email", value: "ada@example.com", key: "duplicate_accounts.email",
+		},
+		{
+			name: "Unknown key",
+			err:  errors.New("Error 1062 (23000): Duplicate entry 'x' for key 'PRIMARY'"),
+			key:  "PRIMARY", value: "x",
+		},
+		{
+			name: "SQLSTATE only",
+			err:  sqlStateError("23505"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &MockDB{execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+				return nil, tt.err
+			}}
+			model, err := NewModel(db, DuplicateAccount{})
+			if err != nil {
+				t.Fatalf("NewModel failed: %v", err)
+			}
+
+			_, err = model.CreateStrict(ctx, &DuplicateAccount{Email: "ada@example.com", EmailAlias: "ada"})
+			var dup *ErrDuplicate
+			if !errors.As(err, &dup) {
+				t.Fatalf("Expected *ErrDuplicate, got %v", err)
+			}
+			if dup.Column != tt.column || dup.Value != tt.value || dup.Key != tt.key || !errors.Is(err, tt.err) {
+				t.Errorf("Unexpected duplicate error %+v", dup)
+			}
+			if !IsDuplicateKey(tt.err) {
+				t.Error("Expected IsDuplicateKey to recognize the error")
+			}
+		})
+	}
+
+	other := errors.New("connection refused")
+	db := &MockDB{execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+		return nil, other
+	}}
+	model, _ := NewModel(db, DuplicateAccount{})
+	if _, err := model.CreateStrict(ctx, &DuplicateAccount{Email: "ada@example.com"}); err != other || IsDuplicateKey(err) {
+		t.Errorf("Expected the driver error unchanged, got %v", err)
+	}
+}
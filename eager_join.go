@@ -0,0 +1,209 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// joinedRelation is a to-one relation fetched within the parent query
+type joinedRelation struct {
+	field *Field
+	model *Model
+	alias string
+}
+
+// WithJoinStrategy makes Find fetch to-one eager relations (hasOne and
+// belongsTo) in the parent query with a LEFT JOIN instead of one query per
+// relation. Relation columns are selected as <relation>__<column>.
+// To-many relations, relations with a custom query or nested relations and
+// relations whose model has a BeforeSelect hook are still loaded in batches.
+func (m *Model) WithJoinStrategy() *Model {
+	clone := *m
+	clone.joinStrategy = true
+	return &clone
+}
+
+// splitEagerLoads separates the relations that can be joined into the parent
+// query from the ones loaded with separate queries
+func (m *Model) splitEagerLoads() ([]joinedRelation, map[string]func(*Builder) *Builder, error) {
+	names := make([]string, 0, len(m.eagerLoad))
+	for name := range m.eagerLoad {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var joined []joinedRelation
+	rest := make(map[string]func(*Builder) *Builder)
+	for _, name := range names {
+		customQuery := m.eagerLoad[name]
+
+		var field *Field
+		for i := range m.fields {
+			if m.fields[i].relation != nil && strings.EqualFold(m.fields[i].name, name) {
+				field = &m.fields[i]
+				break
+			}
+		}
+
+		if field == nil || customQuery != nil || hasNestedPath(names, name) ||
+			(field.relation.relType != relationHasOne && field.relation.relType != relationBelongsTo) {
+			rest[name] = customQuery
+			continue
+		}
+
+		relatedModel, err := m.relatedModel(field.relation)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := relatedModel.hookTarget().(BeforeSelectHook); ok {
+			rest[name] = customQuery
+			continue
+		}
+
+		joined = append(joined, joinedRelation{
+			field: field,
+			model: relatedModel,
+			alias: toSnakeCase(field.name),
+		})
+	}
+	return joined, rest, nil
+}
+
+// hasNestedPath reports whether a nested path like "User.Team" starts with name
+func hasNestedPath(paths []string, name string) bool {
+	prefix := strings.ToLower(name) + "."
+	for _, path := range paths {
+		if strings.HasPrefix(strings.ToLower(path), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinRelations adds the LEFT JOINs and aliased relation columns to q
+func (m *Model) joinRelations(q *Builder, joined []joinedRelation) {
+	if len(q.columns) == 0 {
+		q.Select(m.table + ".*")
+	}
+
+	for _, j := range joined {
+		rel := j.field.relation
+		q.LeftJoin(m.relationTable(rel)+" AS "+j.alias,
+			fmt.Sprintf("%s.%s = %s.%s", j.alias, rel.foreignKey, m.table, rel.localKey))
+
+		for _, f := range j.model.fields {
+			if f.omit || f.relation != nil {
+				continue
+			}
+			q.Select(fmt.Sprintf("%s.%s AS %s__%s", j.alias, f.column, j.alias, f.column))
+		}
+	}
+}
+
+// scanJoined scans a row selected by joinRelations, splitting the aliased
+// columns into the relation structs. A relation is left empty when all of
+// its columns are NULL.
+func (m *Model) scanJoined(ctx context.Context, rows *sql.Rows, v reflect.Value, joined []joinedRelation) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	related := make([]reflect.Value, len(joined))
+	aliases := make(map[string]int, len(joined))
+	for i, j := range joined {
+		related[i] = reflect.New(j.model.structType()).Elem()
+		aliases[j.alias] = i
+	}
+
+	// Relation columns are scanned through a pointer so NULLs from the
+	// LEFT JOIN don't fail the scan
+	type target struct {
+		relation int
+		field    reflect.Value
+	}
+	targets := make([]target, len(columns))
+	values := make([]interface{}, len(columns))
+	extras := reflect.Value{}
+	for _, f := range m.fields {
+		if f.isExtras {
+			extras = v.FieldByName(f.name)
+		}
+	}
+
+	for i, col := range columns {
+		targets[i].relation = -1
+
+		if alias, column, ok := strings.Cut(col, "__"); ok {
+			if r, ok := aliases[alias]; ok {
+				name := getFieldNameByColumn(joined[r].model.fields, column)
+				if field := related[r].FieldByName(name); field.IsValid() {
+					targets[i] = target{relation: r, field: field}
+					values[i] = reflect.New(reflect.PointerTo(field.Type())).Interface()
+					continue
+				}
+			}
+		}
+
+		field := reflect.Value{}
+		for _, f := range m.fields {
+			if f.column == col && !f.isExtras && f.relation == nil {
+				field = v.FieldByName(f.name)
+				break
+			}
+		}
+		if !field.IsValid() {
+			values[i] = new(interface{})
+			continue
+		}
+		if !field.CanSet() {
+			return fmt.Errorf("cannot set field %s", col)
+		}
+		targets[i].field = field
+		values[i] = field.Addr().Interface()
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return err
+	}
+
+	found := make([]bool, len(joined))
+	for i, col := range columns {
+		t := targets[i]
+		switch {
+		case t.relation >= 0:
+			if ptr := reflect.ValueOf(values[i]).Elem(); !ptr.IsNil() {
+				t.field.Set(ptr.Elem())
+				found[t.relation] = true
+			}
+		case !t.field.IsValid() && extras.IsValid():
+			setExtra(extras, col, *values[i].(*interface{}))
+		}
+	}
+	m.remember(v)
+
+	for i, j := range joined {
+		if !found[i] {
+			continue
+		}
+		j.model.remember(related[i])
+		if err := j.model.afterFind(ctx, related[i].Addr().Interface()); err != nil {
+			return err
+		}
+
+		field := v.FieldByName(j.field.name)
+		if !field.CanSet() {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			field.Set(related[i].Addr())
+		} else {
+			field.Set(related[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package qix
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// JoinAuthor is the belongsTo target of JoinArticle
+type JoinAuthor struct {
+	ID   int    `db:"id,pk,auto"`
+	Name string `db:"name"`
+}
+
+// JoinSummary is the hasOne target of JoinArticle
+type JoinSummary struct {
+	ID        int    `db:"id,pk,auto"`
+	ArticleID int    `db:"article_id"`
+	Body      string `db:"body"`
+}
+
+// JoinComment is a hasMany target of JoinArticle
+type JoinComment struct {
+	ID        int    `db:"id,pk,auto"`
+	ArticleID int    `db:"article_id"`
+	Text      string `db:"text"`
+}
+
+// JoinArticle has to-one relations that can be joined into Find
+type JoinArticle struct {
+	ID       int           `db:"id,pk,auto"`
+	UserID   int           `db:"user_id"`
+	Title    string        `db:"title"`
+	User     JoinAuthor    `rel:"belongsTo,localKey:user_id,foreignKey:id"`
+	Profile  *JoinSummary  `rel:"hasOne,foreignKey:article_id,localKey:id"`
+	Comments []JoinComment `rel:"hasMany,foreignKey:article_id,localKey:id"`
+}
+
+// newJoinArticleMock returns one joined article row, with NULL profile
+// columns when withProfile is false
+func newJoinArticleMock(withProfile bool) *MockSQL {
+	return NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.Contains(query, "FROM join_comment") {
+			return &MockResultSet{
+				Columns: []string{"id", "article_id", "text"},
+				Rows:    [][]interface{}{{int64(7), int64(1), "first"}},
+			}, nil
+		}
+
+		row := []interface{}{int64(1), int64(5), "Hello", nil, nil, nil, int64(5), "ann"}
+		if withProfile {
+			row[3], row[4], row[5] = int64(9), int64(1), "intro"
+		}
+		return &MockResultSet{
+			Columns: []string{"id", "user_id", "title", "profile__id", "profile__article_id", "profile__body", "user__id", "user__name"},
+			Rows:    [][]interface{}{row},
+		}, nil
+	})
+}
+
+func TestModelFindWithJoinStrategy(t *testing.T) {
+	ctx := context.Background()
+	mock := newJoinArticleMock(true)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, JoinArticle{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	result, err := model.WithJoinStrategy().With("User", "Profile").Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Expected exactly 1 query, got %d", len(calls))
+	}
+	expected := "SELECT join_article.*, profile.id AS profile__id, profile.article_id AS profile__article_id, profile.body AS profile__body, " +
+		"user.id AS user__id, user.name AS user__name FROM join_article " +
+		"LEFT JOIN join_summary AS profile ON profile.article_id = join_article.id " +
+		"LEFT JOIN join_author AS user ON user.id = join_article.user_id " +
+		"WHERE join_article.id = ? LIMIT ?"
+	if calls[0].Query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, calls[0].Query)
+	}
+
+	article := result.(*JoinArticle)
+	if article.Title != "Hello" || article.UserID != 5 {
+		t.Errorf("Unexpected parent fields: %+v", article)
+	}
+	if article.User != (JoinAuthor{ID: 5, Name: "ann"}) {
+		t.Errorf("Unexpected user: %+v", article.User)
+	}
+	if article.Profile == nil || *article.Profile != (JoinSummary{ID: 9, ArticleID: 1, Body: "intro"}) {
+		t.Errorf("Unexpected profile: %+v", article.Profile)
+	}
+}
+
+func TestModelFindWithJoinStrategyMissingRelation(t *testing.T) {
+	ctx := context.Background()
+	mock := newJoinArticleMock(false)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, JoinArticle{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	if _, err := NewModel(mock.DB, JoinComment{}); err != nil {
+		t.Fatalf("Failed to create comment model: %v", err)
+	}
+
+	result, err := model.WithJoinStrategy().With("User", "Profile", "Comments").Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	// The hasMany relation is still loaded with its own query
+	if calls := mock.Calls(); len(calls) != 2 {
+		t.Fatalf("Expected 2 queries, got %d", len(calls))
+	}
+
+	article := result.(*JoinArticle)
+	if article.Profile != nil {
+		t.Errorf("Expected no profile for NULL columns, got %+v", article.Profile)
+	}
+	if article.User.Name != "ann" {
+		t.Errorf("Unexpected user: %+v", article.User)
+	}
+	if len(article.Comments) != 1 || article.Comments[0].Text != "first" {
+		t.Errorf("Unexpected comments: %+v", article.Comments)
+	}
+}
+
+func TestModelLoad(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.Contains(query, "FROM join_comment") {
+			return &MockResultSet{
+				Columns: []string{"id", "article_id", "text"},
+				Rows:    [][]interface{}{{int64(7), int64(1), "first"}, {int64(8), int64(2), "second"}, {int64(9), int64(1), "third"}},
+			}, nil
+		}
+		return &MockResultSet{
+			Columns: []string{"id", "name"},
+			Rows:    [][]interface{}{{int64(5), "ann"}, {int64(6), "ben"}},
+		}, nil
+	})
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, JoinArticle{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	NewModel(mock.DB, JoinAuthor{})
+	NewModel(mock.DB, JoinComment{})
+
+	articles := []JoinArticle{{ID: 1, UserID: 5, Title: "Hello"}, {ID: 2, UserID: 6, Title: "World"}}
+	if err := model.Load(ctx, &articles, "User", "Comments"); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected one query per relation, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if strings.Contains(call.Query, "FROM join_article") {
+			t.Errorf("Expected the parents not to be queried again, got %s", call.Query)
+		}
+	}
+
+	if articles[0].User.Name != "ann" || articles[1].User.Name != "ben" {
+		t.Errorf("Unexpected users %+v, %+v", articles[0].User, articles[1].User)
+	}
+	if len(articles[0].Comments) != 2 || len(articles[1].Comments) != 1 || articles[1].Comments[0].Text != "second" {
+		t.Errorf("Unexpected comments %+v, %+v", articles[0].Comments, articles[1].Comments)
+	}
+
+	if err := model.Load(ctx, &articles, "Missing"); err == nil {
+		t.Error("Expected error for unknown relation")
+	}
+}
@@ -0,0 +1,152 @@
+package qix
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// EventBus dispatches change notifications published by models. Use Events
+// for the process-wide bus.
+type EventBus struct {
+	mu      sync.RWMutex
+	columns map[string]map[int]columnSubscription // by table, then subscription id
+	nextID  int
+}
+
+// columnSubscription is a handler interested in some columns of a table
+type columnSubscription struct {
+	columns map[string]bool
+	fn      func(ColumnChange)
+}
+
+// ColumnChange describes the subscribed columns changed by an update
+type ColumnChange struct {
+	Table   string
+	Key     interface{}            // Primary key of the updated row
+	Columns []string               // Changed columns the handler subscribed to, sorted
+	Old     map[string]interface{} // Previous values, nil when no before-image is known
+	New     map[string]interface{}
+}
+
+var defaultEvents = &EventBus{}
+
+// Events returns the process-wide event bus
+func Events() *EventBus {
+	return defaultEvents
+}
+
+// SubscribeColumns calls fn after a model update changes at least one of
+// the columns of table. Old values are only compared and reported when the
+// row was loaded with TrackChanges or the model uses FetchBeforeImage;
+// otherwise every written column counts as changed. Handlers run
+// synchronously after the update succeeded. The returned function removes
+// the subscription.
+func (e *EventBus) SubscribeColumns(table string, columns []string, fn func(ColumnChange)) (unsubscribe func()) {
+	sub := columnSubscription{columns: make(map[string]bool, len(columns)), fn: fn}
+	for _, column := range columns {
+		sub.columns[column] = true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.columns == nil {
+		e.columns = make(map[string]map[int]columnSubscription)
+	}
+	if e.columns[table] == nil {
+		e.columns[table] = make(map[int]columnSubscription)
+	}
+	e.nextID++
+	id := e.nextID
+	e.columns[table][id] = sub
+
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.columns[table], id)
+	}
+}
+
+// watches reports whether any handler subscribed to columns of table
+func (e *EventBus) watches(table string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.columns[table]) > 0
+}
+
+// publishColumns notifies the handlers whose columns changed. old may be
+// nil, in which case every column of values counts as changed.
+func (e *EventBus) publishColumns(table string, key interface{}, old, values map[string]interface{}) {
+	e.mu.RLock()
+	subs := make([]columnSubscription, 0, len(e.columns[table]))
+	for _, sub := range e.columns[table] {
+		subs = append(subs, sub)
+	}
+	e.mu.RUnlock()
+
+	for _, sub := range subs {
+		change := ColumnChange{Table: table, Key: key, New: make(map[string]interface{})}
+		if old != nil {
+			change.Old = make(map[string]interface{})
+		}
+		for column, value := range values {
+			if !sub.columns[column] {
+				continue
+			}
+			if old != nil {
+				previous, ok := old[column]
+				if ok && sameValue(previous, value) {
+					continue
+				}
+				change.Old[column] = previous
+			}
+			change.Columns = append(change.Columns, column)
+			change.New[column] = value
+		}
+		if len(change.Columns) > 0 {
+			sort.Strings(change.Columns)
+			sub.fn(change)
+		}
+	}
+}
+
+// FetchBeforeImage makes Update read the current row before writing when
+// column subscribers are registered for the table and the row isn't
+// tracked, so handlers receive old values
+func (m *Model) FetchBeforeImage() *Model {
+	m.beforeImage = true
+	return m
+}
+
+// previousValues returns the stored values of the row being updated from
+// the change tracker or, with FetchBeforeImage, from the database. It
+// returns nil when nobody listens or no before-image is available.
+func (m *Model) previousValues(ctx context.Context, pk interface{}) (map[string]interface{}, error) {
+	if !Events().watches(m.table) {
+		return nil, nil
+	}
+	if m.tracker != nil {
+		if values, ok := m.tracker.get(normalizeKey(pk)); ok {
+			return values, nil
+		}
+	}
+	if !m.beforeImage {
+		return nil, nil
+	}
+
+	rows, err := m.writeQuery().Where(m.pk, "=", pk).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	v := reflect.New(reflect.TypeOf(m.value)).Elem()
+	if err := m.scanRow(rows, v); err != nil {
+		return nil, err
+	}
+	return m.columnValues(v), nil
+}
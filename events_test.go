@@ -0,0 +1,129 @@
+package qix
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// columnRecorder subscribes to columns of tracked_post and records the changes
+func columnRecorder(t *testing.T, columns ...string) *[]ColumnChange {
+	var changes []ColumnChange
+	unsubscribe := Events().SubscribeColumns("tracked_post", columns, func(c ColumnChange) {
+		changes = append(changes, c)
+	})
+	t.Cleanup(unsubscribe)
+	return &changes
+}
+
+func TestSubscribeColumnsTrackedUpdate(t *testing.T) {
+	ctx := context.Background()
+	stamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := newTrackedPostMock(stamp)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	model.TrackChanges()
+
+	titles := columnRecorder(t, "title")
+	created := columnRecorder(t, "created_at")
+
+	found, err := model.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	post := found.(*TrackedPost)
+	post.Title = "updated"
+	if _, err := model.Update(ctx, post); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	expected := []ColumnChange{{
+		Table:   "tracked_post",
+		Key:     1,
+		Columns: []string{"title"},
+		Old:     map[string]interface{}{"title": "hello"},
+		New:     map[string]interface{}{"title": "updated"},
+	}}
+	if !reflect.DeepEqual(*titles, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *titles)
+	}
+	if len(*created) != 0 {
+		t.Errorf("Expected no change for unsubscribed columns, got %+v", *created)
+	}
+}
+
+func TestSubscribeColumnsBeforeImage(t *testing.T) {
+	ctx := context.Background()
+	stamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := newTrackedPostMock(stamp)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	model.FetchBeforeImage()
+
+	titles := columnRecorder(t, "title")
+	created := columnRecorder(t, "created_at")
+
+	// Same title as stored, new created_at
+	later := stamp.Add(time.Hour)
+	if _, err := model.Update(ctx, &TrackedPost{ID: 1, Title: "hello", CreatedAt: later}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if len(*titles) != 0 {
+		t.Errorf("Expected unchanged title to be skipped, got %+v", *titles)
+	}
+	if len(*created) != 1 || (*created)[0].Old["created_at"] != stamp || (*created)[0].New["created_at"] != later {
+		t.Errorf("Expected created_at change from the before-image, got %+v", *created)
+	}
+	if calls := mock.Calls(); len(calls) != 2 {
+		t.Errorf("Expected the before-image read and the update, got %d statements", len(calls))
+	}
+}
+
+func TestSubscribeColumnsWithoutBeforeImage(t *testing.T) {
+	ctx := context.Background()
+	mock := newTrackedPostMock(time.Now())
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	titles := columnRecorder(t, "title")
+	if _, err := model.Update(ctx, &TrackedPost{ID: 1, Title: "hello"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if len(*titles) != 1 || (*titles)[0].Old != nil || (*titles)[0].New["title"] != "hello" {
+		t.Errorf("Expected written columns to count as changed, got %+v", *titles)
+	}
+	if calls := mock.Calls(); len(calls) != 1 {
+		t.Errorf("Expected no before-image read, got %d statements", len(calls))
+	}
+}
+
+func TestUnsubscribeColumns(t *testing.T) {
+	calls := 0
+	unsubscribe := Events().SubscribeColumns("accounts", []string{"email"}, func(ColumnChange) { calls++ })
+
+	Events().publishColumns("accounts", 1, nil, map[string]interface{}{"email": "a@b.c"})
+	unsubscribe()
+	Events().publishColumns("accounts", 1, nil, map[string]interface{}{"email": "d@e.f"})
+
+	if calls != 1 {
+		t.Errorf("Expected 1 notification, got %d", calls)
+	}
+	if Events().watches("accounts") {
+		t.Error("Expected no subscriptions left")
+	}
+}
@@ -0,0 +1,184 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrExportUnsupported is returned by ExportTo when the dialect or the
+// driver cannot run the server-side export
+var ErrExportUnsupported = errors.New("export not supported")
+
+// ExportTarget is a server-side destination of ExportTo, see MySQLOutfile
+// and PostgresCopyTo
+type ExportTarget interface {
+	export(ctx context.Context, b *Builder) error
+}
+
+// CopyToDB is implemented by connections able to stream COPY ... TO STDOUT,
+// such as an adapter around pgx's PgConn.CopyTo. database/sql drivers
+// don't expose the COPY protocol.
+type CopyToDB interface {
+	CopyTo(ctx context.Context, w io.Writer, sql string) (int64, error)
+}
+
+// CopyFormat is the output format of PostgresCopyTo
+type CopyFormat string
+
+// Formats of COPY TO
+const (
+	CopyText   CopyFormat = "text"
+	CopyCSV    CopyFormat = "csv"
+	CopyBinary CopyFormat = "binary"
+)
+
+// ExportTo dumps the result of the query on the database side, which is
+// much faster than scanning rows for large exports
+func (b *Builder) ExportTo(ctx context.Context, target ExportTarget) error {
+	if b.err != nil {
+		return b.err
+	}
+	if err := b.materializeInModels(ctx); err != nil {
+		return err
+	}
+	return target.export(ctx, b)
+}
+
+// mysqlOutfile renders SELECT ... INTO OUTFILE
+type mysqlOutfile struct {
+	path             string
+	fieldsTerminated string
+	enclosed         string
+}
+
+// MySQLOutfile writes the result to path on the MySQL server with
+// SELECT ... INTO OUTFILE. Empty fieldsTerminated and enclosed keep the
+// server defaults. The file must not exist and the user needs the FILE
+// privilege within secure_file_priv.
+func MySQLOutfile(path, fieldsTerminated, enclosed string) ExportTarget {
+	return mysqlOutfile{path: path, fieldsTerminated: fieldsTerminated, enclosed: enclosed}
+}
+
+func (o mysqlOutfile) export(ctx context.Context, b *Builder) error {
+	switch b.dialect.(type) {
+	case nil, mysqlDialect:
+	default:
+		return fmt.Errorf("%w: INTO OUTFILE requires MySQL", ErrExportUnsupported)
+	}
+	if o.path == "" {
+		return errors.New("outfile path is required")
+	}
+
+	query, bindings := b.toSQLWithBindings()
+	rows, err := b.queryContext(ctx, query+o.clause(), bindings...)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// clause renders the INTO OUTFILE clause with its options
+func (o mysqlOutfile) clause() string {
+	clause := " INTO OUTFILE " + mysqlString(o.path)
+	if o.fieldsTerminated != "" || o.enclosed != "" {
+		clause += " FIELDS"
+		if o.fieldsTerminated != "" {
+			clause += " TERMINATED BY " + mysqlString(o.fieldsTerminated)
+		}
+		if o.enclosed != "" {
+			clause += " ENCLOSED BY " + mysqlString(o.enclosed)
+		}
+	}
+	return clause
+}
+
+// mysqlString quotes s as a MySQL string literal, where backslashes escape
+func mysqlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// postgresCopyTo renders COPY (query) TO STDOUT
+type postgresCopyTo struct {
+	w      io.Writer
+	format CopyFormat
+}
+
+// PostgresCopyTo streams the result to w with COPY (query) TO STDOUT in
+// format. The connection of the builder must implement CopyToDB. COPY takes
+// no parameters, so bindings are inlined as literals; only strings,
+// numbers, booleans, times and NULL are accepted.
+func PostgresCopyTo(w io.Writer, format CopyFormat) ExportTarget {
+	return postgresCopyTo{w: w, format: format}
+}
+
+func (c postgresCopyTo) export(ctx context.Context, b *Builder) error {
+	if _, ok := b.dialect.(postgresDialect); !ok {
+		return fmt.Errorf("%w: COPY TO requires Postgres", ErrExportUnsupported)
+	}
+	copier, ok := b.db.(CopyToDB)
+	if !ok {
+		return fmt.Errorf("%w: connection %T cannot copy out", ErrExportUnsupported, b.db)
+	}
+	switch c.format {
+	case CopyText, CopyCSV, CopyBinary:
+	default:
+		return fmt.Errorf("unknown copy format %q", c.format)
+	}
+
+	query, err := inlineBindings(b.toSQLWithBindings())
+	if err != nil {
+		return err
+	}
+	statement := "COPY (" + query + ") TO STDOUT WITH (FORMAT " + string(c.format) + ")"
+
+	event := b.beforeQuery(statement, nil)
+	start := time.Now()
+	_, err = copier.CopyTo(ctx, c.w, statement)
+	elapsed := time.Since(start)
+	b.metrics.record(elapsed, err)
+	b.afterQuery(event, elapsed, err)
+	return err
+}
+
+// inlineBindings replaces the placeholders of query with its bindings
+// rendered as Postgres literals
+func inlineBindings(query string, bindings []interface{}) (string, error) {
+	var err error
+	inlined := replacePlaceholders(query, func(n int) string {
+		if n > len(bindings) {
+			err = errors.New("missing binding for placeholder")
+			return "?"
+		}
+		literal, ok := copyLiteral(bindings[n-1])
+		if !ok && err == nil {
+			err = fmt.Errorf("cannot inline binding %d of type %T", n, bindings[n-1])
+		}
+		return literal
+	})
+	return inlined, err
+}
+
+// copyLiteral renders value as a Postgres literal when its type is safe to inline
+func copyLiteral(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil, string, bool:
+		return sqlLiteral(v), true
+	case time.Time:
+		return "'" + v.Format(time.RFC3339Nano) + "'", true
+	case int:
+		return strconv.Itoa(v), true
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package qix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// copyDB is a MockDB able to copy out, recording the COPY statement
+type copyDB struct {
+	MockDB
+	statement string
+}
+
+func (c *copyDB) CopyTo(ctx context.Context, w io.Writer, sql string) (int64, error) {
+	c.statement = sql
+	n, err := io.WriteString(w, "1,ada\n")
+	return int64(n), err
+}
+
+func TestExportToMySQLOutfile(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning(nil)
+	defer mock.DB.Close()
+
+	err := New(mock.DB).Table("users").Select("id", "name").Where("active", "=", true).
+		ExportTo(ctx, MySQLOutfile(`/var/lib/mysql-files/it's\users.csv`, ",", `"`))
+	if err != nil {
+		t.Fatalf("ExportTo failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	expected := `SELECT id, name FROM users WHERE active = ? INTO OUTFILE '/var/lib/mysql-files/it''s\\users.csv' FIELDS TERMINATED BY ',' ENCLOSED BY '"'`
+	if len(calls) != 1 || calls[0].Query != expected {
+		t.Fatalf("Expected SQL: %s\nGot: %+v", expected, calls)
+	}
+
+	err = New(mock.DB, PostgresDialect).Table("users").ExportTo(ctx, MySQLOutfile("/tmp/users.csv", "", ""))
+	if !errors.Is(err, ErrExportUnsupported) {
+		t.Errorf("Expected ErrExportUnsupported on Postgres, got %v", err)
+	}
+}
+
+func TestExportToPostgresCopy(t *testing.T) {
+	ctx := context.Background()
+	db := &copyDB{}
+	var out bytes.Buffer
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := New(db, PostgresDialect).Table("users").Select("id", "name").
+		Where("name", "!=", "o'brien").Where("created_at", ">", created).Where("score", ">=", 1.5).
+		ExportTo(ctx, PostgresCopyTo(&out, CopyCSV))
+	if err != nil {
+		t.Fatalf("ExportTo failed: %v", err)
+	}
+	expected := `COPY (SELECT id, name FROM users WHERE name != 'o''brien' AND created_at > '2024-01-02T03:04:05Z' AND score >= 1.5) TO STDOUT WITH (FORMAT csv)`
+	if db.statement != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, db.statement)
+	}
+	if out.String() != "1,ada\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		target  ExportTarget
+	}{
+		{"MySQL dialect", New(db).Table("users"), PostgresCopyTo(&out, CopyCSV)},
+		{"No copy support", New(&MockDB{}, PostgresDialect).Table("users"), PostgresCopyTo(&out, CopyCSV)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.builder.ExportTo(ctx, tt.target); !errors.Is(err, ErrExportUnsupported) {
+				t.Errorf("Expected ErrExportUnsupported, got %v", err)
+			}
+		})
+	}
+
+	if err := New(db, PostgresDialect).Table("users").Where("data", "=", []byte("x")).
+		ExportTo(ctx, PostgresCopyTo(&out, CopyCSV)); err == nil {
+		t.Error("Expected an error for a binding that cannot be inlined")
+	}
+	if err := New(db, PostgresDialect).Table("users").ExportTo(ctx, PostgresCopyTo(&out, "xml")); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
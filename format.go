@@ -0,0 +1,274 @@
+package qix
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sqlKeywords are uppercased by FormatSQL
+var sqlKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true, "NOT": true,
+	"IN": true, "IS": true, "NULL": true, "LIKE": true, "ILIKE": true, "BETWEEN": true,
+	"EXISTS": true, "AS": true, "ON": true, "JOIN": true, "INNER": true, "LEFT": true,
+	"RIGHT": true, "FULL": true, "OUTER": true, "CROSS": true, "NATURAL": true,
+	"GROUP": true, "BY": true, "ORDER": true, "HAVING": true, "LIMIT": true, "OFFSET": true,
+	"UNION": true, "ALL": true, "DISTINCT": true, "ASC": true, "DESC": true,
+	"INSERT": true, "INTO": true, "VALUES": true, "UPDATE": true, "SET": true, "DELETE": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "FOR": true,
+	"RETURNING": true, "TRUE": true, "FALSE": true,
+}
+
+// sqlToken is a word, quoted literal or punctuation of a SQL string
+type sqlToken struct {
+	text  string
+	space bool // Preceded by whitespace
+}
+
+// word returns the uppercased token when it is a bare word, "" otherwise
+func (t sqlToken) word() string {
+	r := []rune(t.text)[0]
+	if !unicode.IsLetter(r) && r != '_' {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// formatFrame is the state of the query or parenthesis being formatted
+type formatFrame struct {
+	block       bool   // Top level or subquery, where clauses start new lines
+	indent      string // Indentation of clause keywords
+	closeIndent string // Indentation of the closing parenthesis
+	clause      string // Current clause keyword
+	columnLines bool   // SELECT list rendered one column per line
+	between     bool   // Inside BETWEEN, whose AND stays inline
+	caseDepth   int    // Open CASE expressions
+}
+
+// FormatSQL renders a query on several lines for logs and reviews: one
+// line per clause, one SELECT column per line when there are several,
+// AND/OR conditions on their own line and indented subqueries. Keywords
+// are uppercased; string literals, quoted identifiers and the order of
+// tokens are left unchanged.
+func FormatSQL(query string) string {
+	tokens := tokenizeSQL(query)
+
+	var out strings.Builder
+	lineIndent := ""
+	lineStart := true
+	newline := func(indent string) {
+		if out.Len() > 0 {
+			out.WriteString("\n" + indent)
+		}
+		lineIndent = indent
+		lineStart = true
+	}
+	emit := func(t sqlToken) {
+		if !lineStart && t.space {
+			out.WriteByte(' ')
+		}
+		if w := t.word(); sqlKeywords[w] {
+			out.WriteString(w)
+		} else {
+			out.WriteString(t.text)
+		}
+		lineStart = false
+	}
+
+	stack := []*formatFrame{{block: true}}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		f := stack[len(stack)-1]
+		word := t.word()
+
+		switch {
+		case t.text == "(":
+			emit(t)
+			if i+1 < len(tokens) && tokens[i+1].word() == "SELECT" && f.block {
+				stack = append(stack, &formatFrame{block: true, indent: lineIndent + "  ", closeIndent: lineIndent})
+			} else {
+				stack = append(stack, &formatFrame{})
+			}
+
+		case t.text == ")":
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+				if f.block {
+					newline(f.closeIndent)
+				}
+			}
+			emit(t)
+
+		case !f.block:
+			emit(t)
+
+		case t.text == ",":
+			emit(t)
+			if f.columnLines && f.caseDepth == 0 {
+				newline(f.indent + "  ")
+			}
+
+		case word == "CASE":
+			f.caseDepth++
+			emit(t)
+
+		case word == "END" && f.caseDepth > 0:
+			f.caseDepth--
+			emit(t)
+
+		case f.caseDepth == 0 && isClauseStart(tokens, i):
+			newline(f.indent)
+			emit(t)
+			f.clause, f.between, f.columnLines = word, false, false
+			switch word {
+			case "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "NATURAL":
+				f.clause = "JOIN"
+			case "SELECT":
+				// DISTINCT [ON (...)] stays next to SELECT
+				for i+1 < len(tokens) && (tokens[i+1].word() == "DISTINCT" || tokens[i+1].word() == "ON") {
+					i++
+					emit(tokens[i])
+				}
+				if i+1 < len(tokens) && tokens[i+1].text == "(" && tokens[i].word() == "ON" {
+					for depth := 0; i+1 < len(tokens); {
+						i++
+						emit(tokens[i])
+						if tokens[i].text == "(" {
+							depth++
+						} else if tokens[i].text == ")" {
+							if depth--; depth == 0 {
+								break
+							}
+						}
+					}
+				}
+				if f.columnLines = selectHasColumns(tokens, i+1); f.columnLines {
+					newline(f.indent + "  ")
+				}
+			}
+
+		case (word == "AND" || word == "OR") && f.caseDepth == 0 && !f.between &&
+			(f.clause == "WHERE" || f.clause == "HAVING" || f.clause == "JOIN"):
+			newline(f.indent + "  ")
+			emit(t)
+
+		case word == "AND" && f.between:
+			f.between = false
+			emit(t)
+
+		case word == "BETWEEN":
+			f.between = true
+			emit(t)
+
+		default:
+			emit(t)
+		}
+	}
+	return out.String()
+}
+
+// isClauseStart reports whether the token at i starts a clause line
+func isClauseStart(tokens []sqlToken, i int) bool {
+	word := tokens[i].word()
+	var prev, next string
+	if i > 0 {
+		prev = tokens[i-1].word()
+	}
+	if i+1 < len(tokens) {
+		next = tokens[i+1].word()
+		// A keyword directly followed by ( is a function call, e.g. VALUES(c) or LEFT(s, 3)
+		if tokens[i+1].text == "(" && !tokens[i+1].space {
+			return false
+		}
+	}
+
+	switch word {
+	case "SELECT", "WHERE", "HAVING", "LIMIT", "OFFSET", "UNION", "EXCEPT", "INTERSECT",
+		"VALUES", "SET", "RETURNING", "INSERT", "DELETE", "INNER", "CROSS", "NATURAL", "FULL":
+		return true
+	case "FROM":
+		return prev != "DELETE"
+	case "GROUP", "ORDER":
+		return next == "BY"
+	case "LEFT", "RIGHT":
+		return true
+	case "JOIN":
+		switch prev {
+		case "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "OUTER", "NATURAL":
+			return false
+		}
+		return true
+	case "UPDATE":
+		return prev != "KEY" && prev != "FOR" && prev != "DO"
+	case "FOR":
+		return next == "UPDATE" || next == "SHARE"
+	case "LOCK":
+		return next == "IN"
+	case "ON":
+		return next == "DUPLICATE" || next == "CONFLICT"
+	}
+	return false
+}
+
+// selectHasColumns reports whether the SELECT list starting at i has
+// several columns
+func selectHasColumns(tokens []sqlToken, i int) bool {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case ",":
+			if depth == 0 {
+				return true
+			}
+		default:
+			if depth == 0 && tokens[i].word() != "" && isClauseStart(tokens, i) {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// tokenizeSQL splits a query into words, quoted literals and punctuation
+func tokenizeSQL(query string) []sqlToken {
+	var tokens []sqlToken
+	runes := []rune(query)
+	space := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			i++
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			// Doubled quotes are escapes inside the literal
+			for i++; i < len(runes); i++ {
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+						continue
+					}
+					i++
+					break
+				}
+			}
+		case r == '(' || r == ')' || r == ',':
+			i++
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()'\"`,", runes[i]) {
+				i++
+			}
+		}
+		tokens = append(tokens, sqlToken{text: string(runes[start:i]), space: space})
+		space = false
+	}
+	return tokens
+}
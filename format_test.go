@@ -0,0 +1,99 @@
+package qix
+
+import "testing"
+
+func TestFormatSQL(t *testing.T) {
+	db := &MockDB{}
+	builder := New(db).Table("users").
+		Select("users.id", "users.name", "COUNT(posts.id) AS posts").
+		Join("posts", "posts.user_id = users.id").
+		Where("users.active", "=", true).
+		Where("users.age", ">", 18).
+		WhereExists(New(db).Table("teams").Select("id").Where("teams.id", "=", 7)).
+		GroupBy("users.id", "users.name").
+		OrderBy("posts", "DESC").
+		Limit(10).
+		Union(New(db).Table("admins").Select("id", "name", "0").Where("level", "=", 3))
+
+	expected := `SELECT
+  users.id,
+  users.name,
+  COUNT(posts.id) AS posts
+FROM users
+INNER JOIN posts ON posts.user_id = users.id
+WHERE users.active = ?
+  AND users.age > ?
+  AND EXISTS (
+    SELECT id
+    FROM teams
+    WHERE teams.id = ?
+  )
+GROUP BY users.id, users.name
+ORDER BY posts DESC
+LIMIT ?
+UNION
+SELECT
+  id,
+  name,
+  0
+FROM admins
+WHERE level = ?`
+	if got := FormatSQL(builder.ToSQL()); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestFormatSQLKeepsLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:  "Literals and identifiers",
+			query: `select "from", note from t where note = 'select, from where and' and x between 1 and 2`,
+			expected: "SELECT\n  \"from\",\n  note\nFROM t\nWHERE note = 'select, from where and'\n" +
+				"  AND x BETWEEN 1 AND 2",
+		},
+		{
+			name:     "Escaped quote",
+			query:    "SELECT * FROM t WHERE a = 'it''s where' OR b = 1",
+			expected: "SELECT *\nFROM t\nWHERE a = 'it''s where'\n  OR b = 1",
+		},
+		{
+			name:     "Case and functions",
+			query:    "SELECT CASE WHEN a AND b THEN 1 ELSE 0 END AS flag, LEFT(name, 3) FROM t FOR UPDATE",
+			expected: "SELECT\n  CASE WHEN a AND b THEN 1 ELSE 0 END AS flag,\n  LEFT(name, 3)\nFROM t\nFOR UPDATE",
+		},
+		{
+			name:     "Derived table",
+			query:    "SELECT COUNT(*) FROM (SELECT DISTINCT region FROM orders) AS sub",
+			expected: "SELECT COUNT(*)\nFROM (\n  SELECT DISTINCT region\n  FROM orders\n) AS sub",
+		},
+		{
+			name:     "Upsert",
+			query:    "INSERT INTO t (a, b) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE a = VALUES(a)",
+			expected: "INSERT INTO t (a, b)\nVALUES (?, ?), (?, ?)\nON DUPLICATE KEY UPDATE a = VALUES(a)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatSQL(tt.query); got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDebugPretty(t *testing.T) {
+	builder := New(&MockDB{}, PostgresDialect).Table("users").
+		Select("id", "name").
+		Where("name", "=", "O'Brien").
+		Where("active", "=", true)
+
+	expected := "SELECT\n  id,\n  name\nFROM users\nWHERE name = 'O''Brien'\n  AND active = TRUE"
+	if got := builder.DebugPretty(); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
@@ -2,4 +2,8 @@ module github.com/wibu-gaptek/qix
 
 go 1.23.1
 
-require github.com/go-sql-driver/mysql v1.7.1
+require (
+	github.com/go-sql-driver/mysql v1.7.1
+	golang.org/x/text v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,364 @@
+package qix
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidIdentifier is reported for table names that aren't plain identifiers
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+// WithStrictIdentifiers makes the builder panic on an invalid table name
+// instead of returning the error when the query is executed
+func WithStrictIdentifiers(enabled bool) Option {
+	return optionFunc(func(b *Builder) {
+		b.strictIdentifiers = enabled
+	})
+}
+
+// ValidIdentifier reports whether name is a valid [schema.]table reference
+// with an optional alias
+func ValidIdentifier(name string) bool {
+	cr, err := ParseColumnRef(name)
+	if err != nil || cr.IsExpression() || len(cr.Parts) > 2 {
+		return false
+	}
+	return cr.Parts[len(cr.Parts)-1] != "*"
+}
+
+// checkIdentifier records an error for an invalid table name, or panics in strict mode
+func (b *Builder) checkIdentifier(name string) {
+	if ValidIdentifier(name) {
+		return
+	}
+
+	err := fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+	if b.strictIdentifiers {
+		panic(err)
+	}
+	b.setErr(err)
+}
+
+// setErr keeps the first error found while building, it is returned when the query runs
+func (b *Builder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Err returns the first error found while building the query
+func (b *Builder) Err() error {
+	return b.err
+}
+
+var (
+	// plainIdentifier matches a single unquoted identifier part
+	plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+	// conditionKeywords are left unquoted in join conditions
+	conditionKeywords = map[string]bool{
+		"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true,
+		"IN": true, "LIKE": true, "BETWEEN": true, "TRUE": true, "FALSE": true,
+	}
+)
+
+// ColumnRef is a parsed [db.][table.]column reference with an optional
+// alias. Function calls and other expressions are kept opaque in
+// Expression, with Parts left empty.
+type ColumnRef struct {
+	Parts      []string // Unquoted name parts, the last one may be *
+	Alias      string   // Unquoted alias, empty without one
+	Expression string   // Expression without its alias, e.g. COUNT(id)
+
+	source   string // Reference as written
+	aliasSep string // Separator before the alias as written, e.g. " AS "
+}
+
+// ParseColumnRef parses a reference such as db.table.column AS alias. Parts
+// may be bare or quoted with backticks, double quotes or brackets, where a
+// doubled closing quote is an escape. A * is accepted alone or as the last
+// part. References containing parentheses, such as COUNT(*) c, are parsed
+// as expressions. Anything else is an ErrInvalidIdentifier.
+func ParseColumnRef(s string) (ColumnRef, error) {
+	invalid := func(reason string) (ColumnRef, error) {
+		return ColumnRef{}, fmt.Errorf("%w: %q: %s", ErrInvalidIdentifier, s, reason)
+	}
+
+	ref := strings.TrimSpace(s)
+	if ref == "" {
+		return invalid("empty reference")
+	}
+	words, err := splitRefWords(ref)
+	if err != nil {
+		return invalid(err.Error())
+	}
+
+	cr := ColumnRef{source: ref}
+	body := ref
+	switch n := len(words); {
+	case n >= 3 && strings.EqualFold(ref[words[n-2][0]:words[n-2][1]], "AS"):
+		body = ref[:words[n-3][1]]
+		cr.aliasSep = ref[words[n-3][1]:words[n-1][0]]
+		cr.Alias = ref[words[n-1][0]:]
+	case n == 2:
+		body = ref[:words[0][1]]
+		cr.aliasSep = ref[words[0][1]:words[1][0]]
+		cr.Alias = ref[words[1][0]:]
+	}
+
+	if strings.EqualFold(cr.Alias, "AS") {
+		return invalid("missing alias")
+	}
+	if cr.Alias != "" {
+		alias, err := splitRefPath(cr.Alias)
+		if err != nil || len(alias) != 1 || alias[0] == "*" {
+			return invalid("invalid alias")
+		}
+		cr.Alias = alias[0]
+	}
+
+	if strings.Contains(body, "(") {
+		cr.Expression = body
+		return cr, nil
+	}
+	if strings.ContainsFunc(body, unicode.IsSpace) && !strings.ContainsAny(body, "`\"[") {
+		return invalid("unexpected whitespace")
+	}
+
+	parts, err := splitRefPath(body)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if len(parts) > 3 {
+		return invalid("more than 3 parts")
+	}
+	cr.Parts = parts
+	return cr, nil
+}
+
+// IsExpression reports whether the reference is an opaque expression
+func (r ColumnRef) IsExpression() bool {
+	return r.Expression != ""
+}
+
+// Render writes the reference for the dialect, quoting each name part and
+// the alias. Expressions are returned as written. A nil dialect quotes with
+// backticks.
+func (r ColumnRef) Render(d Dialect) string {
+	if d == nil {
+		d = MySQLDialect
+	}
+	if r.IsExpression() {
+		if r.source != "" {
+			return r.source
+		}
+		if r.Alias == "" {
+			return r.Expression
+		}
+		return r.Expression + " AS " + d.QuoteIdentifier(r.Alias)
+	}
+
+	parts := make([]string, len(r.Parts))
+	for i, part := range r.Parts {
+		if part == "*" {
+			parts[i] = part
+		} else {
+			parts[i] = d.QuoteIdentifier(part)
+		}
+	}
+	name := strings.Join(parts, ".")
+	if r.Alias == "" {
+		return name
+	}
+	sep := r.aliasSep
+	if sep == "" {
+		sep = " AS "
+	}
+	return name + sep + d.QuoteIdentifier(r.Alias)
+}
+
+// Name returns the column name the reference has in a result set: the
+// alias, or else the last part
+func (r ColumnRef) Name() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+	if len(r.Parts) > 0 {
+		return r.Parts[len(r.Parts)-1]
+	}
+	return r.Expression
+}
+
+// splitRefWords returns the [start, end) offsets of the whitespace separated
+// words of ref, skipping whitespace inside quotes and parentheses
+func splitRefWords(ref string) ([][2]int, error) {
+	var words [][2]int
+	var closing rune
+	depth, start := 0, -1
+	for i, r := range ref {
+		switch {
+		case closing != 0:
+			// Doubled closing quotes are escapes and are read as two quotes
+			if r == closing {
+				closing = 0
+			}
+			continue
+		case r == '`' || r == '"' || r == '\'':
+			closing = r
+		case r == '[' && depth == 0:
+			closing = ']'
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth--; depth < 0 {
+				return nil, errors.New("unbalanced parentheses")
+			}
+		case unicode.IsSpace(r) && depth == 0:
+			if start >= 0 {
+				words = append(words, [2]int{start, i})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if closing != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if depth != 0 {
+		return nil, errors.New("unbalanced parentheses")
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(ref)})
+	}
+	return words, nil
+}
+
+// splitRefPath splits a dotted name into its unquoted parts
+func splitRefPath(name string) ([]string, error) {
+	var parts []string
+	for i := 0; ; {
+		var part string
+		switch name[i] {
+		case '`', '"', '[':
+			closing := name[i]
+			if closing == '[' {
+				closing = ']'
+			}
+			var unquoted strings.Builder
+			j := i + 1
+			for ; j < len(name); j++ {
+				if name[j] != closing {
+					unquoted.WriteByte(name[j])
+					continue
+				}
+				if j+1 < len(name) && name[j+1] == closing {
+					unquoted.WriteByte(closing)
+					j++
+					continue
+				}
+				break
+			}
+			if j >= len(name) {
+				return nil, errors.New("unterminated quote")
+			}
+			if unquoted.Len() == 0 {
+				return nil, errors.New("empty quoted name")
+			}
+			part, i = unquoted.String(), j+1
+		default:
+			j := strings.IndexByte(name[i:], '.')
+			if j < 0 {
+				j = len(name) - i
+			}
+			part, i = name[i:i+j], i+j
+			if part != "*" && !plainIdentifier.MatchString(part) {
+				return nil, fmt.Errorf("invalid name %q", part)
+			}
+		}
+
+		if len(parts) > 0 && parts[len(parts)-1] == "*" {
+			return nil, errors.New("* must be the last part")
+		}
+		parts = append(parts, part)
+		if i == len(name) {
+			return parts, nil
+		}
+		if name[i] != '.' || i+1 == len(name) {
+			return nil, errors.New("expected a dot between parts")
+		}
+		i++
+	}
+}
+
+// WithQuotedIdentifiers quotes table and column names with the dialect's
+// quote character, backticks by default, so reserved words like order or
+// group can be used as names. Dotted names are quoted per part and
+// expressions such as COUNT(id) as total are left untouched.
+func WithQuotedIdentifiers(enabled bool) Option {
+	return optionFunc(func(b *Builder) {
+		b.quoteIdentifiers = enabled
+	})
+}
+
+// quote quotes a reference through the builder's dialect when identifier
+// quoting is enabled
+func (b *Builder) quote(ref string) string {
+	if !b.quoteIdentifiers {
+		return ref
+	}
+	return QuoteIdentifier(b.dialect, ref)
+}
+
+// QuoteIdentifier quotes a [schema.]table or [table.]column reference with
+// an optional alias for the dialect, each dotted part separately. A nil
+// dialect quotes with backticks. References that aren't plain identifiers,
+// such as COUNT(*) as c, are returned unchanged.
+func QuoteIdentifier(d Dialect, ref string) string {
+	cr, err := ParseColumnRef(ref)
+	if err != nil {
+		return ref
+	}
+	return cr.Render(d)
+}
+
+// qualified joins name parts into a dotted reference, quoting each part
+// when identifier quoting is enabled
+func (b *Builder) qualified(parts ...string) string {
+	if !b.quoteIdentifiers {
+		return strings.Join(parts, ".")
+	}
+	return ColumnRef{Parts: parts}.Render(b.dialect)
+}
+
+// quoteAll quotes each reference in refs
+func (b *Builder) quoteAll(refs []string) []string {
+	if !b.quoteIdentifiers {
+		return refs
+	}
+	quoted := make([]string, len(refs))
+	for i, ref := range refs {
+		quoted[i] = b.quote(ref)
+	}
+	return quoted
+}
+
+// quoteCondition quotes the column references of a join condition such as
+// "users.id = posts.user_id". Conditions with string literals are left as is.
+func (b *Builder) quoteCondition(condition string) string {
+	if !b.quoteIdentifiers || strings.ContainsAny(condition, "'\"`") {
+		return condition
+	}
+	tokens := strings.Fields(condition)
+	for i, token := range tokens {
+		if !conditionKeywords[strings.ToUpper(token)] {
+			tokens[i] = b.quote(token)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
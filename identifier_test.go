@@ -0,0 +1,188 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidIdentifier(t *testing.T) {
+	valid := []string{
+		"users",
+		"analytics.events",
+		"`order items`",
+		`"public"."users"`,
+		"[dbo].[users]",
+		"users AS u",
+		"users u",
+		"post_tags",
+	}
+	for _, name := range valid {
+		if !ValidIdentifier(name) {
+			t.Errorf("Expected %q to be valid", name)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"users; DROP TABLE users",
+		"users--",
+		"users WHERE 1=1",
+		"a.b.c",
+		"1users",
+		"`users` ; DELETE",
+		"(SELECT 1)",
+	}
+	for _, name := range invalid {
+		if ValidIdentifier(name) {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestTableRejectsInjection(t *testing.T) {
+	var executed bool
+	db := &MockDB{
+		queryFunc: func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			executed = true
+			return nil, nil
+		},
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			executed = true
+			return MockResult{}, nil
+		},
+	}
+
+	builder := New(db).Table("users; DROP TABLE users").Where("id", "=", 1)
+	if !errors.Is(builder.Err(), ErrInvalidIdentifier) {
+		t.Fatalf("Expected ErrInvalidIdentifier, got %v", builder.Err())
+	}
+
+	if _, err := builder.Get(context.Background()); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("Expected Get to fail with ErrInvalidIdentifier, got %v", err)
+	}
+	if _, err := builder.DeleteWithContext(context.Background()); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("Expected Delete to fail with ErrInvalidIdentifier, got %v", err)
+	}
+	if executed {
+		t.Error("Expected no statement to be executed")
+	}
+
+	joined := New(db).Table("users").LeftJoin("orders o; DROP TABLE orders", "users.id = o.user_id")
+	if !errors.Is(joined.Err(), ErrInvalidIdentifier) {
+		t.Errorf("Expected join table to be rejected, got %v", joined.Err())
+	}
+
+	if err := New(db).Table("analytics.events").Join("users AS u", "u.id = events.user_id").Err(); err != nil {
+		t.Errorf("Expected valid names to pass, got %v", err)
+	}
+}
+
+func TestStrictIdentifiersPanics(t *testing.T) {
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("Expected panic with ErrInvalidIdentifier, got %v", r)
+		}
+	}()
+
+	New(&MockDB{}, WithStrictIdentifiers(true)).Table("users; DROP TABLE users")
+}
+
+func TestParseColumnRef(t *testing.T) {
+	tests := []struct {
+		ref        string
+		parts      []string
+		alias      string
+		expression string
+		rendered   string // With PostgresDialect
+	}{
+		{"id", []string{"id"}, "", "", `"id"`},
+		{"users.id", []string{"users", "id"}, "", "", `"users"."id"`},
+		{"shop.users.id", []string{"shop", "users", "id"}, "", "", `"shop"."users"."id"`},
+		{"*", []string{"*"}, "", "", "*"},
+		{"users.*", []string{"users", "*"}, "", "", `"users".*`},
+		{"shop.users.*", []string{"shop", "users", "*"}, "", "", `"shop"."users".*`},
+		{"users AS u", []string{"users"}, "u", "", `"users" AS "u"`},
+		{"users as u", []string{"users"}, "u", "", `"users" as "u"`},
+		{"users u", []string{"users"}, "u", "", `"users" "u"`},
+		{"  users.id   total  ", []string{"users", "id"}, "total", "", `"users"."id"   "total"`},
+		{"`order items`", []string{"order items"}, "", "", `"order items"`},
+		{"`a``b`.c", []string{"a`b", "c"}, "", "", `"a` + "`" + `b"."c"`},
+		{`"we""ird"`, []string{`we"ird`}, "", "", `"we""ird"`},
+		{"[dbo].[users] AS [u]", []string{"dbo", "users"}, "u", "", `"dbo"."users" AS "u"`},
+		{"[a]]b]", []string{"a]b"}, "", "", `"a]b"`},
+		{`"public".users`, []string{"public", "users"}, "", "", `"public"."users"`},
+		{"COUNT(*)", nil, "", "COUNT(*)", "COUNT(*)"},
+		{"COUNT(id) as total", nil, "total", "COUNT(id)", "COUNT(id) as total"},
+		{"COUNT(id) total", nil, "total", "COUNT(id)", "COUNT(id) total"},
+		{"COALESCE(name, 'a b') AS label", nil, "label", "COALESCE(name, 'a b')", "COALESCE(name, 'a b') AS label"},
+		{"price * (1 + tax)", nil, "", "price * (1 + tax)", "price * (1 + tax)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			cr, err := ParseColumnRef(tt.ref)
+			if err != nil {
+				t.Fatalf("ParseColumnRef failed: %v", err)
+			}
+			if !reflect.DeepEqual(cr.Parts, tt.parts) || cr.Alias != tt.alias || cr.Expression != tt.expression {
+				t.Errorf("Got parts %q, alias %q, expression %q", cr.Parts, cr.Alias, cr.Expression)
+			}
+			if got := cr.Render(PostgresDialect); got != tt.rendered {
+				t.Errorf("Expected %s, got %s", tt.rendered, got)
+			}
+		})
+	}
+
+	malformed := []string{
+		"",
+		"   ",
+		"1users",
+		"users--",
+		"users;",
+		"a.b.c.d",
+		"a..b",
+		".a",
+		"a.",
+		"*.id",
+		"users.*.id",
+		"`users",
+		`"users`,
+		"[users",
+		"``",
+		"`a`b",
+		"`a`.`b`c",
+		"users AS",
+		"users AS 1u",
+		"users AS *",
+		"users AS u.v",
+		"users u v",
+		"a + b",
+		"users; DROP TABLE users",
+		"COUNT(id",
+		"COUNT(id))",
+		"COUNT('id)",
+		"'users'",
+	}
+	for _, ref := range malformed {
+		if cr, err := ParseColumnRef(ref); !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("Expected %q to be rejected, got %+v", ref, cr)
+		}
+	}
+}
+
+func TestColumnRefRender(t *testing.T) {
+	ref := ColumnRef{Parts: []string{"users", "id"}, Alias: "user_id"}
+	if got := ref.Render(nil); got != "`users`.`id` AS `user_id`" {
+		t.Errorf("Unexpected render %s", got)
+	}
+	if got := ref.Name(); got != "user_id" {
+		t.Errorf("Expected the alias as name, got %s", got)
+	}
+	if got := (ColumnRef{Expression: "COUNT(*)", Alias: "c"}).Render(SQLiteDialect); got != `COUNT(*) AS "c"` {
+		t.Errorf("Unexpected expression render %s", got)
+	}
+}
@@ -0,0 +1,75 @@
+package qix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidInclude is reported by ParseIncludes for rejected include paths
+var ErrInvalidInclude = errors.New("invalid include")
+
+// ParseIncludes validates a JSON:API style include parameter such as
+// "comments.user,tags" against the allowed relation paths and returns the
+// paths with their real relation names, ready for With. Matching is
+// case-insensitive; the error lists every rejected entry.
+func (m *Model) ParseIncludes(param string, allowed []string) ([]string, error) {
+	allow := make(map[string]bool, len(allowed))
+	for _, path := range allowed {
+		allow[strings.ToLower(strings.TrimSpace(path))] = true
+	}
+
+	var includes, invalid []string
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(param, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key := strings.ToLower(entry)
+		if !allow[key] {
+			invalid = append(invalid, entry)
+			continue
+		}
+
+		path, err := m.resolveRelationPath(entry)
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		if !seen[key] {
+			seen[key] = true
+			includes = append(includes, path)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %s (allowed: %s)", ErrInvalidInclude,
+			strings.Join(invalid, ", "), strings.Join(allowed, ", "))
+	}
+	return includes, nil
+}
+
+// resolveRelationPath maps each segment of a dotted relation path to the
+// relation field name of the model it belongs to
+func (m *Model) resolveRelationPath(path string) (string, error) {
+	segments := strings.Split(path, ".")
+	current := m
+	for i, segment := range segments {
+		field := current.relationField(segment)
+		if field == nil {
+			return "", fmt.Errorf("relation '%s' not found", segment)
+		}
+		segments[i] = field.name
+
+		if i < len(segments)-1 {
+			next, err := current.relatedModel(field.relation)
+			if err != nil {
+				return "", err
+			}
+			current = next
+		}
+	}
+	return strings.Join(segments, "."), nil
+}
@@ -0,0 +1,132 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// IncArticle has a nested relation path articles -> comments -> author
+type IncArticle struct {
+	ID       int          `db:"id,pk,auto"`
+	Title    string       `db:"title"`
+	Comments []IncComment `rel:"hasMany,foreignKey:article_id"`
+}
+
+// IncComment belongs to an IncArticle and an IncAuthor
+type IncComment struct {
+	ID        int       `db:"id,pk,auto"`
+	ArticleID int       `db:"article_id"`
+	AuthorID  int       `db:"author_id"`
+	Author    IncAuthor `rel:"belongsTo,localKey:author_id,foreignKey:id"`
+}
+
+// IncAuthor writes IncComments
+type IncAuthor struct {
+	ID   int    `db:"id,pk,auto"`
+	Name string `db:"name"`
+}
+
+func newIncludesMock() *MockSQL {
+	return NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		switch {
+		case strings.Contains(query, "FROM inc_comment"):
+			return &MockResultSet{
+				Columns: []string{"id", "article_id", "author_id"},
+				Rows:    [][]interface{}{{int64(10), int64(1), int64(5)}, {int64(11), int64(1), int64(6)}},
+			}, nil
+		case strings.Contains(query, "FROM inc_author"):
+			return &MockResultSet{
+				Columns: []string{"id", "name"},
+				Rows:    [][]interface{}{{int64(5), "ann"}, {int64(6), "ben"}},
+			}, nil
+		}
+		return &MockResultSet{
+			Columns: []string{"id", "title"},
+			Rows:    [][]interface{}{{int64(1), "Hello"}},
+		}, nil
+	})
+}
+
+func TestModelParseIncludes(t *testing.T) {
+	model, err := NewModel(&MockDB{}, Post{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	allowed := []string{"comments", "comments.user", "tags", "likes"}
+
+	tests := []struct {
+		name     string
+		param    string
+		expected []string
+		invalid  string
+	}{
+		{name: "Nested paths", param: "comments.user,tags", expected: []string{"Comments.User", "Tags"}},
+		{name: "Case normalization", param: " COMMENTS.User , Tags,comments.USER", expected: []string{"Comments.User", "Tags"}},
+		{name: "Empty", param: "", expected: nil},
+		{name: "Not allowed", param: "comments,author,comments.post", invalid: "invalid include: author, comments.post (allowed: comments, comments.user, tags, likes)"},
+		{name: "Unknown relation", param: "likes", invalid: "invalid include: likes (allowed: comments, comments.user, tags, likes)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			includes, err := model.ParseIncludes(tt.param, allowed)
+			if tt.invalid != "" {
+				if !errors.Is(err, ErrInvalidInclude) {
+					t.Fatalf("Expected ErrInvalidInclude, got %v", err)
+				}
+				if err.Error() != tt.invalid {
+					t.Errorf("Expected error %q, got %q", tt.invalid, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(includes, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, includes)
+			}
+		})
+	}
+}
+
+func TestModelNestedEagerLoading(t *testing.T) {
+	ctx := context.Background()
+	mock := newIncludesMock()
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, IncArticle{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	for _, related := range []interface{}{IncComment{}, IncAuthor{}} {
+		if _, err := NewModel(mock.DB, related); err != nil {
+			t.Fatalf("Failed to create model: %v", err)
+		}
+	}
+
+	includes, err := model.ParseIncludes("comments.author,comments", []string{"comments", "comments.author"})
+	if err != nil {
+		t.Fatalf("ParseIncludes failed: %v", err)
+	}
+
+	result, err := model.With(includes...).Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	// The article, its comments once and their authors once
+	if calls := mock.Calls(); len(calls) != 3 {
+		t.Errorf("Expected 3 queries, got %d", len(calls))
+	}
+
+	article := result.(*IncArticle)
+	if len(article.Comments) != 2 {
+		t.Fatalf("Expected 2 comments, got %d", len(article.Comments))
+	}
+	if article.Comments[0].Author.Name != "ann" || article.Comments[1].Author.Name != "ben" {
+		t.Errorf("Expected nested authors to be loaded, got %+v", article.Comments)
+	}
+}
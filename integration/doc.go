@@ -0,0 +1,16 @@
+// Package integration runs the qix scenario suite against real MySQL,
+// Postgres and SQLite databases, where drivers return int64 instead of
+// int, []byte instead of string and parse times their own way.
+//
+// The tests only build with the integration tag. MySQL uses the driver qix
+// already depends on; Postgres and SQLite drivers are linked with the
+// postgres and sqlite tags once added to go.mod:
+//
+//	go get github.com/jackc/pgx/v5 modernc.org/sqlite
+//	go test -tags "integration postgres sqlite" ./integration/
+//
+// Without QIX_IT_MYSQL_DSN or QIX_IT_POSTGRES_DSN the suite starts the
+// databases of compose.yaml with docker compose and removes them
+// afterwards; QIX_IT_KEEP=1 leaves them running for the next run. Engines
+// whose driver isn't linked or whose database can't be reached are skipped.
+package integration
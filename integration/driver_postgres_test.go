@@ -0,0 +1,5 @@
+//go:build integration && postgres
+
+package integration
+
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,5 @@
+//go:build integration && sqlite
+
+package integration
+
+import _ "modernc.org/sqlite"
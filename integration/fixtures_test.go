@@ -0,0 +1,168 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/wibu-gaptek/qix"
+)
+
+// Author has one Profile and many Articles
+type Author struct {
+	ID        int64     `db:"id,pk,auto"`
+	Name      string    `db:"name"`
+	Email     string    `db:"email,unique"`
+	Score     int       `db:"score"`
+	CreatedAt time.Time `db:"created_at"`
+	Profile   Profile   `rel:"hasOne,foreignKey:author_id"`
+	Articles  []Article `rel:"hasMany,foreignKey:author_id"`
+}
+
+// Profile belongs to an Author
+type Profile struct {
+	ID       int64  `db:"id,pk,auto"`
+	AuthorID int64  `db:"author_id"`
+	Bio      string `db:"bio"`
+}
+
+// Article belongs to an Author and has many Labels through article_label
+type Article struct {
+	ID          int64     `db:"id,pk,auto"`
+	AuthorID    int64     `db:"author_id"`
+	Title       string    `db:"title"`
+	Views       int       `db:"views"`
+	PublishedAt time.Time `db:"published_at"`
+	Author      *Author   `rel:"belongsTo"`
+	Labels      []Label   `rel:"manyToMany,pivot:article_label,pivotFk:article_id,pivotRfk:label_id"`
+}
+
+// Label is attached to articles
+type Label struct {
+	ID   int64  `db:"id,pk,auto"`
+	Name string `db:"name"`
+}
+
+// tables are created in order and dropped in reverse order
+var tables = []struct {
+	name string
+	ddl  string
+}{
+	{"author", `CREATE TABLE author (
+		{id},
+		name VARCHAR(100) NOT NULL,
+		email VARCHAR(190) NOT NULL UNIQUE,
+		score INT NOT NULL DEFAULT 0,
+		created_at {time} NOT NULL
+	)`},
+	{"profile", `CREATE TABLE profile (
+		{id},
+		author_id BIGINT NOT NULL REFERENCES author (id),
+		bio VARCHAR(255) NOT NULL
+	)`},
+	{"article", `CREATE TABLE article (
+		{id},
+		author_id BIGINT NOT NULL REFERENCES author (id),
+		title VARCHAR(200) NOT NULL,
+		views INT NOT NULL DEFAULT 0,
+		published_at {time} NOT NULL
+	)`},
+	{"label", `CREATE TABLE label (
+		{id},
+		name VARCHAR(50) NOT NULL UNIQUE
+	)`},
+	{"article_label", `CREATE TABLE article_label (
+		article_id BIGINT NOT NULL REFERENCES article (id),
+		label_id BIGINT NOT NULL REFERENCES label (id),
+		PRIMARY KEY (article_id, label_id)
+	)`},
+	{"counter", `CREATE TABLE counter (
+		name VARCHAR(50) NOT NULL PRIMARY KEY,
+		hits INT NOT NULL
+	)`},
+}
+
+// idColumn is the auto-increment primary key of each engine
+var idColumn = map[string]string{
+	"mysql":    "id BIGINT AUTO_INCREMENT PRIMARY KEY",
+	"postgres": "id BIGSERIAL PRIMARY KEY",
+	"sqlite":   "id INTEGER PRIMARY KEY AUTOINCREMENT",
+}
+
+// timeType is the date and time column type of each engine
+var timeType = map[string]string{
+	"mysql":    "DATETIME",
+	"postgres": "TIMESTAMP",
+	"sqlite":   "DATETIME",
+}
+
+// epoch is the creation time of the fixtures, without sub-second
+// precision that DATETIME would drop
+var epoch = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// fixtures are inserted with explicit ids so every engine agrees on them
+var fixtures = []struct {
+	table string
+	rows  []map[string]interface{}
+}{
+	{"author", []map[string]interface{}{
+		{"id": 1, "name": "Ada", "email": "ada@example.com", "score": 10, "created_at": epoch},
+		{"id": 2, "name": "Grace", "email": "grace@example.com", "score": 7, "created_at": epoch.Add(time.Hour)},
+		{"id": 3, "name": "Linus", "email": "linus@example.com", "score": 3, "created_at": epoch.Add(2 * time.Hour)},
+	}},
+	{"profile", []map[string]interface{}{
+		{"id": 1, "author_id": 1, "bio": "Analyst"},
+	}},
+	{"article", []map[string]interface{}{
+		{"id": 1, "author_id": 1, "title": "Notes", "views": 5, "published_at": epoch},
+		{"id": 2, "author_id": 1, "title": "Engines", "views": 3, "published_at": epoch.Add(24 * time.Hour)},
+		{"id": 3, "author_id": 2, "title": "Compilers", "views": 8, "published_at": epoch.Add(48 * time.Hour)},
+	}},
+	{"label", []map[string]interface{}{
+		{"id": 1, "name": "math"},
+		{"id": 2, "name": "history"},
+	}},
+	{"article_label", []map[string]interface{}{
+		{"article_id": 1, "label_id": 1},
+		{"article_id": 1, "label_id": 2},
+		{"article_id": 2, "label_id": 1},
+	}},
+}
+
+// seed inserts the fixtures
+func (e *engine) seed(ctx context.Context) error {
+	for _, fixture := range fixtures {
+		if err := e.builder().Table(fixture.table).BatchInsert(ctx, fixture.rows); err != nil {
+			return err
+		}
+		if _, ok := fixture.rows[0]["id"]; ok && e.name == "postgres" {
+			// Explicit ids don't advance the serial sequence
+			_, err := e.db.ExecContext(ctx, "SELECT setval(pg_get_serial_sequence('"+fixture.table+"', 'id'), (SELECT MAX(id) FROM "+fixture.table+"))")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// registerModels registers the models on the engine's connection, so
+// relations load from the database under test
+func registerModels(t *testing.T, e *engine) {
+	t.Helper()
+	if err := qix.RegisterModels(e.db, e.dialect, Author{}, Profile{}, Article{}, Label{}); err != nil {
+		t.Fatalf("RegisterModels: %v", err)
+	}
+}
+
+// model returns a fresh model of value on the engine
+func model(t *testing.T, e *engine, value interface{}) *qix.Model {
+	t.Helper()
+	m, err := qix.NewModel(e.db, value, e.dialect)
+	if err != nil {
+		t.Fatalf("NewModel(%T): %v", value, err)
+	}
+	return m
+}
@@ -0,0 +1,291 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wibu-gaptek/qix"
+)
+
+func TestCRUD(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		ctx := context.Background()
+		authors := model(t, e, Author{})
+
+		alan := &Author{Name: "Alan", Email: "alan@example.com", Score: 5, CreatedAt: epoch}
+		id, err := authors.Create(ctx, alan)
+		if !e.lastInsertID {
+			// The driver has no LastInsertId, the row is written but its id is unknown
+			if err == nil {
+				t.Fatal("Expected Create to report the missing LastInsertId")
+			}
+			id, err = qix.ValueAs[int64](ctx, e.builder().Table("author").Where("email", "=", alan.Email), "id")
+		}
+		if err != nil || id != 4 {
+			t.Fatalf("Expected id 4, got %d (%v)", id, err)
+		}
+
+		found, err := model(t, e, Author{}).Find(ctx, id)
+		if err != nil {
+			t.Fatalf("Find failed: %v", err)
+		}
+		got := found.(*Author)
+		if got.Name != "Alan" || got.Score != 5 || !got.CreatedAt.Equal(epoch) {
+			t.Errorf("Unexpected author %+v", got)
+		}
+
+		got.Score = 6
+		if affected, err := model(t, e, Author{}).Update(ctx, got); err != nil || affected != 1 {
+			t.Fatalf("Update: expected 1 row, got %d (%v)", affected, err)
+		}
+		score, err := qix.ValueAs[int](ctx, e.builder().Table("author").Where("id", "=", id), "score")
+		if err != nil || score != 6 {
+			t.Errorf("Expected score 6, got %d (%v)", score, err)
+		}
+
+		if affected, err := model(t, e, Author{}).Delete(ctx, id); err != nil || affected != 1 {
+			t.Fatalf("Delete: expected 1 row, got %d (%v)", affected, err)
+		}
+		if _, err := model(t, e, Author{}).Find(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows after Delete, got %v", err)
+		}
+
+		_, err = model(t, e, Author{}).CreateStrict(ctx, &Author{Name: "Ada", Email: "ada@example.com", CreatedAt: epoch})
+		var dup *qix.ErrDuplicate
+		if !errors.As(err, &dup) || (dup.Column != "" && dup.Column != "email") {
+			t.Errorf("Expected a duplicate email, got %v", err)
+		}
+	})
+}
+
+func TestTransactionSavepoints(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		ctx := context.Background()
+		label := func(name string) []map[string]interface{} {
+			return []map[string]interface{}{{"name": name}}
+		}
+
+		rollback := errors.New("rollback")
+		err := model(t, e, Label{}).Transaction(ctx, func(tx *qix.Model) error {
+			if err := tx.Query().BatchInsert(ctx, label("outer")); err != nil {
+				return err
+			}
+			inner := tx.Transaction(ctx, func(sp *qix.Model) error {
+				if err := sp.Query().BatchInsert(ctx, label("inner")); err != nil {
+					return err
+				}
+				return rollback
+			})
+			if !errors.Is(inner, rollback) {
+				t.Errorf("Expected the savepoint error, got %v", inner)
+			}
+			return tx.Transaction(ctx, func(sp *qix.Model) error {
+				return sp.Query().BatchInsert(ctx, label("released"))
+			})
+		})
+		if err != nil {
+			t.Fatalf("Transaction failed: %v", err)
+		}
+
+		names, err := qix.PluckAs[string](ctx, e.builder().Table("label").Where("id", ">", 2).OrderBy("id", "ASC"), "name")
+		if err != nil || !reflect.DeepEqual(names, []string{"outer", "released"}) {
+			t.Errorf("Expected the savepoint rolled back alone, got %v (%v)", names, err)
+		}
+
+		err = e.builder().Transaction(ctx, func(tx *qix.Builder) error {
+			if err := tx.Table("label").BatchInsert(ctx, label("discarded")); err != nil {
+				return err
+			}
+			return rollback
+		})
+		if !errors.Is(err, rollback) {
+			t.Fatalf("Expected the rollback error, got %v", err)
+		}
+		if exists, err := e.builder().Table("label").Where("name", "=", "discarded").Exists(ctx); err != nil || exists {
+			t.Errorf("Expected the transaction rolled back, got %v (%v)", exists, err)
+		}
+	})
+}
+
+func TestEagerLoading(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		ctx := context.Background()
+
+		found, err := model(t, e, Author{}).With("Profile", "Articles").Find(ctx, 1)
+		if err != nil {
+			t.Fatalf("Find with relations failed: %v", err)
+		}
+		ada := found.(*Author)
+		if ada.Profile.Bio != "Analyst" {
+			t.Errorf("hasOne: expected the profile, got %+v", ada.Profile)
+		}
+		if len(ada.Articles) != 2 {
+			t.Errorf("hasMany: expected 2 articles, got %+v", ada.Articles)
+		}
+
+		found, err = model(t, e, Article{}).With("Author", "Labels").Find(ctx, 1)
+		if err != nil {
+			t.Fatalf("Find with relations failed: %v", err)
+		}
+		notes := found.(*Article)
+		if notes.Author == nil || notes.Author.Name != "Ada" {
+			t.Errorf("belongsTo: expected Ada, got %+v", notes.Author)
+		}
+		var labels []string
+		for _, l := range notes.Labels {
+			labels = append(labels, l.Name)
+		}
+		if len(labels) != 2 {
+			t.Errorf("manyToMany: expected 2 labels, got %v", labels)
+		}
+	})
+}
+
+func TestPagination(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		query := e.builder().Table("article").Select("id", "title").Where("views", ">", 2).OrderBy("id", "ASC")
+		expectSQL(t, e, query.Clone(), map[string]string{
+			"mysql":    "SELECT id, title FROM article WHERE views > ? ORDER BY id ASC",
+			"postgres": "SELECT id, title FROM article WHERE views > $1 ORDER BY id ASC",
+			"sqlite":   "SELECT id, title FROM article WHERE views > ? ORDER BY id ASC",
+		})
+
+		page, err := query.Paginate(2, 2)
+		if err != nil {
+			t.Fatalf("Paginate failed: %v", err)
+		}
+		if page.Total != 3 || page.LastPage != 2 || len(page.Items) != 1 {
+			t.Fatalf("Unexpected page %+v", page)
+		}
+		if title := page.Items[0]["title"]; asString(title) != "Compilers" {
+			t.Errorf("Expected Compilers, got %v (%T)", title, title)
+		}
+
+		cursor, err := e.builder().Table("article").Select("id", "views").OrderBy("views", "DESC").OrderBy("id", "ASC").
+			CursorPaginate(context.Background(), 2, "")
+		if err != nil {
+			t.Fatalf("CursorPaginate failed: %v", err)
+		}
+		next, err := e.builder().Table("article").Select("id", "views").OrderBy("views", "DESC").OrderBy("id", "ASC").
+			CursorPaginate(context.Background(), 2, cursor.NextCursor)
+		if err != nil || len(next.Items) != 1 || next.HasMore {
+			t.Errorf("Unexpected next page %+v (%v)", next, err)
+		}
+	})
+}
+
+func TestBatchOperations(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		ctx := context.Background()
+		rows := []map[string]interface{}{
+			{"name": "a", "hits": 1},
+			{"name": "b", "hits": 2},
+			{"name": "c", "hits": 3},
+		}
+		if err := e.builder().Table("counter").BatchInsert(ctx, rows, qix.WithInsertChunkSize(2)); err != nil {
+			t.Fatalf("BatchInsert failed: %v", err)
+		}
+
+		duplicates := []map[string]interface{}{{"name": "d", "hits": 4}, {"name": "a", "hits": 5}, {"name": "e", "hits": 6}}
+		err := e.builder().Table("counter").BatchInsert(ctx, duplicates, qix.BisectOnError())
+		var batchErr *qix.BatchError
+		if !errors.As(err, &batchErr) || batchErr.ApproxRow != 1 || !qix.IsDuplicateKey(err) {
+			t.Fatalf("Expected row 1 reported as a duplicate, got %v", err)
+		}
+
+		sum, err := e.builder().Table("counter").SumValue(ctx, "hits")
+		if err != nil || sum != 1+2+3+4+6 {
+			t.Errorf("Expected the valid rows inserted, got sum %v (%v)", sum, err)
+		}
+
+		var seen []string
+		err = e.builder().Table("counter").Select("name").OrderBy("name", "ASC").ChunkById(ctx, 2, "name", func(rows *sql.Rows) error {
+			for rows.Next() {
+				var name string
+				if err := rows.Scan(&name); err != nil {
+					return err
+				}
+				seen = append(seen, name)
+			}
+			return nil
+		})
+		if err != nil || !reflect.DeepEqual(seen, []string{"a", "b", "c", "d", "e"}) {
+			t.Errorf("Unexpected chunks %v (%v)", seen, err)
+		}
+	})
+}
+
+func TestUpsert(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		ctx := context.Background()
+		table := func() *qix.Builder { return e.builder().Table("counter") }
+
+		if err := table().Upsert(ctx, []map[string]interface{}{{"name": "a", "hits": 1}}, []string{"name"}, nil); err != nil {
+			t.Fatalf("Upsert insert failed: %v", err)
+		}
+		rows := []map[string]interface{}{{"name": "a", "hits": 10}, {"name": "b", "hits": 20}}
+		if err := table().Upsert(ctx, rows, []string{"name"}, nil); err != nil {
+			t.Fatalf("Upsert update failed: %v", err)
+		}
+		if err := table().Upsert(ctx, []map[string]interface{}{{"name": "b", "hits": 99}}, []string{"name"}, []string{}); err != nil {
+			t.Fatalf("Upsert ignore failed: %v", err)
+		}
+
+		hits, err := qix.PluckAs[int](ctx, table().OrderBy("name", "ASC"), "hits")
+		if err != nil || !reflect.DeepEqual(hits, []int{10, 20}) {
+			t.Errorf("Expected [10 20], got %v (%v)", hits, err)
+		}
+	})
+}
+
+func TestDriverTypes(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, e *engine) {
+		ctx := context.Background()
+
+		// Drivers return int64, []byte or string for the same columns
+		count, err := e.builder().Table("article").CountValue(ctx)
+		if err != nil || count != 3 {
+			t.Errorf("Expected 3 articles, got %d (%v)", count, err)
+		}
+		titles, err := qix.PluckAs[string](ctx, e.builder().Table("article").OrderBy("id", "ASC"), "title")
+		if err != nil || !reflect.DeepEqual(titles, []string{"Notes", "Engines", "Compilers"}) {
+			t.Errorf("Unexpected titles %v (%v)", titles, err)
+		}
+		views, err := qix.ValueAs[int](ctx, e.builder().Table("article").Where("id", "=", 3), "views")
+		if err != nil || views != 8 {
+			t.Errorf("Expected 8 views, got %d (%v)", views, err)
+		}
+
+		articles, err := qix.GetTyped[Article](ctx, e.builder().Table("article").OrderBy("id", "ASC"))
+		if err != nil || len(articles) != 3 {
+			t.Fatalf("GetTyped failed: %v", err)
+		}
+		if !articles[0].PublishedAt.Equal(epoch) || articles[2].Views != 8 {
+			t.Errorf("Unexpected article %+v", articles[0])
+		}
+
+		if _, err := e.builder().Table("article").Where("id", "=", 1).Increment(ctx, "views", 2); err != nil {
+			t.Fatalf("Increment failed: %v", err)
+		}
+		if max, err := e.builder().Table("article").MaxValue(ctx, "views"); err != nil || asString(max) != "8" {
+			t.Errorf("Expected max 8, got %v (%v)", max, err)
+		}
+	})
+}
+
+// asString renders a driver value, []byte and string alike
+func asString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,123 @@
+package qix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrJSONUnsupported is returned when a JSON condition has no equivalent
+// in the dialect
+var ErrJSONUnsupported = errors.New("json condition not supported by dialect")
+
+// WhereJsonContains matches rows whose JSON column contains value at path,
+// a MySQL style path such as "$.tags" ("" for the whole document). value is
+// encoded to JSON and bound. MySQL renders JSON_CONTAINS(column, ?, ?) and
+// Postgres column::jsonb @> ?::jsonb, reading the path with #>. SQLite has
+// no containment operator and fails with ErrJSONUnsupported.
+func (b *Builder) WhereJsonContains(column, path string, value interface{}) *Builder {
+	document, err := json.Marshal(value)
+	if err != nil {
+		b.setErr(fmt.Errorf("%w: column %q: %v", ErrUnsupportedValue, column, err))
+		return b
+	}
+
+	switch b.dialect.(type) {
+	case postgresDialect:
+		target, bindings := b.jsonbPath(column, path)
+		return b.whereRaw(target+" @> ?::jsonb", "AND", append(bindings, string(document)))
+	case sqliteDialect:
+		b.setErr(fmt.Errorf("%w: JSON_CONTAINS", ErrJSONUnsupported))
+		return b
+	}
+	if path == "" {
+		return b.whereRaw("JSON_CONTAINS("+b.quote(column)+", ?)", "AND", []interface{}{string(document)})
+	}
+	return b.whereRaw("JSON_CONTAINS("+b.quote(column)+", ?, ?)", "AND", []interface{}{string(document), path})
+}
+
+// WhereJsonLength compares the number of elements of the JSON array at
+// path ("" for the whole document) with value: JSON_LENGTH on MySQL,
+// jsonb_array_length on Postgres and json_array_length on SQLite
+func (b *Builder) WhereJsonLength(column, path string, operator string, value int) *Builder {
+	var length string
+	var bindings []interface{}
+	switch b.dialect.(type) {
+	case postgresDialect:
+		target, pathBindings := b.jsonbPath(column, path)
+		length, bindings = "jsonb_array_length("+target+")", pathBindings
+	case sqliteDialect:
+		length = b.jsonFunction("json_array_length", column, path, &bindings)
+	default:
+		length = b.jsonFunction("JSON_LENGTH", column, path, &bindings)
+	}
+	return b.whereRaw(length+" "+operator+" ?", "AND", append(bindings, value))
+}
+
+// jsonFunction renders fn(column) or fn(column, ?) with the path bound
+func (b *Builder) jsonFunction(fn, column, path string, bindings *[]interface{}) string {
+	if path == "" {
+		return fn + "(" + b.quote(column) + ")"
+	}
+	*bindings = append(*bindings, path)
+	return fn + "(" + b.quote(column) + ", ?)"
+}
+
+// jsonbPath renders the Postgres jsonb value of column at a MySQL style
+// path, bound as a text array for the #> operator
+func (b *Builder) jsonbPath(column, path string) (string, []interface{}) {
+	target := b.quote(column) + "::jsonb"
+	segments := jsonPathSegments(path)
+	if len(segments) == 0 {
+		return target, nil
+	}
+	return "(" + target + " #> ?)", []interface{}{"{" + strings.Join(segments, ",") + "}"}
+}
+
+// jsonPathSegments splits a path such as $.items[0].name into its keys
+// and indexes, as elements of a Postgres text array
+func jsonPathSegments(path string) []string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []string
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			if strings.HasPrefix(path, `"`) {
+				end := strings.Index(path[1:], `"`)
+				if end < 0 {
+					return append(segments, postgresArrayElement(path[1:]))
+				}
+				segments = append(segments, postgresArrayElement(path[1:end+1]))
+				path = path[end+2:]
+				continue
+			}
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, postgresArrayElement(path[:end]))
+			path = path[end:]
+		case '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return append(segments, strings.TrimSpace(path[1:]))
+			}
+			segments = append(segments, strings.TrimSpace(path[1:end]))
+			path = path[end+1:]
+		default:
+			// A path without the leading $. such as "tags"
+			path = "." + path
+		}
+	}
+	return segments
+}
+
+// postgresArrayElement quotes a key for a Postgres text array literal
+func postgresArrayElement(key string) string {
+	if strings.ContainsAny(key, `{},"\ `) || key == "" {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+	}
+	return key
+}
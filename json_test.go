@@ -0,0 +1,126 @@
+package qix
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWhereJsonContains(t *testing.T) {
+	db := &MockDB{}
+	tests := []struct {
+		name     string
+		builder  *Builder
+		expected string
+		bindings []interface{}
+	}{
+		{
+			name:     "MySQL",
+			builder:  New(db).Table("posts").Where("id", ">", 1).WhereJsonContains("meta", "$.tags", "go").Where("draft", "=", false),
+			expected: "SELECT * FROM posts WHERE id > ? AND JSON_CONTAINS(meta, ?, ?) AND draft = ?",
+			bindings: []interface{}{1, `"go"`, "$.tags", false},
+		},
+		{
+			name:     "MySQLWithoutPath",
+			builder:  New(db).Table("posts").WhereJsonContains("tags", "", []string{"go", "sql"}),
+			expected: "SELECT * FROM posts WHERE JSON_CONTAINS(tags, ?)",
+			bindings: []interface{}{`["go","sql"]`},
+		},
+		{
+			name:     "Postgres",
+			builder:  New(db, PostgresDialect).Table("posts").Where("id", ">", 1).WhereJsonContains("meta", "$.author.roles", "admin"),
+			expected: "SELECT * FROM posts WHERE id > $1 AND (meta::jsonb #> $2) @> $3::jsonb",
+			bindings: []interface{}{1, "{author,roles}", `"admin"`},
+		},
+		{
+			name:     "PostgresWithoutPath",
+			builder:  New(db, PostgresDialect).Table("posts").WhereJsonContains("meta", "", map[string]int{"level": 2}),
+			expected: "SELECT * FROM posts WHERE meta::jsonb @> $1::jsonb",
+			bindings: []interface{}{`{"level":2}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, bindings := tt.builder.ToSQLWithBindings()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+			if !reflect.DeepEqual(bindings, tt.bindings) {
+				t.Errorf("Expected bindings %v, got %v", tt.bindings, bindings)
+			}
+		})
+	}
+
+	if err := New(db, SQLiteDialect).Table("posts").WhereJsonContains("meta", "$.tags", "go").Err(); !errors.Is(err, ErrJSONUnsupported) {
+		t.Errorf("Expected ErrJSONUnsupported on SQLite, got %v", err)
+	}
+	if err := New(db).Table("posts").WhereJsonContains("meta", "", func() {}).Err(); !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("Expected ErrUnsupportedValue for a func, got %v", err)
+	}
+}
+
+func TestWhereJsonLength(t *testing.T) {
+	db := &MockDB{}
+	tests := []struct {
+		name     string
+		builder  *Builder
+		expected string
+		bindings []interface{}
+	}{
+		{
+			name:     "MySQL",
+			builder:  New(db).Table("posts").WhereJsonLength("meta", "$.tags", ">", 2),
+			expected: "SELECT * FROM posts WHERE JSON_LENGTH(meta, ?) > ?",
+			bindings: []interface{}{"$.tags", 2},
+		},
+		{
+			name:     "MySQLWithoutPath",
+			builder:  New(db).Table("posts").WhereJsonLength("tags", "", "=", 0),
+			expected: "SELECT * FROM posts WHERE JSON_LENGTH(tags) = ?",
+			bindings: []interface{}{0},
+		},
+		{
+			name:     "Postgres",
+			builder:  New(db, PostgresDialect).Table("posts").Where("id", "=", 5).WhereJsonLength("meta", "$.items[0].tags", "<", 3),
+			expected: "SELECT * FROM posts WHERE id = $1 AND jsonb_array_length((meta::jsonb #> $2)) < $3",
+			bindings: []interface{}{5, "{items,0,tags}", 3},
+		},
+		{
+			name:     "SQLite",
+			builder:  New(db, SQLiteDialect).Table("posts").WhereJsonLength("meta", "$.tags", ">=", 1),
+			expected: "SELECT * FROM posts WHERE json_array_length(meta, ?) >= ?",
+			bindings: []interface{}{"$.tags", 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, bindings := tt.builder.ToSQLWithBindings()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+			if !reflect.DeepEqual(bindings, tt.bindings) {
+				t.Errorf("Expected bindings %v, got %v", tt.bindings, bindings)
+			}
+		})
+	}
+}
+
+func TestJsonPathSegments(t *testing.T) {
+	tests := map[string][]string{
+		"":                 nil,
+		"$":                nil,
+		"$.tags":           {"tags"},
+		"tags.name":        {"tags", "name"},
+		"$.items[2].name":  {"items", "2", "name"},
+		`$."first name".x`: {`"first name"`, "x"},
+		`$."a,b"`:          {`"a,b"`},
+		"$[0]":             {"0"},
+	}
+	for path, expected := range tests {
+		if got := jsonPathSegments(path); !reflect.DeepEqual(got, expected) {
+			t.Errorf("jsonPathSegments(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package qix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LintLevel controls how problems found by the query lint are reported
+type LintLevel int
+
+const (
+	// LintOff disables the lint (the default)
+	LintOff LintLevel = iota
+	// LintWarn logs each problem through the package logger
+	LintWarn
+	// LintStrict fails the query with ErrQueryLint
+	LintStrict
+)
+
+// ErrQueryLint is returned at LintStrict when the query has lint problems
+var ErrQueryLint = errors.New("query lint failed")
+
+// WithQueryLint inspects the clauses of every query before it is executed
+// and reports conditions that are almost certainly mistakes: the same
+// column and operator bound to different values, IS NULL combined with an
+// equality on the same column, and LIMIT 0.
+func WithQueryLint(level LintLevel) Option {
+	return optionFunc(func(b *Builder) {
+		b.lintLevel = level
+	})
+}
+
+// WhereClause is a read-only view of a WHERE condition
+type WhereClause struct {
+	Column   string      // Column, or the full SQL of raw and nested conditions
+	Operator string      // Comparison operator, empty for raw conditions
+	Value    interface{} // Bound value of basic comparisons
+	Boolean  string      // "AND" or "OR"
+	IsColumn bool        // Value is a column name, see WhereColumn
+	IsNull   bool        // IS [NOT] NULL condition
+	IsRaw    bool        // Raw, nested or EXISTS condition
+}
+
+// GetWheres returns the WHERE conditions in the order they were added
+func (b *Builder) GetWheres() []WhereClause {
+	clauses := make([]WhereClause, len(b.wheres))
+	for i, w := range b.wheres {
+		clauses[i] = WhereClause{
+			Column:   w.column,
+			Operator: w.operator,
+			Value:    w.value,
+			Boolean:  w.boolean,
+			IsColumn: w.isColumn,
+			IsNull:   w.isNull,
+			IsRaw:    w.operator == "" && w.value == "",
+		}
+	}
+	return clauses
+}
+
+// GetLimit returns the LIMIT value and whether one was set
+func (b *Builder) GetLimit() (int, bool) {
+	if b.limit == nil {
+		return 0, false
+	}
+	return *b.limit, true
+}
+
+// GetOffset returns the OFFSET value and whether one was set
+func (b *Builder) GetOffset() (int, bool) {
+	if b.offset == nil {
+		return 0, false
+	}
+	return *b.offset, true
+}
+
+// lint reports the problems found in the builder's clauses according to
+// its lint level, it only returns an error at LintStrict
+func (b *Builder) lint() error {
+	if b.lintLevel == LintOff {
+		return nil
+	}
+	problems := b.lintProblems()
+	if len(problems) == 0 {
+		return nil
+	}
+	if b.lintLevel == LintStrict {
+		return fmt.Errorf("%w: %s", ErrQueryLint, strings.Join(problems, "; "))
+	}
+	for _, problem := range problems {
+		currentLogger().Warnf("query lint: %s", problem)
+	}
+	return nil
+}
+
+// lintProblems describes the mistakes found in the builder's clauses
+func (b *Builder) lintProblems() []string {
+	var problems []string
+
+	if limit, ok := b.GetLimit(); ok && limit == 0 {
+		problems = append(problems, "LIMIT 0 never returns rows")
+	}
+
+	wheres := b.GetWheres()
+	for _, w := range wheres {
+		// OR changes the meaning of repeated conditions, only plain
+		// conjunctions can be checked
+		if w.Boolean == "OR" {
+			return problems
+		}
+	}
+
+	type comparison struct {
+		column, operator string
+	}
+	values := make(map[comparison]interface{})
+	equals := make(map[string]bool)
+	nulls := make(map[string]bool)
+	reported := make(map[comparison]bool)
+
+	for _, w := range wheres {
+		switch {
+		case w.IsRaw || w.IsColumn:
+			continue
+		case w.IsNull:
+			if w.Operator == "IS" {
+				nulls[w.Column] = true
+			}
+			continue
+		case !isBoundOperator(w.Operator):
+			continue
+		}
+
+		key := comparison{w.Column, strings.ToUpper(w.Operator)}
+		if w.Operator == "=" {
+			equals[w.Column] = true
+		}
+		if previous, ok := values[key]; ok {
+			if !reflect.DeepEqual(previous, w.Value) && !reported[key] {
+				reported[key] = true
+				problems = append(problems, fmt.Sprintf("%s %s is compared to different values (%v and %v)", w.Column, w.Operator, previous, w.Value))
+			}
+			continue
+		}
+		values[key] = w.Value
+	}
+
+	for _, w := range wheres {
+		if w.IsNull && w.Operator == "IS" && equals[w.Column] && nulls[w.Column] {
+			problems = append(problems, fmt.Sprintf("%s IS NULL contradicts an equality on the same column", w.Column))
+			nulls[w.Column] = false
+		}
+	}
+
+	return problems
+}
+
+// isBoundOperator reports whether conditions with the operator hold their
+// bound value, IN and BETWEEN only keep the rendered placeholders
+func isBoundOperator(operator string) bool {
+	switch strings.ToUpper(operator) {
+	case "IN", "NOT IN", "EXISTS", "BETWEEN":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQueryLintRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(*Builder) *Builder
+		problem string
+	}{
+		{
+			name: "DuplicateConditionDifferentValues",
+			build: func(b *Builder) *Builder {
+				return b.Where("tenant_id", "=", 1).Where("tenant_id", "=", 2)
+			},
+			problem: "tenant_id = is compared to different values (1 and 2)",
+		},
+		{
+			name: "DuplicateConditionSameValue",
+			build: func(b *Builder) *Builder {
+				return b.Where("tenant_id", "=", 1).Where("tenant_id", "=", 1)
+			},
+		},
+		{
+			name: "DifferentOperators",
+			build: func(b *Builder) *Builder {
+				return b.Where("age", ">", 18).Where("age", "<", 65)
+			},
+		},
+		{
+			name: "DuplicateJoinedWithOr",
+			build: func(b *Builder) *Builder {
+				return b.Where("status", "=", "draft").OrWhere("status", "=", "review")
+			},
+		},
+		{
+			name: "NullAndEquality",
+			build: func(b *Builder) *Builder {
+				return b.WhereNull("deleted_at").Where("deleted_at", "=", "2024-01-01")
+			},
+			problem: "deleted_at IS NULL contradicts an equality on the same column",
+		},
+		{
+			name: "NotNullAndEquality",
+			build: func(b *Builder) *Builder {
+				return b.WhereNotNull("deleted_at").Where("deleted_at", "=", "2024-01-01")
+			},
+		},
+		{
+			name: "NullOnOtherColumn",
+			build: func(b *Builder) *Builder {
+				return b.WhereNull("deleted_at").Where("id", "=", 1)
+			},
+		},
+		{
+			name: "LimitZero",
+			build: func(b *Builder) *Builder {
+				return b.Limit(0)
+			},
+			problem: "LIMIT 0 never returns rows",
+		},
+		{
+			name: "LimitOne",
+			build: func(b *Builder) *Builder {
+				return b.Limit(1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := tt.build(New(&MockDB{}).Table("posts")).lintProblems()
+			if tt.problem == "" {
+				if len(problems) != 0 {
+					t.Errorf("Expected no problems, got %v", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || problems[0] != tt.problem {
+				t.Errorf("Expected [%s], got %v", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestQueryLintWarn(t *testing.T) {
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	_, err := New(&MockDB{}, WithQueryLint(LintWarn)).Table("posts").
+		Where("tenant_id", "=", 1).Where("tenant_id", "=", 2).Get(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the query to run, got %v", err)
+	}
+
+	warnings := logs.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "tenant_id =") {
+		t.Errorf("Expected a tenant_id warning, got %v", warnings)
+	}
+}
+
+func TestQueryLintStrict(t *testing.T) {
+	executed := 0
+	db := &MockDB{
+		queryFunc: func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			executed++
+			return nil, nil
+		},
+	}
+
+	builder := New(db, WithQueryLint(LintStrict))
+	_, err := builder.Table("posts").Limit(0).Get(context.Background())
+	if !errors.Is(err, ErrQueryLint) {
+		t.Errorf("Expected ErrQueryLint, got %v", err)
+	}
+	if executed != 0 {
+		t.Errorf("Expected no query to be executed, got %d", executed)
+	}
+
+	if _, err := builder.newQuery().Table("posts").Where("id", "=", 1).Get(context.Background()); err != nil {
+		t.Errorf("Expected clean query to run, got %v", err)
+	}
+	if executed != 1 {
+		t.Errorf("Expected the clean query to be executed, got %d", executed)
+	}
+}
+
+func TestQueryLintOffByDefault(t *testing.T) {
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	if _, err := New(&MockDB{}).Table("posts").Limit(0).Get(context.Background()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(logs.Warnings()) != 0 {
+		t.Errorf("Expected no warnings, got %v", logs.Warnings())
+	}
+}
+
+func TestGetWheres(t *testing.T) {
+	wheres := New(&MockDB{}).Table("posts").
+		Where("id", "=", 1).
+		WhereNullWithBoolean("deleted_at", "OR", false).
+		WhereRaw("score > 10").
+		GetWheres()
+
+	if len(wheres) != 3 {
+		t.Fatalf("Expected 3 clauses, got %d", len(wheres))
+	}
+	if wheres[0].Column != "id" || wheres[0].Operator != "=" || wheres[0].Value != 1 {
+		t.Errorf("Unexpected first clause: %+v", wheres[0])
+	}
+	if !wheres[1].IsNull || wheres[1].Boolean != "OR" {
+		t.Errorf("Unexpected second clause: %+v", wheres[1])
+	}
+	if !wheres[2].IsRaw {
+		t.Errorf("Expected third clause to be raw: %+v", wheres[2])
+	}
+}
@@ -0,0 +1,89 @@
+package qix
+
+// lockMode is the row lock requested for a SELECT query
+type lockMode int
+
+const (
+	lockNone lockMode = iota
+	lockForUpdate
+	lockForUpdateSkipLocked
+	lockShared
+)
+
+// ForUpdate locks the selected rows for writing until the end of the
+// transaction, rendering FOR UPDATE. SQLite has no row locks and renders
+// nothing, its transactions already lock the database. Outside a
+// transaction the lock would be released at once, so ForUpdate logs a
+// warning and leaves the query unchanged.
+func (b *Builder) ForUpdate() *Builder {
+	return b.setLock(lockForUpdate, "ForUpdate")
+}
+
+// LockForUpdate is an alias of ForUpdate
+func (b *Builder) LockForUpdate() *Builder {
+	return b.setLock(lockForUpdate, "LockForUpdate")
+}
+
+// ForUpdateSkipLocked is ForUpdate skipping the rows locked by other
+// transactions instead of waiting for them, rendering FOR UPDATE SKIP
+// LOCKED (MySQL 8+ and Postgres). It suits job queues where workers each
+// claim different rows.
+func (b *Builder) ForUpdateSkipLocked() *Builder {
+	return b.setLock(lockForUpdateSkipLocked, "ForUpdateSkipLocked")
+}
+
+// SharedLock locks the selected rows against writes by other transactions,
+// rendering LOCK IN SHARE MODE for MySQL and FOR SHARE for Postgres.
+// SQLite renders nothing and outside a transaction it is a no-op, see
+// ForUpdate.
+func (b *Builder) SharedLock() *Builder {
+	return b.setLock(lockShared, "SharedLock")
+}
+
+// setLock sets the row lock when the builder runs in a transaction
+func (b *Builder) setLock(mode lockMode, method string) *Builder {
+	if !b.inTransaction() {
+		currentLogger().Warnf("%s ignored outside a transaction on %s", method, b.table)
+		return b
+	}
+	b.lock = mode
+	return b
+}
+
+// inTransaction reports whether the builder runs in a transaction, one
+// started by Transaction or a connection that commits like *sql.Tx and the
+// types wrapping it
+func (b *Builder) inTransaction() bool {
+	if b.txHooks != nil {
+		return true
+	}
+	_, ok := b.db.(interface {
+		Commit() error
+		Rollback() error
+	})
+	return ok
+}
+
+// lockClause renders the row lock of the query for the dialect
+func (b *Builder) lockClause() string {
+	if b.lock == lockNone {
+		return ""
+	}
+
+	switch b.dialect.(type) {
+	case sqliteDialect:
+		return ""
+	case postgresDialect:
+		if b.lock == lockShared {
+			return " FOR SHARE"
+		}
+	default:
+		if b.lock == lockShared {
+			return " LOCK IN SHARE MODE"
+		}
+	}
+	if b.lock == lockForUpdateSkipLocked {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return " FOR UPDATE"
+}
@@ -0,0 +1,120 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+// lockTx returns a builder running in a transaction of a mock connection
+func lockTx(t *testing.T, opts ...Option) *Builder {
+	t.Helper()
+	mock := NewMockSQL()
+	tx, err := mock.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		mock.DB.Close()
+	})
+	return New(tx, opts...)
+}
+
+func TestRowLocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		build    func(*Builder) *Builder
+		expected string
+	}{
+		{"MySQL for update", MySQLDialect, (*Builder).ForUpdate, "SELECT * FROM items WHERE id = ? ORDER BY id ASC LIMIT ? FOR UPDATE"},
+		{"MySQL shared", MySQLDialect, (*Builder).SharedLock, "SELECT * FROM items WHERE id = ? ORDER BY id ASC LIMIT ? LOCK IN SHARE MODE"},
+		{"MySQL skip locked", MySQLDialect, (*Builder).ForUpdateSkipLocked, "SELECT * FROM items WHERE id = ? ORDER BY id ASC LIMIT ? FOR UPDATE SKIP LOCKED"},
+		{"Alias", MySQLDialect, (*Builder).LockForUpdate, "SELECT * FROM items WHERE id = ? ORDER BY id ASC LIMIT ? FOR UPDATE"},
+		{"Default shared", nil, (*Builder).SharedLock, "SELECT * FROM items WHERE id = ? ORDER BY id ASC LIMIT ? LOCK IN SHARE MODE"},
+		{"Postgres for update", PostgresDialect, (*Builder).LockForUpdate, "SELECT * FROM items WHERE id = $1 ORDER BY id ASC LIMIT $2 FOR UPDATE"},
+		{"Postgres skip locked", PostgresDialect, (*Builder).ForUpdateSkipLocked, "SELECT * FROM items WHERE id = $1 ORDER BY id ASC LIMIT $2 FOR UPDATE SKIP LOCKED"},
+		{"Postgres shared", PostgresDialect, (*Builder).SharedLock, "SELECT * FROM items WHERE id = $1 ORDER BY id ASC LIMIT $2 FOR SHARE"},
+		{"SQLite", SQLiteDialect, (*Builder).LockForUpdate, "SELECT * FROM items WHERE id = ? ORDER BY id ASC LIMIT ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.dialect != nil {
+				opts = append(opts, tt.dialect)
+			}
+			builder := lockTx(t, opts...).Table("items").Where("id", "=", 1).OrderBy("id", "ASC").Limit(1)
+			if sql := tt.build(builder).ToSQL(); sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestRowLockOnlyOnSelect(t *testing.T) {
+	builder := lockTx(t).Table("items").Where("id", "=", 1).ForUpdate()
+
+	query, err := builder.Clone().Update(map[string]interface{}{"stock": 4}).statementSQL()
+	if err != nil {
+		t.Fatalf("statementSQL failed: %v", err)
+	}
+	if query != "UPDATE items SET stock = ? WHERE id = ?" {
+		t.Errorf("Unexpected update SQL: %s", query)
+	}
+
+	query, _ = builder.Clone().deleteSQL()
+	if query != "DELETE FROM items WHERE id = ?" {
+		t.Errorf("Unexpected delete SQL: %s", query)
+	}
+}
+
+// wrappedTx is a transaction behind an application's own connection type
+type wrappedTx struct {
+	*sql.Tx
+}
+
+func TestRowLockWrappedTransaction(t *testing.T) {
+	tx := lockTx(t).db.(*sql.Tx)
+	if sql := New(wrappedTx{tx}).Table("items").ForUpdate().ToSQL(); sql != "SELECT * FROM items FOR UPDATE" {
+		t.Errorf("Expected the lock in a wrapped transaction, got %s", sql)
+	}
+
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+	err := New(mock.DB).Transaction(context.Background(), func(tx *Builder) error {
+		tx.db = &MockDB{} // A connection wrapped by middleware, the builder keeps the transaction state
+		if sql := tx.Table("items").SharedLock().ToSQL(); sql != "SELECT * FROM items LOCK IN SHARE MODE" {
+			t.Errorf("Expected the lock in Transaction, got %s", sql)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+}
+
+func TestRowLockOutsideTransaction(t *testing.T) {
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	locks := map[string]func(*Builder) *Builder{
+		"ForUpdate":           (*Builder).ForUpdate,
+		"ForUpdateSkipLocked": (*Builder).ForUpdateSkipLocked,
+		"SharedLock":          (*Builder).SharedLock,
+	}
+	for name, lock := range locks {
+		sql := lock(New(&MockDB{}).Table("items").Where("id", "=", 1)).ToSQL()
+		if sql != "SELECT * FROM items WHERE id = ?" {
+			t.Errorf("%s: expected no lock outside a transaction, got %s", name, sql)
+		}
+	}
+
+	warnings := logs.Warnings()
+	if len(warnings) != len(locks) || !strings.Contains(warnings[0], "outside a transaction") {
+		t.Errorf("Expected a warning per lock, got %v", warnings)
+	}
+}
@@ -0,0 +1,59 @@
+package qix
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Logger receives diagnostics emitted by qix, such as N+1 warnings
+type Logger interface {
+	Warnf(format string, args ...interface{})
+}
+
+// stdLogger writes diagnostics through the standard log package
+type stdLogger struct{}
+
+func (stdLogger) Warnf(format string, args ...interface{}) {
+	log.Printf("qix: warning: "+format, args...)
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = stdLogger{}
+)
+
+// SetLogger replaces the package logger, nil restores the default
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	if l == nil {
+		l = stdLogger{}
+	}
+	logger = l
+}
+
+// currentLogger returns the package logger
+func currentLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+var (
+	fingerprintString  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	fingerprintNumber  = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	fingerprintList    = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fingerprintSpacing = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint normalizes a query so statements that only differ in literal
+// values or IN-list length share the same shape
+func Fingerprint(query string) string {
+	query = fingerprintString.ReplaceAllString(query, "?")
+	query = fingerprintNumber.ReplaceAllString(query, "?")
+	query = fingerprintList.ReplaceAllString(query, "(?+)")
+	query = fingerprintSpacing.ReplaceAllString(query, " ")
+	return strings.TrimSpace(query)
+}
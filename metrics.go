@@ -0,0 +1,69 @@
+package qix
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics holds lightweight execution counters shared by a builder,
+// its transaction builders and the statements it prepares
+type Metrics struct {
+	queries  atomic.Int64
+	errors   atomic.Int64
+	duration atomic.Int64 // nanoseconds
+}
+
+// MetricsSnapshot is a point-in-time copy of the counters
+type MetricsSnapshot struct {
+	Queries       int64
+	Errors        int64
+	TotalDuration time.Duration
+}
+
+// record adds one execution to the counters, it is a no-op on nil metrics
+func (m *Metrics) record(elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.queries.Add(1)
+	m.duration.Add(int64(elapsed))
+	if err != nil {
+		m.errors.Add(1)
+	}
+}
+
+// Snapshot returns the current counter values
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	if m == nil {
+		return MetricsSnapshot{}
+	}
+	return MetricsSnapshot{
+		Queries:       m.queries.Load(),
+		Errors:        m.errors.Load(),
+		TotalDuration: time.Duration(m.duration.Load()),
+	}
+}
+
+// Reset sets all counters back to zero
+func (m *Metrics) Reset() {
+	if m == nil {
+		return
+	}
+	m.queries.Store(0)
+	m.errors.Store(0)
+	m.duration.Store(0)
+}
+
+// WithMetrics enables execution counters on the builder
+func (b *Builder) WithMetrics() *Builder {
+	if b.metrics == nil {
+		b.metrics = &Metrics{}
+	}
+	return b
+}
+
+// Metrics returns a snapshot of the execution counters.
+// The snapshot is empty when WithMetrics was not called.
+func (b *Builder) Metrics() MetricsSnapshot {
+	return b.metrics.Snapshot()
+}
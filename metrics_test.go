@@ -0,0 +1,87 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	db := &MockDB{
+		queryFunc: func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			time.Sleep(time.Millisecond)
+			return nil, nil
+		},
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			if query == "DELETE FROM locked" {
+				return nil, errors.New("table is locked")
+			}
+			return MockResult{lastID: 1, rowsAffected: 1}, nil
+		},
+	}
+
+	builder := New(db).WithMetrics()
+
+	if _, err := builder.Table("users").Get(ctx); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := New(db).Table("users").InsertGetId(ctx, map[string]interface{}{"name": "John"}); err != nil {
+		t.Fatalf("InsertGetId failed: %v", err)
+	}
+	if _, err := builder.Table("users").InsertGetId(ctx, map[string]interface{}{"name": "John"}); err != nil {
+		t.Fatalf("InsertGetId failed: %v", err)
+	}
+	if _, err := builder.Table("locked").DeleteWithContext(ctx); err == nil {
+		t.Fatal("Expected delete to fail")
+	}
+
+	snapshot := builder.Metrics()
+	if snapshot.Queries != 3 {
+		t.Errorf("Expected 3 queries, got %d", snapshot.Queries)
+	}
+	if snapshot.Errors != 1 {
+		t.Errorf("Expected 1 error, got %d", snapshot.Errors)
+	}
+	if snapshot.TotalDuration < time.Millisecond {
+		t.Errorf("Expected total duration of at least 1ms, got %v", snapshot.TotalDuration)
+	}
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	builder := New(&MockDB{}).Table("users")
+	if _, err := builder.Get(context.Background()); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if snapshot := builder.Metrics(); snapshot != (MetricsSnapshot{}) {
+		t.Errorf("Expected empty snapshot, got %+v", snapshot)
+	}
+}
+
+func TestMetricsPreparedQuery(t *testing.T) {
+	ctx := context.Background()
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	builder := New(db).WithMetrics()
+	pq, err := builder.Table("events").Insert(map[string]interface{}{"topic": nil}).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := pq.Exec(ctx, i); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+
+	if queries := builder.Metrics().Queries; queries != 3 {
+		t.Errorf("Expected 3 queries, got %d", queries)
+	}
+}
@@ -0,0 +1,268 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// MockSQL is an in-memory database/sql driver used by tests that need real
+// *sql.Rows, prepared statements or transactions.
+type MockSQL struct {
+	DB *sql.DB
+
+	mu        sync.Mutex
+	queryFunc func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error)
+	execFunc  func(ctx context.Context, query string, args []interface{}) (driver.Result, error)
+	calls     []MockCall
+	prepares  int
+	begins    int
+	commits   int
+	rollbacks int
+}
+
+// MockCall records a statement sent to the mock driver
+type MockCall struct {
+	Query string
+	Args  []interface{}
+}
+
+// MockResultSet is the data returned for a query
+type MockResultSet struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+var (
+	mockSQLRegistry sync.Map
+	mockSQLCounter  int64
+	mockSQLOnce     sync.Once
+)
+
+// NewMockSQL opens a *sql.DB backed by a fresh MockSQL instance
+func NewMockSQL() *MockSQL {
+	mockSQLOnce.Do(func() {
+		sql.Register("qixmock", mockSQLDriver{})
+	})
+
+	m := &MockSQL{}
+	dsn := fmt.Sprintf("mock-%d", atomic.AddInt64(&mockSQLCounter, 1))
+	mockSQLRegistry.Store(dsn, m)
+
+	db, err := sql.Open("qixmock", dsn)
+	if err != nil {
+		panic(err)
+	}
+	m.DB = db
+	return m
+}
+
+// OnQuery sets the handler used for queries
+func (m *MockSQL) OnQuery(fn func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error)) *MockSQL {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queryFunc = fn
+	return m
+}
+
+// OnExec sets the handler used for statements without result rows
+func (m *MockSQL) OnExec(fn func(ctx context.Context, query string, args []interface{}) (driver.Result, error)) *MockSQL {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.execFunc = fn
+	return m
+}
+
+// Returning makes every query return the given result set
+func (m *MockSQL) Returning(columns []string, rows ...[]interface{}) *MockSQL {
+	return m.OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		return &MockResultSet{Columns: columns, Rows: rows}, nil
+	})
+}
+
+// Calls returns a copy of the recorded statements
+func (m *MockSQL) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// Prepares returns how many statements were prepared on the driver
+func (m *MockSQL) Prepares() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.prepares
+}
+
+func (m *MockSQL) record(query string, args []interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, MockCall{Query: query, Args: args})
+}
+
+func (m *MockSQL) query(ctx context.Context, query string, args []interface{}) (driver.Rows, error) {
+	m.record(query, args)
+	m.mu.Lock()
+	fn := m.queryFunc
+	m.mu.Unlock()
+
+	if fn == nil {
+		return &mockSQLRows{}, nil
+	}
+	rs, err := fn(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		rs = &MockResultSet{}
+	}
+	return &mockSQLRows{columns: rs.Columns, rows: rs.Rows}, nil
+}
+
+func (m *MockSQL) exec(ctx context.Context, query string, args []interface{}) (driver.Result, error) {
+	m.record(query, args)
+	m.mu.Lock()
+	fn := m.execFunc
+	m.mu.Unlock()
+
+	if fn == nil {
+		return driver.RowsAffected(0), nil
+	}
+	return fn(ctx, query, args)
+}
+
+type mockSQLDriver struct{}
+
+func (mockSQLDriver) Open(name string) (driver.Conn, error) {
+	m, ok := mockSQLRegistry.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown mock dsn %q", name)
+	}
+	return &mockSQLConn{mock: m.(*MockSQL)}, nil
+}
+
+type mockSQLConn struct {
+	mock *MockSQL
+}
+
+func (c *mockSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *mockSQLConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	c.mock.mu.Lock()
+	c.mock.prepares++
+	c.mock.mu.Unlock()
+	return &mockSQLStmt{conn: c, query: query}, nil
+}
+
+func (c *mockSQLConn) Close() error {
+	return nil
+}
+
+func (c *mockSQLConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *mockSQLConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.mock.mu.Lock()
+	c.mock.begins++
+	c.mock.mu.Unlock()
+	return &mockSQLTx{mock: c.mock}, nil
+}
+
+func (c *mockSQLConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.mock.query(ctx, query, namedValues(args))
+}
+
+func (c *mockSQLConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.mock.exec(ctx, query, namedValues(args))
+}
+
+func namedValues(args []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+type mockSQLStmt struct {
+	conn  *mockSQLConn
+	query string
+}
+
+func (s *mockSQLStmt) Close() error {
+	return nil
+}
+
+func (s *mockSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s *mockSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("use ExecContext")
+}
+
+func (s *mockSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("use QueryContext")
+}
+
+func (s *mockSQLStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.mock.exec(ctx, s.query, namedValues(args))
+}
+
+func (s *mockSQLStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.mock.query(ctx, s.query, namedValues(args))
+}
+
+type mockSQLTx struct {
+	mock *MockSQL
+}
+
+func (t *mockSQLTx) Commit() error {
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+	t.mock.commits++
+	return nil
+}
+
+func (t *mockSQLTx) Rollback() error {
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+	t.mock.rollbacks++
+	return nil
+}
+
+type mockSQLRows struct {
+	columns []string
+	rows    [][]interface{}
+	pos     int
+}
+
+func (r *mockSQLRows) Columns() []string {
+	return r.columns
+}
+
+func (r *mockSQLRows) Close() error {
+	return nil
+}
+
+func (r *mockSQLRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	row := r.rows[r.pos]
+	r.pos++
+	for i := range dest {
+		if i < len(row) {
+			dest[i] = row[i]
+		}
+	}
+	return nil
+}
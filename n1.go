@@ -0,0 +1,143 @@
+package qix
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// N1Option configures N+1 detection
+type N1Option func(*n1Detector)
+
+// N1Threshold sets how many identical queries within the window trigger a warning (default 10)
+func N1Threshold(n int) N1Option {
+	return func(d *n1Detector) {
+		d.threshold = n
+	}
+}
+
+// N1Window sets the time window in which repeated queries are counted (default 1s)
+func N1Window(window time.Duration) N1Option {
+	return func(d *n1Detector) {
+		d.window = window
+	}
+}
+
+// n1Detector counts repeated SELECT fingerprints within a sliding window
+type n1Detector struct {
+	threshold int
+	window    time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*n1Entry
+}
+
+type n1Entry struct {
+	first  time.Time
+	count  int
+	warned bool
+}
+
+// maxN1Entries bounds the fingerprints kept before expired ones are pruned
+const maxN1Entries = 1024
+
+var (
+	n1Mu     sync.RWMutex
+	detector *n1Detector
+)
+
+// EnableN1Detection logs a warning when the same SELECT shape runs many
+// times in quick succession, the usual symptom of loading relations in a loop.
+// It is meant for development, counting adds overhead to every query.
+func EnableN1Detection(opts ...N1Option) {
+	d := &n1Detector{
+		threshold: 10,
+		window:    time.Second,
+		seen:      make(map[string]*n1Entry),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	n1Mu.Lock()
+	detector = d
+	n1Mu.Unlock()
+}
+
+// DisableN1Detection turns N+1 detection off
+func DisableN1Detection() {
+	n1Mu.Lock()
+	detector = nil
+	n1Mu.Unlock()
+}
+
+// observeN1 feeds an executed query to the detector when enabled
+func observeN1(event *QueryEvent) {
+	n1Mu.RLock()
+	d := detector
+	n1Mu.RUnlock()
+
+	if d != nil {
+		d.observe(event.SQL, time.Now())
+	}
+}
+
+func (d *n1Detector) observe(query string, now time.Time) {
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT") {
+		return
+	}
+	fingerprint := Fingerprint(query)
+
+	d.mu.Lock()
+	entry, ok := d.seen[fingerprint]
+	if !ok || now.Sub(entry.first) > d.window {
+		if len(d.seen) >= maxN1Entries {
+			d.prune(now)
+		}
+		entry = &n1Entry{first: now}
+		d.seen[fingerprint] = entry
+	}
+	entry.count++
+	fire := entry.count >= d.threshold && !entry.warned
+	if fire {
+		entry.warned = true
+	}
+	count := entry.count
+	d.mu.Unlock()
+
+	if fire {
+		currentLogger().Warnf("possible N+1: %q ran %d times within %s (called from %s)",
+			fingerprint, count, d.window, callerHint())
+	}
+}
+
+// prune drops fingerprints whose window has passed, callers must hold d.mu
+func (d *n1Detector) prune(now time.Time) {
+	for fingerprint, entry := range d.seen {
+		if now.Sub(entry.first) > d.window {
+			delete(d.seen, fingerprint)
+		}
+	}
+}
+
+// qixPackage is the import path used to skip frames inside this package
+var qixPackage = reflect.TypeOf(Builder{}).PkgPath() + "."
+
+// callerHint returns the first stack frame outside qix and database/sql
+func callerHint() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, qixPackage) && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal && !strings.HasPrefix(frame.Function, "database/sql.") && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
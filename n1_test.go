@@ -0,0 +1,121 @@
+package qix
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingLogger collects warnings for assertions
+type recordingLogger struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warnings...)
+}
+
+func TestN1DetectionWarnsPastThreshold(t *testing.T) {
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	EnableN1Detection(N1Threshold(5), N1Window(time.Minute))
+	defer DisableN1Detection()
+
+	ctx := context.Background()
+	db := &MockDB{}
+
+	// Loading comments post by post, the classic N+1 pattern
+	for postID := 1; postID <= 4; postID++ {
+		New(db).Table("comment").Where("post_id", "=", postID).Get(ctx)
+	}
+	if len(logs.Warnings()) != 0 {
+		t.Fatalf("Expected no warning below the threshold, got %v", logs.Warnings())
+	}
+
+	for postID := 5; postID <= 12; postID++ {
+		New(db).Table("comment").Where("post_id", "=", postID).Get(ctx)
+	}
+
+	warnings := logs.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected exactly one warning, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "SELECT * FROM comment WHERE post_id = ?") {
+		t.Errorf("Expected warning to include the query shape, got %s", warnings[0])
+	}
+	if !strings.Contains(warnings[0], "n1_test.go") {
+		t.Errorf("Expected warning to point at the calling code, got %s", warnings[0])
+	}
+}
+
+func TestN1DetectionIgnoresDistinctQueries(t *testing.T) {
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	EnableN1Detection(N1Threshold(3))
+	defer DisableN1Detection()
+
+	ctx := context.Background()
+	for _, table := range []string{"users", "posts", "tags", "comments", "avatars"} {
+		New(&MockDB{}).Table(table).Where("id", "=", 1).Get(ctx)
+	}
+
+	if warnings := logs.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestN1DetectionWindowExpires(t *testing.T) {
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	d := &n1Detector{threshold: 3, window: time.Second, seen: make(map[string]*n1Entry)}
+	start := time.Now()
+	query := "SELECT * FROM comment WHERE post_id = ?"
+
+	d.observe(query, start)
+	d.observe(query, start.Add(500*time.Millisecond))
+	d.observe(query, start.Add(2*time.Second)) // window restarted
+	d.observe(query, start.Add(2500*time.Millisecond))
+
+	if warnings := logs.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings across windows, got %v", warnings)
+	}
+
+	d.observe(query, start.Add(2600*time.Millisecond))
+	if warnings := logs.Warnings(); len(warnings) != 1 {
+		t.Errorf("Expected a warning within the new window, got %v", warnings)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := map[string]string{
+		"SELECT * FROM users WHERE id = 42":              "SELECT * FROM users WHERE id = ?",
+		"SELECT * FROM users WHERE name = 'O''Brien'":    "SELECT * FROM users WHERE name = ?",
+		"SELECT * FROM users WHERE id IN (?, ?, ?)":      "SELECT * FROM users WHERE id IN (?+)",
+		"SELECT *   FROM users\n WHERE id IN (?)":        "SELECT * FROM users WHERE id IN (?+)",
+		"SELECT * FROM users2 WHERE score > 1.5 LIMIT ?": "SELECT * FROM users2 WHERE score > ? LIMIT ?",
+	}
+
+	for input, expected := range tests {
+		if got := Fingerprint(input); got != expected {
+			t.Errorf("Fingerprint(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
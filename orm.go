@@ -6,21 +6,29 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 )
 
 // Model represents a database model with ORM capabilities
 type Model struct {
-	builder    *Builder
-	value      interface{}
-	table      string
-	pk         string
-	fields     []Field
-	eagerLoad  map[string]func(*Builder) *Builder // Eager loading callbacks
-	preloaded  map[string]interface{}             // Preloaded relations
-	isPreload  bool                               // Whether the model is being used for preloading
-	relManager *relationManager                   // For handling relationships
+	builder      *Builder
+	value        interface{}
+	table        string
+	schema       string // Schema of the table, see SetSchema
+	pk           string
+	fields       []Field
+	eagerLoad    map[string]func(*Builder) *Builder // Eager loading callbacks
+	preloaded    map[string]interface{}             // Preloaded relations
+	isPreload    bool                               // Whether the model is being used for preloading
+	relManager   *relationManager                   // For handling relationships
+	err          error                              // Deferred error surfaced when the query runs
+	tracker      *changeTracker                     // Loaded values for dirty tracking, see TrackChanges
+	joinStrategy bool                               // Join to-one eager loads into Find, see WithJoinStrategy
+	beforeImage  bool                               // Read the row before updates for column subscribers, see FetchBeforeImage
+	softDelete   string                             // Column set by Delete instead of removing the row, see SetSoftDeleteColumn
+	trashed      trashedScope                       // Soft deleted rows included in reads, see WithTrashed
 }
 
 // relationManager manages model relationships
@@ -32,13 +40,18 @@ type relationManager struct {
 
 // field represents a struct field mapped to a database column
 type Field struct {
-	name     string    // Go field name
-	column   string    // DB column name
-	isPK     bool      // Is primary key
-	isAuto   bool      // Is auto-increment
-	omitZero bool      // Omit zero values
-	omit     bool      // Omit from operations
-	relation *relation // Relation information if field is a relation
+	name      string           // Go field name
+	column    string           // DB column name
+	isPK      bool             // Is primary key
+	isAuto    bool             // Is auto-increment
+	omitZero  bool             // Omit zero values
+	omit      bool             // Omit from operations
+	isExtras  bool             // Receives scanned columns without a matching field
+	createdAt bool             // Set to the current time on create
+	updatedAt bool             // Set to the current time on create and on updates that change something
+	unique    bool             // Unique column, see CreateStrict
+	relation  *relation        // Relation information if field is a relation
+	rules     []validationRule // Rules from the validate tag
 }
 
 // relation defines a relationship between models
@@ -51,6 +64,8 @@ type relation struct {
 	pivot       string           // Pivot table for many-to-many
 	pivotFk     string           // Pivot foreign key
 	pivotRfk    string           // Pivot related foreign key
+	schema      string           // Schema of the target table, see Model.relationTable
+	inferred    bool             // Guessed from the field type rather than declared with a rel tag
 }
 
 // relationshipType defines types of relationships
@@ -63,6 +78,19 @@ const (
 	relationManyToMany
 )
 
+// AfterFindHook is implemented by models that derive state after being
+// loaded, for example computed display names or decrypted payloads.
+// It runs for every instance scanned by Find, First, All, Where and eager loading.
+type AfterFindHook interface {
+	AfterFind(ctx context.Context) error
+}
+
+// BeforeSelectHook is implemented by models that adjust read queries right
+// before they are executed, for example to force a deleted_at scope
+type BeforeSelectHook interface {
+	BeforeSelect(q *Builder)
+}
+
 // Global relation manager
 var globalRelManager = &relationManager{
 	registry:   make(map[reflect.Type]*Model),
@@ -70,14 +98,14 @@ var globalRelManager = &relationManager{
 }
 
 // NewModel creates a new ORM model
-func NewModel(db DB, value interface{}) (*Model, error) {
+func NewModel(db DB, value interface{}, opts ...Option) (*Model, error) {
 	// Set the DB for the relation manager if not already set
 	if globalRelManager.db == nil {
 		globalRelManager.db = db
 	}
 
 	m := &Model{
-		builder:    New(db),
+		builder:    New(db, opts...),
 		value:      value,
 		pk:         "id", // Default primary key
 		eagerLoad:  make(map[string]func(*Builder) *Builder),
@@ -150,6 +178,7 @@ func (m *Model) parseStruct() error {
 		f := Field{
 			name:   field.Name,
 			column: column,
+			rules:  parseValidateTag(field.Tag.Get("validate")),
 		}
 
 		// Parse options
@@ -164,6 +193,17 @@ func (m *Model) parseStruct() error {
 				f.omitZero = true
 			case "omit":
 				f.omit = true
+			case "extras":
+				f.isExtras = true
+				f.omit = true
+			case "created":
+				f.createdAt = true
+			case "updated":
+				f.updatedAt = true
+			case "unique":
+				f.unique = true
+			case "softDelete":
+				m.softDelete = column
 			}
 		}
 
@@ -187,6 +227,7 @@ func (m *Model) parseStruct() error {
 				// Potential belongsTo or hasOne relationship
 				rel := &relation{
 					modelType: fieldType,
+					inferred:  true,
 				}
 
 				// Try to determine relationship type and keys
@@ -217,6 +258,7 @@ func (m *Model) parseStruct() error {
 					rel := &relation{
 						modelType: elemType,
 						relType:   relationHasMany,
+						inferred:  true,
 					}
 
 					// Try to determine keys
@@ -260,7 +302,7 @@ func (m *Model) All(ctx context.Context) (interface{}, error) {
 	results := reflect.MakeSlice(sliceType, 0, 0)
 
 	// Build query
-	rows, err := m.builder.Table(m.table).Get(ctx)
+	rows, err := m.get(ctx, m.builder.Table(m.from()))
 	if err != nil {
 		return nil, err
 	}
@@ -276,6 +318,10 @@ func (m *Model) All(ctx context.Context) (interface{}, error) {
 			return nil, err
 		}
 
+		if err := m.afterFind(ctx, result.Addr().Interface()); err != nil {
+			return nil, err
+		}
+
 		// Append to results slice
 		results = reflect.Append(results, result)
 	}
@@ -287,11 +333,24 @@ func (m *Model) All(ctx context.Context) (interface{}, error) {
 func (m *Model) Find(ctx context.Context, id interface{}) (interface{}, error) {
 	result := reflect.New(reflect.TypeOf(m.value)).Interface()
 
+	eagerLoad := m.eagerLoad
+	var joined []joinedRelation
+	if m.joinStrategy {
+		var err error
+		if joined, eagerLoad, err = m.splitEagerLoads(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build query
-	rows, err := m.builder.Table(m.table).
-		Where(m.pk, "=", id).
-		Limit(1).
-		Get(ctx)
+	q := m.builder.Table(m.from())
+	if len(joined) > 0 {
+		m.joinRelations(q, joined)
+		q.Where(m.table+"."+m.pk, "=", id)
+	} else {
+		q.Where(m.pk, "=", id)
+	}
+	rows, err := m.get(ctx, q.Limit(1))
 
 	if err != nil {
 		return nil, err
@@ -304,17 +363,22 @@ func (m *Model) Find(ctx context.Context, id interface{}) (interface{}, error) {
 	}
 
 	// Map columns to struct fields
-	if err := m.scanInto(rows, result); err != nil {
+	if len(joined) > 0 {
+		err = m.scanJoined(ctx, rows, reflect.ValueOf(result).Elem(), joined)
+	} else {
+		err = m.scanInto(rows, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.afterFind(ctx, result); err != nil {
 		return nil, err
 	}
 
 	// Load eager relations if any
-	if len(m.eagerLoad) > 0 {
-		for relation, customQuery := range m.eagerLoad {
-			if err := m.loadRelation(ctx, result, relation, customQuery); err != nil {
-				return nil, fmt.Errorf("error loading relation '%s': %w", relation, err)
-			}
-		}
+	if err := m.loadEagerRelations(ctx, result, eagerLoad); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -327,9 +391,8 @@ func (m *Model) Where(ctx context.Context, column string, operator string, value
 	results := reflect.MakeSlice(sliceType, 0, 0)
 
 	// Build query
-	rows, err := m.builder.Table(m.table).
-		Where(column, operator, value).
-		Get(ctx)
+	rows, err := m.get(ctx, m.builder.Table(m.from()).
+		Where(column, operator, value))
 
 	if err != nil {
 		return nil, err
@@ -346,24 +409,26 @@ func (m *Model) Where(ctx context.Context, column string, operator string, value
 			return nil, err
 		}
 
+		if err := m.afterFind(ctx, result.Addr().Interface()); err != nil {
+			return nil, err
+		}
+
 		// Append to results slice
 		results = reflect.Append(results, result)
 	}
 
 	// Load eager relations if any
 	if len(m.eagerLoad) > 0 && results.Len() > 0 {
-		for relation, customQuery := range m.eagerLoad {
-			// Create pointer to slice for loadRelation
-			resultsPtr := reflect.New(results.Type())
-			resultsPtr.Elem().Set(results)
+		// Create pointer to slice for loadRelation
+		resultsPtr := reflect.New(results.Type())
+		resultsPtr.Elem().Set(results)
 
-			if err := m.loadRelation(ctx, resultsPtr.Interface(), relation, customQuery); err != nil {
-				return nil, fmt.Errorf("error loading relation '%s': %w", relation, err)
-			}
-
-			// Update results with potentially modified values
-			results = resultsPtr.Elem()
+		if err := m.loadEagerRelations(ctx, resultsPtr.Interface(), m.eagerLoad); err != nil {
+			return nil, err
 		}
+
+		// Update results with potentially modified values
+		results = resultsPtr.Elem()
 	}
 
 	return results.Interface(), nil
@@ -371,14 +436,22 @@ func (m *Model) Where(ctx context.Context, column string, operator string, value
 
 // Create inserts a new record
 func (m *Model) Create(ctx context.Context, data interface{}) (int64, error) {
+	if err := m.validate(data); err != nil {
+		return 0, err
+	}
+
 	// Extract values from struct
 	values, err := m.extractValues(data, true)
 	if err != nil {
 		return 0, err
 	}
 
+	now := time.Now()
+	m.touch(data, values, m.timestampField(false), now, true)
+	m.touch(data, values, m.timestampField(true), now, true)
+
 	// Insert into database
-	return m.builder.Table(m.table).InsertGetId(ctx, values)
+	return m.writeQuery().InsertGetId(ctx, values)
 }
 
 // Update updates a record by primary key
@@ -392,6 +465,10 @@ func (m *Model) Update(ctx context.Context, data interface{}) (int64, error) {
 		return 0, errors.New("data must be a struct or pointer to struct")
 	}
 
+	if err := m.validate(data); err != nil {
+		return 0, err
+	}
+
 	// Get primary key value
 	var pkValue interface{}
 	for _, f := range m.fields {
@@ -411,17 +488,100 @@ func (m *Model) Update(ctx context.Context, data interface{}) (int64, error) {
 		return 0, err
 	}
 
+	// Only write changed columns of tracked rows, skip the update when nothing changed
+	if changes, tracked := m.Dirty(data); tracked {
+		if len(changes) == 0 {
+			return 0, nil
+		}
+		values = changes
+	}
+	m.touch(data, values, m.timestampField(true), time.Now(), false)
+
+	previous, err := m.previousValues(ctx, pkValue)
+	if err != nil {
+		return 0, err
+	}
+
 	// Update in database
-	return m.builder.Table(m.table).
+	affected, err := m.writeQuery().
 		Where(m.pk, "=", pkValue).
 		UpdateWithContext(ctx, values)
+	if err != nil {
+		return 0, err
+	}
+
+	m.remember(v)
+	if Events().watches(m.table) {
+		Events().publishColumns(m.table, pkValue, previous, values)
+	}
+	return affected, nil
 }
 
-// Delete deletes a record by primary key
+// Delete deletes a record by primary key. With soft deletes it sets the
+// soft delete column instead, see ForceDelete.
 func (m *Model) Delete(ctx context.Context, id interface{}) (int64, error) {
-	return m.builder.Table(m.table).
-		Where(m.pk, "=", id).
-		DeleteWithContext(ctx)
+	if m.softDelete != "" {
+		return m.softDeleteRow(ctx, id)
+	}
+	return m.ForceDelete(ctx, id)
+}
+
+// writeQuery returns a fresh builder for the model's table so writes don't
+// pick up conditions accumulated on the shared read builder
+func (m *Model) writeQuery() *Builder {
+	return m.builder.newQuery().Table(m.from())
+}
+
+// get runs the model's BeforeSelect hook, applies the soft delete scope
+// and executes the query. The hook gets a copy of q, so its conditions
+// aren't added to the shared builder again on every read.
+func (m *Model) get(ctx context.Context, q *Builder) (*sql.Rows, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if hook, ok := m.hookTarget().(BeforeSelectHook); ok {
+		q = q.Clone()
+		hook.BeforeSelect(q)
+	}
+	q, err := m.scoped(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return q.Get(ctx)
+}
+
+// structType returns the struct type behind the model value
+func (m *Model) structType() reflect.Type {
+	t := reflect.TypeOf(m.value)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// hookTarget returns a pointer to the model struct so hooks declared
+// on either value or pointer receivers are found
+func (m *Model) hookTarget() interface{} {
+	return reflect.New(m.structType()).Interface()
+}
+
+// afterFind runs the AfterFind hook on a scanned instance
+func (m *Model) afterFind(ctx context.Context, instance interface{}) error {
+	v := reflect.ValueOf(instance)
+	for v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	hook, ok := v.Interface().(AfterFindHook)
+	if !ok {
+		return nil
+	}
+
+	if err := hook.AfterFind(ctx); err != nil {
+		pk := extractFieldValue(v.Interface(), getPkFieldName(m.fields, m.pk))
+		return fmt.Errorf("AfterFind failed for %s %s=%v: %w", m.table, m.pk, pk, err)
+	}
+	return nil
 }
 
 // extractValues extracts field values from a struct into a map
@@ -490,7 +650,12 @@ func (m *Model) scanRow(rows *sql.Rows, v reflect.Value) error {
 
 	// Create a map of column names to field indices
 	colToField := make(map[string]int)
+	extras := reflect.Value{}
 	for i, f := range m.fields {
+		if f.isExtras {
+			extras = v.FieldByName(f.name)
+			continue
+		}
 		colToField[f.column] = i
 	}
 
@@ -524,6 +689,9 @@ func (m *Model) scanRow(rows *sql.Rows, v reflect.Value) error {
 	for i, col := range columns {
 		fieldIdx, ok := colToField[col]
 		if !ok {
+			if extras.IsValid() {
+				setExtra(extras, col, *values[i].(*interface{}))
+			}
 			continue
 		}
 
@@ -543,9 +711,32 @@ func (m *Model) scanRow(rows *sql.Rows, v reflect.Value) error {
 		fieldVal.Set(scanVal)
 	}
 
+	m.remember(v)
 	return nil
 }
 
+// setExtra stores an unmapped column in the model's extras map
+func setExtra(extras reflect.Value, column string, value interface{}) {
+	if extras.Kind() != reflect.Map || !extras.CanSet() {
+		return
+	}
+	if extras.IsNil() {
+		extras.Set(reflect.MakeMap(extras.Type()))
+	}
+	if raw, ok := value.([]byte); ok {
+		value = string(raw)
+	}
+
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		val = reflect.Zero(extras.Type().Elem())
+	}
+	if !val.Type().AssignableTo(extras.Type().Elem()) {
+		return
+	}
+	extras.SetMapIndex(reflect.ValueOf(column), val)
+}
+
 // Helper functions
 
 // toSnakeCase converts a CamelCase string to snake_case
@@ -579,7 +770,7 @@ func (m *Model) SetPrimaryKey(pk string) *Model {
 
 // Query returns the underlying query builder
 func (m *Model) Query() *Builder {
-	return m.builder.Table(m.table)
+	return m.builder.Table(m.from())
 }
 
 // First retrieves the first record matching the current query
@@ -587,9 +778,8 @@ func (m *Model) First(ctx context.Context) (interface{}, error) {
 	result := reflect.New(reflect.TypeOf(m.value)).Interface()
 
 	// Build query
-	rows, err := m.builder.Table(m.table).
-		Limit(1).
-		Get(ctx)
+	rows, err := m.get(ctx, m.builder.Table(m.from()).
+		Limit(1))
 
 	if err != nil {
 		return nil, err
@@ -606,13 +796,13 @@ func (m *Model) First(ctx context.Context) (interface{}, error) {
 		return nil, err
 	}
 
+	if err := m.afterFind(ctx, result); err != nil {
+		return nil, err
+	}
+
 	// Load eager relations if any
-	if len(m.eagerLoad) > 0 {
-		for relation, customQuery := range m.eagerLoad {
-			if err := m.loadRelation(ctx, result, relation, customQuery); err != nil {
-				return nil, fmt.Errorf("error loading relation '%s': %w", relation, err)
-			}
-		}
+	if err := m.loadEagerRelations(ctx, result, m.eagerLoad); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -745,8 +935,12 @@ func (m *Model) getKeyValue(model interface{}, columnName string) (interface{},
 }
 
 // Paginate retrieves records with pagination
-func (m *Model) Paginate(ctx context.Context, page, perPage int) (*Paginator, error) {
-	return m.builder.Table(m.table).Paginate(page, perPage)
+func (m *Model) Paginate(ctx context.Context, page, perPage int, opts ...PaginateOption) (*Paginator, error) {
+	q, err := m.scoped(ctx, m.builder.Table(m.from()))
+	if err != nil {
+		return nil, err
+	}
+	return q.Paginate(page, perPage, opts...)
 }
 
 // WithContext returns a clone of the model with the specified context
@@ -777,9 +971,20 @@ func (m *Model) Preload(ctx context.Context, result interface{}, relation string
 	return m.PreloadWithQuery(ctx, result, relation, nil)
 }
 
+// Load loads the given relations onto already fetched results, a single
+// instance or a slice, without querying the parent rows again. Nested
+// paths such as "Comments.User" are supported.
+func (m *Model) Load(ctx context.Context, result interface{}, relations ...string) error {
+	eager := make(map[string]func(*Builder) *Builder, len(relations))
+	for _, relation := range relations {
+		eager[relation] = nil
+	}
+	return m.loadEagerRelations(ctx, result, eager)
+}
+
 // PreloadWithQuery loads a relation with a custom query
 func (m *Model) PreloadWithQuery(ctx context.Context, result interface{}, relation string, customQuery func(*Builder) *Builder) error {
-	return m.loadRelation(ctx, result, relation, customQuery)
+	return m.loadEagerRelations(ctx, result, map[string]func(*Builder) *Builder{relation: customQuery})
 }
 
 // WithTransaction returns a clone of the model with the transaction
@@ -805,6 +1010,12 @@ func (m *Model) Transaction(ctx context.Context, fn func(*Model) error) error {
 			return fmt.Errorf("failed to create savepoint: %w", err)
 		}
 
+		// Hooks registered in the savepoint wait for the outermost transaction
+		hooks := m.builder.txHooks
+		if hooks != nil {
+			hooks.savepoint()
+		}
+
 		// Execute the function
 		err = fn(m)
 
@@ -812,11 +1023,21 @@ func (m *Model) Transaction(ctx context.Context, fn func(*Model) error) error {
 			// Rollback to savepoint on error
 			_, rbErr := tx.ExecContext(ctx, fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", savepointID))
 			if rbErr != nil {
+				if hooks != nil {
+					hooks.releaseSavepoint()
+				}
 				return fmt.Errorf("failed to rollback to savepoint: %v (original error: %w)", rbErr, err)
 			}
+			if hooks != nil {
+				hooks.rollbackSavepoint(ctx)
+			}
 			return err
 		}
 
+		if hooks != nil {
+			hooks.releaseSavepoint()
+		}
+
 		// Release savepoint on success
 		_, err = tx.ExecContext(ctx, fmt.Sprintf("RELEASE SAVEPOINT %s", savepointID))
 		if err != nil {
@@ -878,7 +1099,7 @@ func (m *Model) parseRelationTag(tag string, field reflect.StructField) (*relati
 	switch rel.relType {
 	case relationHasOne, relationHasMany:
 		rel.localKey = "id"
-		rel.foreignKey = toSnakeCase(reflect.TypeOf(m.value).Elem().Name()) + "_id"
+		rel.foreignKey = toSnakeCase(m.structType().Name()) + "_id"
 	case relationBelongsTo:
 		rel.localKey = toSnakeCase(field.Name) + "_id"
 		rel.foreignKey = "id"
@@ -921,6 +1142,8 @@ func (m *Model) parseRelationTag(tag string, field reflect.StructField) (*relati
 			rel.pivotRfk = value
 		case "table":
 			rel.targetTable = value
+		case "schema":
+			rel.schema = value
 		}
 	}
 
@@ -957,6 +1180,306 @@ func (m *Model) WithQuery(relation string, query func(*Builder) *Builder) *Model
 	return &clone
 }
 
+// WithSum selects the sum of column over a relation as <relation>_sum_<column>
+func (m *Model) WithSum(relation, column string) *Model {
+	return m.withAggregate(relation, "SUM", column)
+}
+
+// WithAvg selects the average of column over a relation as <relation>_avg_<column>
+func (m *Model) WithAvg(relation, column string) *Model {
+	return m.withAggregate(relation, "AVG", column)
+}
+
+// WithMax selects the maximum of column over a relation as <relation>_max_<column>
+func (m *Model) WithMax(relation, column string) *Model {
+	return m.withAggregate(relation, "MAX", column)
+}
+
+// WithMin selects the minimum of column over a relation as <relation>_min_<column>
+func (m *Model) WithMin(relation, column string) *Model {
+	return m.withAggregate(relation, "MIN", column)
+}
+
+// withAggregate returns a copy of the model selecting a correlated subquery
+// column aggregating a relation. NULL results (no related rows) are
+// reported as 0.
+func (m *Model) withAggregate(relationName, function, column string) *Model {
+	clone := *m
+	clone.builder = m.builder.Clone()
+
+	var rel *relation
+	var fieldName string
+	for _, f := range m.fields {
+		if strings.EqualFold(f.name, relationName) && f.relation != nil {
+			rel = f.relation
+			fieldName = f.name
+			break
+		}
+	}
+
+	if rel == nil {
+		clone.err = fmt.Errorf("relation '%s' not found", relationName)
+		return &clone
+	}
+
+	q := m.builder.qualified
+	target := m.relationTable(rel)
+	expr := q(column)
+	var from string
+	switch rel.relType {
+	case relationManyToMany:
+		// Qualify the column since the pivot table is joined in
+		expr = q(target, column)
+		from = fmt.Sprintf("%s JOIN %s ON %s = %s WHERE %s = %s",
+			q(target), q(rel.pivot), q(target, rel.foreignKey), q(rel.pivot, rel.pivotRfk),
+			q(rel.pivot, rel.pivotFk), q(m.table, rel.localKey))
+	default:
+		from = fmt.Sprintf("%s WHERE %s = %s", q(target), q(target, rel.foreignKey), q(m.table, rel.localKey))
+	}
+
+	alias := toSnakeCase(fieldName) + "_" + strings.ToLower(function) + "_" + column
+	subQuery := fmt.Sprintf("(SELECT COALESCE(%s(%s),0) FROM %s) AS %s", function, expr, from, q(alias))
+
+	if len(clone.builder.columns) == 0 {
+		clone.builder.Select(q(m.table) + ".*")
+	}
+	clone.builder.Select(subQuery)
+	return &clone
+}
+
+// Has returns a copy of the model keeping the rows whose number of related
+// rows compares to count with operator, for example Has("Posts", ">", 3)
+func (m *Model) Has(relation string, operator string, count int) *Model {
+	return m.WhereHas(relation, nil, operator, count)
+}
+
+// WhereHas is Has counting only the related rows matching query. Like
+// Has it returns a copy of the model, the model itself is left unchanged.
+func (m *Model) WhereHas(relationName string, query func(*Builder) *Builder, operator string, count int) *Model {
+	clone := *m
+	clone.builder = m.builder.Clone()
+
+	field := m.relationField(relationName)
+	if field == nil {
+		clone.err = fmt.Errorf("relation '%s' not found", relationName)
+		return &clone
+	}
+	rel := field.relation
+
+	target := m.relationTable(rel)
+	sub := m.builder.newQuery().Table(target).Select("COUNT(*)")
+	switch rel.relType {
+	case relationManyToMany:
+		sub.Join(rel.pivot, fmt.Sprintf("%s.%s = %s.%s", target, rel.foreignKey, rel.pivot, rel.pivotRfk)).
+			WhereColumn(rel.pivot+"."+rel.pivotFk, "=", m.table+"."+rel.localKey)
+	default:
+		sub.WhereColumn(target+"."+rel.foreignKey, "=", m.table+"."+rel.localKey)
+	}
+	if query != nil {
+		sub = query(sub)
+	}
+
+	clone.builder.WhereRaw("("+sub.toSQL()+") "+operator+" ?", append(sub.GetBindings(), count)...)
+	return &clone
+}
+
+// WithLatest eager loads only the most recent row of a hasOne or hasMany
+// relation per parent, ordered by column. A hasMany field receives a
+// single element slice.
+func (m *Model) WithLatest(relation, column string) *Model {
+	return m.withEdge(relation, column, "DESC")
+}
+
+// WithOldest eager loads only the oldest row of a hasOne or hasMany
+// relation per parent, ordered by column
+func (m *Model) WithOldest(relation, column string) *Model {
+	return m.withEdge(relation, column, "ASC")
+}
+
+// withEdge eager loads the first row per parent in the given order using a
+// correlated subquery, ties are broken by the related primary key
+func (m *Model) withEdge(relationName, column, direction string) *Model {
+	var rel *relation
+	var fieldName string
+	for _, f := range m.fields {
+		if strings.EqualFold(f.name, relationName) && f.relation != nil {
+			rel = f.relation
+			fieldName = f.name
+			break
+		}
+	}
+
+	if rel == nil {
+		return m.withErr(fmt.Errorf("relation '%s' not found", relationName))
+	}
+	if rel.relType != relationHasOne && rel.relType != relationHasMany {
+		return m.withErr(fmt.Errorf("relation '%s' must be hasOne or hasMany", relationName))
+	}
+
+	relatedModel, err := m.relatedModel(rel)
+	if err != nil {
+		return m.withErr(err)
+	}
+	pk := relatedModel.pk
+
+	return m.WithQuery(fieldName, func(q *Builder) *Builder {
+		r := q.qualified
+		return q.WhereRaw(fmt.Sprintf(
+			"%s = (SELECT %s FROM %s AS %s WHERE %s = %s ORDER BY %s %s, %s %s LIMIT 1)",
+			r(q.table, pk), r("edge", pk), r(q.table), r("edge"), r("edge", rel.foreignKey), r(q.table, rel.foreignKey),
+			r("edge", column), direction, r("edge", pk), direction))
+	})
+}
+
+// withErr returns a copy of the model failing with err, so a bad relation
+// name doesn't break the model itself
+func (m *Model) withErr(err error) *Model {
+	clone := *m
+	clone.err = err
+	return &clone
+}
+
+// relatedModel returns the registered model of a relation target, creating it when needed
+func (m *Model) relatedModel(rel *relation) (*Model, error) {
+	if m.relManager == nil {
+		return nil, errors.New("relation manager not initialized")
+	}
+
+	if relatedModel, exists := m.relManager.registry[rel.modelType]; exists {
+		return relatedModel, nil
+	}
+
+	// Try to create a new model instance
+	dummy := reflect.New(rel.modelType).Interface()
+	relatedModel, err := NewModel(m.relManager.db, dummy, WithDialect(m.builder.dialect))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create related model: %w", err)
+	}
+	return relatedModel, nil
+}
+
+// relationField returns the relation field matching name case-insensitively
+func (m *Model) relationField(name string) *Field {
+	for i := range m.fields {
+		if m.fields[i].relation != nil && strings.EqualFold(m.fields[i].name, name) {
+			return &m.fields[i]
+		}
+	}
+	return nil
+}
+
+// loadEagerRelations loads relations into results, a pointer to a model or
+// a slice of models. Nested paths such as "Comments.User" load each level
+// once and pass the loaded children on to the next level; a custom query
+// applies to the last relation of its path.
+func (m *Model) loadEagerRelations(ctx context.Context, results interface{}, eager map[string]func(*Builder) *Builder) error {
+	type level struct {
+		name   string
+		query  func(*Builder) *Builder
+		nested map[string]func(*Builder) *Builder
+	}
+
+	levels := make(map[string]*level)
+	var keys []string
+	for path, customQuery := range eager {
+		name, rest, isNested := strings.Cut(path, ".")
+		key := strings.ToLower(name)
+		l, ok := levels[key]
+		if !ok {
+			if f := m.relationField(name); f != nil {
+				name = f.name
+			}
+			l = &level{name: name, nested: make(map[string]func(*Builder) *Builder)}
+			levels[key] = l
+			keys = append(keys, key)
+		}
+		if isNested {
+			l.nested[rest] = customQuery
+		} else {
+			l.query = customQuery
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		l := levels[key]
+		if err := m.loadRelation(ctx, results, l.name, l.query); err != nil {
+			return fmt.Errorf("error loading relation '%s': %w", l.name, err)
+		}
+		if len(l.nested) == 0 {
+			continue
+		}
+
+		relatedModel, children, err := m.relatedChildren(results, l.name)
+		if err != nil {
+			return err
+		}
+		if children.Elem().Len() == 0 {
+			continue
+		}
+		if err := relatedModel.loadEagerRelations(ctx, children.Interface(), l.nested); err != nil {
+			return fmt.Errorf("error loading relation '%s': %w", l.name, err)
+		}
+	}
+	return nil
+}
+
+// relatedChildren collects pointers to the loaded values of a relation
+// across results, returned as a pointer to a slice of pointers
+func (m *Model) relatedChildren(results interface{}, name string) (*Model, reflect.Value, error) {
+	field := m.relationField(name)
+	if field == nil {
+		return nil, reflect.Value{}, fmt.Errorf("relation '%s' not found", name)
+	}
+	relatedModel, err := m.relatedModel(field.relation)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+
+	children := reflect.New(reflect.SliceOf(reflect.PointerTo(field.relation.modelType)))
+	add := func(v reflect.Value) {
+		children.Elem().Set(reflect.Append(children.Elem(), v))
+	}
+
+	var parents []reflect.Value
+	v := reflect.Indirect(reflect.ValueOf(results))
+	switch v.Kind() {
+	case reflect.Struct:
+		parents = append(parents, v)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if item := reflect.Indirect(v.Index(i)); item.Kind() == reflect.Struct {
+				parents = append(parents, item)
+			}
+		}
+	}
+
+	for _, parent := range parents {
+		value := parent.FieldByName(field.name)
+		switch value.Kind() {
+		case reflect.Struct:
+			if !value.IsZero() && value.CanAddr() {
+				add(value.Addr())
+			}
+		case reflect.Ptr:
+			if !value.IsNil() {
+				add(value)
+			}
+		case reflect.Slice:
+			for i := 0; i < value.Len(); i++ {
+				item := value.Index(i)
+				if item.Kind() != reflect.Ptr {
+					item = item.Addr()
+				}
+				if !item.IsNil() {
+					add(item)
+				}
+			}
+		}
+	}
+	return relatedModel, children, nil
+}
+
 // loadRelation loads related models for a specific relation
 func (m *Model) loadRelation(ctx context.Context, results interface{}, relationName string, customQuery func(*Builder) *Builder) error {
 	// Get the field for the relation
@@ -974,33 +1497,20 @@ func (m *Model) loadRelation(ctx context.Context, results interface{}, relationN
 
 	// Get relation info
 	rel := relationField.relation
-	targetTable := rel.targetTable
+	targetTable := m.relationTable(rel)
 
 	// Find related model
-	var relatedModel *Model
-	var exists bool
-
-	// Try to get related model from registry
-	if m.relManager != nil {
-		relatedModel, exists = m.relManager.registry[rel.modelType]
-		if !exists {
-			// Try to create a new model instance
-			dummy := reflect.New(rel.modelType).Interface()
-			var err error
-			relatedModel, err = NewModel(m.relManager.db, dummy)
-			if err != nil {
-				return fmt.Errorf("failed to create related model: %w", err)
-			}
-		}
-	} else {
-		return errors.New("relation manager not initialized")
+	relatedModel, err := m.relatedModel(rel)
+	if err != nil {
+		return err
 	}
 
 	// Set flag to indicate this model is being used for preloading
 	relatedModel.isPreload = true
 
-	// Create query builder for the related model
-	query := relatedModel.Query()
+	// Create a fresh query builder for the related model so conditions
+	// don't pile up on its shared builder across loads
+	query := relatedModel.builder.newQuery().Table(m.relationTable(rel))
 
 	// Apply custom query constraints if provided
 	if customQuery != nil {
@@ -1090,7 +1600,7 @@ func (m *Model) loadRelation(ctx context.Context, results interface{}, relationN
 	}
 
 	// Execute query to get related models
-	relatedRows, err := query.Get(ctx)
+	relatedRows, err := relatedModel.get(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -1118,6 +1628,10 @@ func (m *Model) loadRelation(ctx context.Context, results interface{}, relationN
 				return fmt.Errorf("failed to scan related row: %w", err)
 			}
 
+			if err := relatedModel.afterFind(ctx, relatedInstance); err != nil {
+				return err
+			}
+
 			// Extract the key value to map this related instance
 			var keyValue interface{}
 
@@ -1202,6 +1716,10 @@ func (m *Model) loadRelation(ctx context.Context, results interface{}, relationN
 					return fmt.Errorf("failed to scan related row: %w", err)
 				}
 
+				if err := relatedModel.afterFind(ctx, relatedInstance); err != nil {
+					return err
+				}
+
 				// For many-to-many, the parent key comes from the pivot table
 				var pivotParentKey interface{}
 				var values = make([]interface{}, len(columns))
@@ -1228,6 +1746,10 @@ func (m *Model) loadRelation(ctx context.Context, results interface{}, relationN
 					return fmt.Errorf("failed to scan related row: %w", err)
 				}
 
+				if err := relatedModel.afterFind(ctx, relatedInstance); err != nil {
+					return err
+				}
+
 				// Get the foreign key value that references the parent
 				parentKey := extractFieldValue(relatedInstance, foreignKeyField)
 
@@ -1321,6 +1843,125 @@ func extractFieldValue(model interface{}, columnName string) interface{} {
 	return nil
 }
 
+// AttachRelation joins two already loaded collections in memory. For every
+// parent, the children whose childKey matches the parent's parentKey are
+// assigned to the parent's field: the first match for struct fields, all
+// matches for slice fields. Keys are resolved by field name or db tag.
+// parents must be a slice (or pointer to slice) of structs or struct pointers.
+func AttachRelation(parents, children interface{}, parentKey, childKey, field string) error {
+	parentVals, err := structElems(parents)
+	if err != nil {
+		return fmt.Errorf("parents: %w", err)
+	}
+	childVals, err := structElems(children)
+	if err != nil {
+		return fmt.Errorf("children: %w", err)
+	}
+
+	// Group children by key
+	groups := make(map[interface{}][]reflect.Value)
+	for _, child := range childVals {
+		key := normalizeKey(extractFieldValue(child.Addr().Interface(), childKey))
+		if key == nil {
+			continue
+		}
+		groups[key] = append(groups[key], child)
+	}
+
+	for _, parent := range parentVals {
+		relField := parent.FieldByName(field)
+		if !relField.IsValid() || !relField.CanSet() {
+			return fmt.Errorf("field %s not found or not settable", field)
+		}
+
+		key := normalizeKey(extractFieldValue(parent.Addr().Interface(), parentKey))
+		matches := groups[key]
+		if key == nil || len(matches) == 0 {
+			continue
+		}
+
+		if relField.Kind() == reflect.Slice {
+			newSlice := reflect.MakeSlice(relField.Type(), 0, len(matches))
+			for _, match := range matches {
+				newSlice = reflect.Append(newSlice, assignableValue(match, relField.Type().Elem()))
+			}
+			relField.Set(newSlice)
+			continue
+		}
+
+		relField.Set(assignableValue(matches[0], relField.Type()))
+	}
+
+	return nil
+}
+
+// structElems returns the addressable struct values held by a slice
+func structElems(collection interface{}) ([]reflect.Value, error) {
+	v := reflect.ValueOf(collection)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a slice, got %s", v.Kind())
+	}
+
+	elems := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		if item.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("expected struct elements, got %s", item.Kind())
+		}
+		if !item.CanAddr() {
+			return nil, errors.New("slice elements are not addressable")
+		}
+		elems = append(elems, item)
+	}
+
+	return elems, nil
+}
+
+// assignableValue converts a struct value to the pointer or value form of typ
+func assignableValue(v reflect.Value, typ reflect.Type) reflect.Value {
+	if typ.Kind() == reflect.Ptr {
+		return v.Addr()
+	}
+	return v
+}
+
+// normalizeKey makes keys of different integer widths and byte slices comparable
+func normalizeKey(key interface{}) interface{} {
+	if key == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(key)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Slice:
+		if b, ok := key.([]byte); ok {
+			return string(b)
+		}
+		return nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return normalizeKey(v.Elem().Interface())
+	}
+
+	return key
+}
+
 // getFieldNameByColumn gets the field name corresponding to a given column name
 func getFieldNameByColumn(fields []Field, colName string) string {
 	for _, field := range fields {
@@ -1348,7 +1989,7 @@ func getFieldNameByColumn(fields []Field, colName string) string {
 // Count returns the count of records
 func (m *Model) Count(ctx context.Context) (int64, error) {
 	var count int64
-	rows, err := m.builder.Table(m.table).Count("*").Get(ctx)
+	rows, err := m.get(ctx, m.builder.Table(m.from()).Count("*"))
 	if err != nil {
 		return 0, err
 	}
@@ -1360,3 +2001,109 @@ func (m *Model) Count(ctx context.Context) (int64, error) {
 
 	return count, err
 }
+
+// inModel records where a WhereInModel subquery sits in the builder
+type inModel struct {
+	where   int // index in wheres
+	binding int // index of the first subquery binding in bindings
+	query   string
+	args    []interface{}
+}
+
+// WhereInModel adds a "column IN (SELECT key FROM model ...)" condition using
+// the related model's table, primary key (when key is empty) and BeforeSelect hook.
+// The optional constrain callback filters the related rows.
+func (b *Builder) WhereInModel(column string, model *Model, constrain func(*Builder) *Builder, key string) *Builder {
+	if key == "" {
+		key = model.pk
+	}
+
+	sub := b.newQuery().Table(model.from()).Select(key)
+	if hook, ok := model.hookTarget().(BeforeSelectHook); ok {
+		hook.BeforeSelect(sub)
+	}
+	if constrain != nil {
+		sub = constrain(sub)
+	}
+
+	query := sub.toSQL()
+	args := sub.GetBindings()
+
+	b.inModels = append(b.inModels, inModel{
+		where:   len(b.wheres),
+		binding: len(b.bindings),
+		query:   query,
+		args:    args,
+	})
+	b.wheres = append(b.wheres, where{
+		column:   column,
+		operator: "IN",
+		value:    query,
+		boolean:  "AND",
+	})
+	b.bindings = append(b.bindings, args...)
+	return b
+}
+
+// WithMaterialized makes WhereInModel conditions run their subquery separately
+// when the query executes, inlining the returned keys as a plain IN list.
+// Useful on databases that optimize IN-subqueries poorly.
+func (b *Builder) WithMaterialized() *Builder {
+	b.materializeIn = true
+	return b
+}
+
+// materializeInModels replaces WhereInModel subqueries with their results
+func (b *Builder) materializeInModels(ctx context.Context) error {
+	if !b.materializeIn || len(b.inModels) == 0 {
+		return nil
+	}
+
+	shift := 0
+	for _, in := range b.inModels {
+		values, err := b.pluck(ctx, in.query, in.args)
+		if err != nil {
+			return fmt.Errorf("failed to materialize subquery for %s: %w", b.wheres[in.where].column, err)
+		}
+
+		w := &b.wheres[in.where]
+		switch {
+		case len(values) > 0:
+			w.value = strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		case b.emptyInNoop:
+			*w = where{column: "1 = 1", value: "", boolean: w.boolean}
+		default:
+			*w = where{column: "1 = 0", value: "", boolean: w.boolean}
+		}
+
+		pos := in.binding + shift
+		rest := append([]interface{}{}, b.bindings[pos+len(in.args):]...)
+		b.bindings = append(append(b.bindings[:pos], values...), rest...)
+		shift += len(values) - len(in.args)
+	}
+
+	b.inModels = nil
+	return nil
+}
+
+// pluck runs a single column query and returns its values
+func (b *Builder) pluck(ctx context.Context, query string, args []interface{}) ([]interface{}, error) {
+	rows, err := b.queryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []interface{}
+	for rows.Next() {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		if raw, ok := value.([]byte); ok {
+			value = string(raw)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
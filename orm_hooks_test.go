@@ -0,0 +1,164 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var finderMemberHooks int64
+
+// FinderTeam is a model with read hooks
+type FinderTeam struct {
+	ID      int            `db:"id,pk,auto"`
+	Name    string         `db:"name"`
+	Members []FinderMember `rel:"hasMany,foreignKey:team_id"`
+}
+
+// BeforeSelect forces a soft delete scope on every read
+func (FinderTeam) BeforeSelect(q *Builder) {
+	q.WhereNull("deleted_at")
+}
+
+// FinderMember derives its display name after being loaded
+type FinderMember struct {
+	ID          int    `db:"id,pk,auto"`
+	TeamID      int    `db:"team_id"`
+	Name        string `db:"name"`
+	DisplayName string `db:"-"`
+}
+
+func (f *FinderMember) AfterFind(ctx context.Context) error {
+	atomic.AddInt64(&finderMemberHooks, 1)
+	if f.Name == "bad" {
+		return errors.New("cannot decrypt name")
+	}
+	f.DisplayName = strings.ToUpper(f.Name)
+	return nil
+}
+
+func newFinderMock() *MockSQL {
+	return NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		switch {
+		case strings.Contains(query, "FROM finder_team"):
+			return &MockResultSet{
+				Columns: []string{"id", "name"},
+				Rows:    [][]interface{}{{int64(1), "red"}, {int64(2), "blue"}},
+			}, nil
+		case strings.Contains(query, "FROM finder_member"):
+			return &MockResultSet{
+				Columns: []string{"id", "team_id", "name"},
+				Rows: [][]interface{}{
+					{int64(10), int64(1), "ann"},
+					{int64(11), int64(1), "ben"},
+					{int64(12), int64(2), "cid"},
+				},
+			}, nil
+		}
+		return nil, errors.New("unexpected query: " + query)
+	})
+}
+
+// Test AfterFind invocation across All and an eager-loaded hasMany
+func TestModelAfterFindHook(t *testing.T) {
+	ctx := context.Background()
+	mock := newFinderMock()
+	defer mock.DB.Close()
+
+	memberModel, err := NewModel(mock.DB, FinderMember{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	atomic.StoreInt64(&finderMemberHooks, 0)
+	result, err := memberModel.All(ctx)
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+
+	members := result.([]FinderMember)
+	if hooks := atomic.LoadInt64(&finderMemberHooks); hooks != 3 {
+		t.Errorf("Expected 3 AfterFind calls, got %d", hooks)
+	}
+	if members[0].DisplayName != "ANN" {
+		t.Errorf("Expected display name ANN, got %q", members[0].DisplayName)
+	}
+
+	teamModel, err := NewModel(mock.DB, FinderTeam{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	atomic.StoreInt64(&finderMemberHooks, 0)
+	result, err = teamModel.With("Members").Where(ctx, "id", ">", 0)
+	if err != nil {
+		t.Fatalf("Where failed: %v", err)
+	}
+
+	teams := result.([]FinderTeam)
+	if hooks := atomic.LoadInt64(&finderMemberHooks); hooks != 3 {
+		t.Errorf("Expected 3 AfterFind calls for eager-loaded members, got %d", hooks)
+	}
+	if len(teams[0].Members) != 2 || teams[0].Members[1].DisplayName != "BEN" {
+		t.Errorf("Expected eager-loaded members with display names, got %+v", teams[0].Members)
+	}
+}
+
+// Test AfterFind errors abort with the failing primary key
+func TestModelAfterFindHookError(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"id", "team_id", "name"},
+		[]interface{}{int64(1), int64(1), "ok"},
+		[]interface{}{int64(2), int64(1), "bad"},
+	)
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, FinderMember{})
+	_, err := model.All(ctx)
+	if err == nil {
+		t.Fatal("Expected AfterFind error")
+	}
+	if !strings.Contains(err.Error(), "id=2") || !strings.Contains(err.Error(), "cannot decrypt name") {
+		t.Errorf("Expected error to name the failing record, got %v", err)
+	}
+}
+
+// Test BeforeSelect adjusts read queries
+func TestModelBeforeSelectHook(t *testing.T) {
+	ctx := context.Background()
+	mock := newFinderMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, FinderTeam{})
+	if _, err := model.Find(ctx, 1); err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	expected := "SELECT * FROM finder_team WHERE id = ? AND deleted_at IS NULL LIMIT ?"
+	if len(calls) != 1 || calls[0].Query != expected {
+		t.Errorf("Expected query %q, got %+v", expected, calls)
+	}
+}
+
+// Test BeforeSelect conditions don't pile up across reads
+func TestModelBeforeSelectHookRepeatedReads(t *testing.T) {
+	ctx := context.Background()
+	mock := newFinderMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, FinderTeam{})
+	for i := 0; i < 2; i++ {
+		if _, err := model.All(ctx); err != nil {
+			t.Fatalf("All failed: %v", err)
+		}
+	}
+
+	calls := mock.Calls()
+	expected := "SELECT * FROM finder_team WHERE deleted_at IS NULL"
+	if len(calls) != 2 || calls[0].Query != expected || calls[1].Query != calls[0].Query {
+		t.Errorf("Expected %q twice, got %+v", expected, calls)
+	}
+}
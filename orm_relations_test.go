@@ -3,6 +3,7 @@ package qix
 import (
 	"context"
 	"database/sql"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -268,3 +269,381 @@ func findRelationField(fields []Field, name string) *Field {
 	}
 	return nil
 }
+
+// Test in-memory relation hydration between preloaded collections
+func TestAttachRelation(t *testing.T) {
+	users := []Gamer{
+		{ID: 1, Name: "Alice"},
+		{ID: 2, Name: "Bob"},
+	}
+	posts := []Post{
+		{ID: 10, UserID: 2, Title: "Hello"},
+		{ID: 11, UserID: 1, Title: "World"},
+		{ID: 12, UserID: 2, Title: "Again"},
+		{ID: 13, UserID: 3, Title: "Orphan"},
+	}
+
+	// belongsTo direction: attach each post's author
+	if err := AttachRelation(posts, users, "user_id", "id", "User"); err != nil {
+		t.Fatalf("AttachRelation failed: %v", err)
+	}
+
+	expectedAuthors := []string{"Bob", "Alice", "Bob", ""}
+	for i, post := range posts {
+		if post.User.Name != expectedAuthors[i] {
+			t.Errorf("Post %d: expected author %q, got %q", post.ID, expectedAuthors[i], post.User.Name)
+		}
+	}
+
+	// hasMany direction: attach posts to users through a pointer to the slice
+	if err := AttachRelation(&users, posts, "id", "user_id", "Posts"); err != nil {
+		t.Fatalf("AttachRelation failed: %v", err)
+	}
+
+	if len(users[0].Posts) != 1 || users[0].Posts[0].ID != 11 {
+		t.Errorf("Expected Alice to have post 11, got %+v", users[0].Posts)
+	}
+	if len(users[1].Posts) != 2 || users[1].Posts[0].ID != 10 || users[1].Posts[1].ID != 12 {
+		t.Errorf("Expected Bob to have posts 10 and 12, got %d posts", len(users[1].Posts))
+	}
+}
+
+// Test hydration into pointer fields from pointer slices
+func TestAttachRelationPointers(t *testing.T) {
+	avatars := []*Avatar{{ID: 1, UserID: 7}}
+	users := []*Gamer{{ID: 7, Name: "Carol"}}
+
+	if err := AttachRelation(avatars, users, "user_id", "id", "User"); err != nil {
+		t.Fatalf("AttachRelation failed: %v", err)
+	}
+
+	if avatars[0].User != users[0] {
+		t.Error("Expected avatar user to point at the loaded user")
+	}
+
+	if err := AttachRelation(avatars, users, "user_id", "id", "Missing"); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+	if err := AttachRelation(Avatar{}, users, "user_id", "id", "User"); err == nil {
+		t.Error("Expected error for non-slice parents")
+	}
+}
+
+// Test correlated aggregate columns over relations
+func TestModelWithAggregates(t *testing.T) {
+	tests := []struct {
+		name     string
+		apply    func(*Model) *Model
+		model    interface{}
+		expected string
+	}{
+		{
+			name:     "WithSum",
+			model:    &Gamer{},
+			apply:    func(m *Model) *Model { return m.WithSum("Posts", "views") },
+			expected: "SELECT gamer.*, (SELECT COALESCE(SUM(views),0) FROM post WHERE post.user_id = gamer.id) AS posts_sum_views FROM gamer",
+		},
+		{
+			name:     "WithAvg",
+			model:    &Gamer{},
+			apply:    func(m *Model) *Model { return m.WithAvg("Posts", "views") },
+			expected: "SELECT gamer.*, (SELECT COALESCE(AVG(views),0) FROM post WHERE post.user_id = gamer.id) AS posts_avg_views FROM gamer",
+		},
+		{
+			name:     "WithMax",
+			model:    &Post{},
+			apply:    func(m *Model) *Model { return m.WithMax("Comments", "id") },
+			expected: "SELECT post.*, (SELECT COALESCE(MAX(id),0) FROM comment WHERE comment.post_id = post.id) AS comments_max_id FROM post",
+		},
+		{
+			name:     "WithMin",
+			model:    &Post{},
+			apply:    func(m *Model) *Model { return m.WithMin("Comments", "id") },
+			expected: "SELECT post.*, (SELECT COALESCE(MIN(id),0) FROM comment WHERE comment.post_id = post.id) AS comments_min_id FROM post",
+		},
+		{
+			name:  "Multiple aggregates over a pivot",
+			model: &Post{},
+			apply: func(m *Model) *Model {
+				return m.WithSum("Comments", "likes").WithMax("Tags", "weight")
+			},
+			expected: "SELECT post.*, (SELECT COALESCE(SUM(likes),0) FROM comment WHERE comment.post_id = post.id) AS comments_sum_likes, " +
+				"(SELECT COALESCE(MAX(tag.weight),0) FROM tag JOIN post_tags ON tag.id = post_tags.tag_id WHERE post_tags.post_id = post.id) AS tags_max_weight FROM post",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := NewModel(&MockDB{}, tt.model)
+			if err != nil {
+				t.Fatalf("Failed to create model: %v", err)
+			}
+
+			sql := tt.apply(model).Query().ToSQL()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL:\n%s\nGot:\n%s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestModelWithAggregateUnknownRelation(t *testing.T) {
+	model, _ := NewModel(&MockDB{}, &Gamer{})
+
+	if _, err := model.WithSum("Invoices", "amount").All(context.Background()); err == nil {
+		t.Error("Expected error for unknown relation")
+	}
+	if model.err != nil {
+		t.Errorf("Expected the model to be left unchanged, got %v", model.err)
+	}
+}
+
+func TestModelWithAggregateLeavesModelUnchanged(t *testing.T) {
+	model, err := NewModel(&MockDB{}, &Gamer{}, WithQuotedIdentifiers(true))
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	expected := "SELECT `gamer`.*, (SELECT COALESCE(SUM(`views`),0) FROM `post` WHERE `post`.`user_id` = `gamer`.`id`) AS `posts_sum_views` FROM `gamer`"
+	if sql := model.WithSum("Posts", "views").Query().ToSQL(); sql != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, sql)
+	}
+	if sql := model.Query().ToSQL(); sql != "SELECT * FROM `gamer`" {
+		t.Errorf("Expected the model's own query unchanged, got %s", sql)
+	}
+}
+
+// Test filtering on the number of related rows
+func TestModelHas(t *testing.T) {
+	gamers, err := NewModel(&MockDB{}, &Gamer{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	builder := gamers.Has("Posts", ">", 3).Query()
+	expected := "SELECT * FROM gamer WHERE (SELECT COUNT(*) FROM post WHERE post.user_id = gamer.id) > ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{3}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	posts, _ := NewModel(&MockDB{}, &Post{})
+	builder = posts.WhereHas("Comments", func(q *Builder) *Builder {
+		return q.Where("approved", "=", true)
+	}, ">=", 2).WhereHas("Tags", nil, "=", 0).Query()
+	expected = "SELECT * FROM post WHERE (SELECT COUNT(*) FROM comment WHERE comment.post_id = post.id AND approved = ?) >= ? " +
+		"AND (SELECT COUNT(*) FROM tag INNER JOIN post_tags ON tag.id = post_tags.tag_id WHERE post_tags.post_id = post.id) = ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{true, 2, 0}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	if _, err := gamers.Has("Invoices", ">", 0).All(context.Background()); err == nil {
+		t.Error("Expected error for unknown relation")
+	}
+
+	// Has and WhereHas return copies, the models keep their own queries
+	if sql := gamers.Query().ToSQL(); sql != "SELECT * FROM gamer" || gamers.err != nil {
+		t.Errorf("Expected the gamers model unchanged, got %s (%v)", sql, gamers.err)
+	}
+	if sql := posts.Query().ToSQL(); sql != "SELECT * FROM post" {
+		t.Errorf("Expected the posts model unchanged, got %s", sql)
+	}
+}
+
+// Test IN-subqueries derived from another model
+func TestWhereInModel(t *testing.T) {
+	users, _ := NewModel(&MockDB{}, &Gamer{})
+
+	builder := New(&MockDB{}).Table("post").
+		Where("published", "=", true).
+		WhereInModel("user_id", users, func(q *Builder) *Builder {
+			return q.Where("team_id", "=", 7)
+		}, "").
+		WhereLike("title", "%go%")
+
+	expected := "SELECT * FROM post WHERE published = ? AND user_id IN (SELECT id FROM gamer WHERE team_id = ?) AND title LIKE ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{true, 7, "%go%"}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+}
+
+func TestWhereInModelMaterialized(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.HasPrefix(query, "SELECT email FROM gamer") {
+			return &MockResultSet{Columns: []string{"email"}, Rows: [][]interface{}{{[]byte("a@x.io")}, {[]byte("b@x.io")}}}, nil
+		}
+		return &MockResultSet{Columns: []string{"id"}}, nil
+	})
+	defer mock.DB.Close()
+
+	users, _ := NewModel(mock.DB, &Gamer{})
+
+	rows, err := New(mock.DB).Table("invites").
+		Where("sent", "=", int64(0)).
+		WhereInModel("email", users, func(q *Builder) *Builder {
+			return q.Where("team_id", "=", int64(7))
+		}, "email").
+		Where("expired", "=", int64(0)).
+		WithMaterialized().
+		Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	rows.Close()
+
+	calls := mock.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 queries, got %d", len(calls))
+	}
+	if calls[0].Query != "SELECT email FROM gamer WHERE team_id = ?" {
+		t.Errorf("Unexpected subquery: %s", calls[0].Query)
+	}
+
+	expected := "SELECT * FROM invites WHERE sent = ? AND email IN (?, ?) AND expired = ?"
+	if calls[1].Query != expected {
+		t.Errorf("Expected SQL:\n%s\nGot:\n%s", expected, calls[1].Query)
+	}
+	if args := calls[1].Args; !reflect.DeepEqual(args, []interface{}{int64(0), "a@x.io", "b@x.io", int64(0)}) {
+		t.Errorf("Unexpected bindings %v", args)
+	}
+}
+
+func TestWhereInModelMaterializedEmpty(t *testing.T) {
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	users, _ := NewModel(mock.DB, &Gamer{})
+
+	rows, err := New(mock.DB).Table("post").
+		WhereInModel("user_id", users, nil, "id").
+		Where("published", "=", true).
+		WithMaterialized().
+		Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	rows.Close()
+
+	calls := mock.Calls()
+	expected := "SELECT * FROM post WHERE 1 = 0 AND published = ?"
+	if len(calls) != 2 || calls[1].Query != expected {
+		t.Fatalf("Expected final query %q, got %+v", expected, calls)
+	}
+	if len(calls[1].Args) != 1 {
+		t.Errorf("Expected 1 binding, got %v", calls[1].Args)
+	}
+}
+
+// EdgeCustomer has both a to-one and a to-many view of its orders
+type EdgeCustomer struct {
+	ID     int         `db:"id,pk,auto"`
+	Name   string      `db:"name"`
+	Latest EdgeOrder   `rel:"hasOne,foreignKey:customer_id"`
+	Orders []EdgeOrder `rel:"hasMany,foreignKey:customer_id"`
+}
+
+// EdgeOrder belongs to an EdgeCustomer
+type EdgeOrder struct {
+	ID         int    `db:"id,pk,auto"`
+	CustomerID int    `db:"customer_id"`
+	Ref        string `db:"ref"`
+}
+
+// Test loading a single latest/oldest row per parent
+func TestModelWithLatest(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.Contains(query, "FROM edge_order") {
+			return &MockResultSet{
+				Columns: []string{"id", "customer_id", "ref"},
+				Rows:    [][]interface{}{{int64(12), int64(1), "A-2"}, {int64(21), int64(2), "B-1"}},
+			}, nil
+		}
+		return &MockResultSet{
+			Columns: []string{"id", "name"},
+			Rows:    [][]interface{}{{int64(1), "ann"}, {int64(2), "ben"}},
+		}, nil
+	})
+	defer mock.DB.Close()
+
+	if _, err := NewModel(mock.DB, EdgeOrder{}); err != nil {
+		t.Fatalf("Failed to create order model: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		apply    func(*Model) *Model
+		expected string
+		check    func(t *testing.T, customers []EdgeCustomer)
+	}{
+		{
+			name:  "WithLatest hasOne",
+			apply: func(m *Model) *Model { return m.WithLatest("Latest", "created_at") },
+			expected: "SELECT * FROM edge_order WHERE edge_order.id = (SELECT edge.id FROM edge_order AS edge " +
+				"WHERE edge.customer_id = edge_order.customer_id ORDER BY edge.created_at DESC, edge.id DESC LIMIT 1) AND customer_id IN (?, ?)",
+			check: func(t *testing.T, customers []EdgeCustomer) {
+				if customers[0].Latest.Ref != "A-2" || customers[1].Latest.Ref != "B-1" {
+					t.Errorf("Unexpected latest orders: %+v", customers)
+				}
+			},
+		},
+		{
+			name:  "WithOldest hasMany",
+			apply: func(m *Model) *Model { return m.WithOldest("orders", "created_at") },
+			expected: "SELECT * FROM edge_order WHERE edge_order.id = (SELECT edge.id FROM edge_order AS edge " +
+				"WHERE edge.customer_id = edge_order.customer_id ORDER BY edge.created_at ASC, edge.id ASC LIMIT 1) AND customer_id IN (?, ?)",
+			check: func(t *testing.T, customers []EdgeCustomer) {
+				if len(customers[0].Orders) != 1 || customers[0].Orders[0].Ref != "A-2" || len(customers[1].Orders) != 1 {
+					t.Errorf("Expected one order per customer, got %+v", customers)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := NewModel(mock.DB, EdgeCustomer{})
+			if err != nil {
+				t.Fatalf("Failed to create model: %v", err)
+			}
+
+			before := len(mock.Calls())
+			result, err := tt.apply(model).Where(ctx, "id", ">", 0)
+			if err != nil {
+				t.Fatalf("Where failed: %v", err)
+			}
+
+			calls := mock.Calls()[before:]
+			if len(calls) != 2 {
+				t.Fatalf("Expected 2 queries, got %d", len(calls))
+			}
+			if calls[1].Query != tt.expected {
+				t.Errorf("Expected SQL:\n%s\nGot:\n%s", tt.expected, calls[1].Query)
+			}
+			tt.check(t, result.([]EdgeCustomer))
+		})
+	}
+}
+
+func TestModelWithLatestInvalidRelation(t *testing.T) {
+	model, _ := NewModel(&MockDB{}, &Post{})
+
+	if _, err := model.WithLatest("User", "created_at").All(context.Background()); err == nil {
+		t.Error("Expected error for a belongsTo relation")
+	}
+	if _, err := model.WithOldest("Missing", "created_at").All(context.Background()); err == nil {
+		t.Error("Expected error for an unknown relation")
+	}
+	if model.err != nil {
+		t.Errorf("Expected the model itself to keep working, got %v", model.err)
+	}
+}
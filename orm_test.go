@@ -839,3 +839,52 @@ type MockDBWithRows struct {
 func (m *MockDBWithRows) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	return m.rows, nil
 }
+
+// Store is a model collecting computed columns in an extras map
+type Store struct {
+	ID     int                    `db:"id,pk,auto"`
+	Name   string                 `db:"name"`
+	Extras map[string]interface{} `db:",extras"`
+}
+
+func TestModelScanExtras(t *testing.T) {
+	mock := NewMockSQL().Returning(
+		[]string{"id", "name", "distance", "rating"},
+		[]interface{}{int64(1), "Central", 1.25, []byte("4.5")},
+		[]interface{}{int64(2), "North", 3.5, nil},
+	)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, Store{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	model.Query().Select("*", "ST_Distance(location, POINT(0, 0)) AS distance", "rating")
+
+	result, err := model.All(context.Background())
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+
+	stores := result.([]Store)
+	if len(stores) != 2 {
+		t.Fatalf("Expected 2 stores, got %d", len(stores))
+	}
+	if stores[0].Name != "Central" || stores[0].Extras["distance"] != 1.25 || stores[0].Extras["rating"] != "4.5" {
+		t.Errorf("Unexpected first store: %+v", stores[0])
+	}
+	if _, ok := stores[1].Extras["rating"]; !ok || stores[1].Extras["rating"] != nil {
+		t.Errorf("Expected NULL rating to be kept as nil, got %+v", stores[1].Extras)
+	}
+	if _, ok := stores[0].Extras["name"]; ok {
+		t.Error("Mapped columns must not be copied into extras")
+	}
+
+	values, err := model.extractValues(stores[0], false)
+	if err != nil {
+		t.Fatalf("extractValues failed: %v", err)
+	}
+	if _, ok := values["extras"]; ok {
+		t.Error("Extras must not be written back to the database")
+	}
+}
@@ -0,0 +1,151 @@
+package qix
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pageParam is the query parameter carrying the page number
+const pageParam = "page"
+
+// defaultLinkWindow is the number of pages shown on each side of the current page
+const defaultLinkWindow = 2
+
+// PageLink is a numbered link for pagination UIs
+type PageLink struct {
+	Page   int    `json:"page"`
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+}
+
+// WithBaseURL generates the navigation URLs from base, replacing or appending
+// the page query parameter while keeping every other parameter as it is
+func (p *Paginator) WithBaseURL(base string) *Paginator {
+	p.baseURL = base
+	p.FirstPageURL = p.PageURL(1)
+	p.LastPageURL = p.PageURL(p.lastPage())
+	p.NextPageURL = ""
+	p.PrevPageURL = ""
+
+	if p.CurrentPage < p.lastPage() {
+		p.NextPageURL = p.PageURL(p.CurrentPage + 1)
+	}
+	if p.CurrentPage > 1 {
+		p.PrevPageURL = p.PageURL(p.CurrentPage - 1)
+	}
+	return p
+}
+
+// WithLinkWindow sets how many pages Links shows on each side of the current page
+func (p *Paginator) WithLinkWindow(window int) *Paginator {
+	p.linkWindow = window
+	return p
+}
+
+// PageURL returns the URL of the given page, or an empty string when no base URL is set
+func (p *Paginator) PageURL(page int) string {
+	if p.baseURL == "" {
+		return ""
+	}
+
+	base, fragment, hasFragment := strings.Cut(p.baseURL, "#")
+	path, rawQuery, _ := strings.Cut(base, "?")
+
+	pageValue := pageParam + "=" + strconv.Itoa(page)
+	params := make([]string, 0)
+	replaced := false
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err == nil && name == pageParam {
+			if !replaced {
+				params = append(params, pageValue)
+				replaced = true
+			}
+			continue
+		}
+		params = append(params, param)
+	}
+	if !replaced {
+		params = append(params, pageValue)
+	}
+
+	result := path + "?" + strings.Join(params, "&")
+	if hasFragment {
+		result += "#" + fragment
+	}
+	return result
+}
+
+// Links returns numbered page links for the current page plus the configured window on each side
+func (p *Paginator) Links() []PageLink {
+	window := p.linkWindow
+	if window <= 0 {
+		window = defaultLinkWindow
+	}
+
+	start := p.CurrentPage - window
+	if start < 1 {
+		start = 1
+	}
+	end := p.CurrentPage + window
+	if end > p.lastPage() {
+		end = p.lastPage()
+	}
+
+	links := make([]PageLink, 0, end-start+1)
+	for page := start; page <= end; page++ {
+		links = append(links, PageLink{
+			Page:   page,
+			URL:    p.PageURL(page),
+			Active: page == p.CurrentPage,
+		})
+	}
+	return links
+}
+
+// lastPage returns the last page number, an empty result still has one page
+func (p *Paginator) lastPage() int {
+	if p.LastPage < 1 {
+		return 1
+	}
+	return p.LastPage
+}
+
+// paginationLinks is the JSON representation of the navigation URLs
+type paginationLinks struct {
+	First string     `json:"first"`
+	Last  string     `json:"last"`
+	Next  *string    `json:"next"`
+	Prev  *string    `json:"prev"`
+	Pages []PageLink `json:"pages"`
+}
+
+// MarshalJSON encodes the paginator, adding a links object when a base URL was set
+func (p Paginator) MarshalJSON() ([]byte, error) {
+	type plain Paginator
+	if p.baseURL == "" {
+		return json.Marshal(plain(p))
+	}
+
+	links := paginationLinks{
+		First: p.FirstPageURL,
+		Last:  p.LastPageURL,
+		Pages: p.Links(),
+	}
+	if p.NextPageURL != "" {
+		links.Next = &p.NextPageURL
+	}
+	if p.PrevPageURL != "" {
+		links.Prev = &p.PrevPageURL
+	}
+
+	return json.Marshal(struct {
+		plain
+		Links paginationLinks `json:"links"`
+	}{plain(p), links})
+}
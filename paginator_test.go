@@ -0,0 +1,156 @@
+package qix
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPaginatorURLs(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		page  int
+		last  int
+		first string
+		lastU string
+		next  string
+		prev  string
+	}{
+		{
+			name:  "Middle page preserves parameters",
+			base:  "/api/users?status=active&q=caf%C3%A9+bar",
+			page:  2,
+			last:  4,
+			first: "/api/users?status=active&q=caf%C3%A9+bar&page=1",
+			lastU: "/api/users?status=active&q=caf%C3%A9+bar&page=4",
+			next:  "/api/users?status=active&q=caf%C3%A9+bar&page=3",
+			prev:  "/api/users?status=active&q=caf%C3%A9+bar&page=1",
+		},
+		{
+			name:  "Existing page parameter is replaced in place",
+			base:  "/api/users?page=7&status=active",
+			page:  1,
+			last:  2,
+			first: "/api/users?page=1&status=active",
+			lastU: "/api/users?page=2&status=active",
+			next:  "/api/users?page=2&status=active",
+		},
+		{
+			name:  "Last page without query string",
+			base:  "/api/users",
+			page:  3,
+			last:  3,
+			first: "/api/users?page=1",
+			lastU: "/api/users?page=3",
+			prev:  "/api/users?page=2",
+		},
+		{
+			name:  "Empty result has a single page",
+			base:  "/api/users#top",
+			page:  1,
+			last:  0,
+			first: "/api/users?page=1#top",
+			lastU: "/api/users?page=1#top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := (&Paginator{CurrentPage: tt.page, LastPage: tt.last}).WithBaseURL(tt.base)
+			if p.FirstPageURL != tt.first {
+				t.Errorf("FirstPageURL: expected %q, got %q", tt.first, p.FirstPageURL)
+			}
+			if p.LastPageURL != tt.lastU {
+				t.Errorf("LastPageURL: expected %q, got %q", tt.lastU, p.LastPageURL)
+			}
+			if p.NextPageURL != tt.next {
+				t.Errorf("NextPageURL: expected %q, got %q", tt.next, p.NextPageURL)
+			}
+			if p.PrevPageURL != tt.prev {
+				t.Errorf("PrevPageURL: expected %q, got %q", tt.prev, p.PrevPageURL)
+			}
+		})
+	}
+}
+
+func TestPaginatorLinksWindow(t *testing.T) {
+	tests := []struct {
+		current int
+		last    int
+		window  int
+		pages   []int
+	}{
+		{current: 5, last: 10, window: 2, pages: []int{3, 4, 5, 6, 7}},
+		{current: 1, last: 10, window: 2, pages: []int{1, 2, 3}},
+		{current: 10, last: 10, window: 3, pages: []int{7, 8, 9, 10}},
+		{current: 2, last: 3, window: 5, pages: []int{1, 2, 3}},
+		{current: 1, last: 0, window: 0, pages: []int{1}},
+	}
+
+	for _, tt := range tests {
+		p := (&Paginator{CurrentPage: tt.current, LastPage: tt.last}).
+			WithLinkWindow(tt.window).
+			WithBaseURL("/items")
+		links := p.Links()
+
+		if len(links) != len(tt.pages) {
+			t.Errorf("current=%d last=%d: expected pages %v, got %+v", tt.current, tt.last, tt.pages, links)
+			continue
+		}
+		for i, link := range links {
+			if link.Page != tt.pages[i] {
+				t.Errorf("current=%d: expected page %d at %d, got %d", tt.current, tt.pages[i], i, link.Page)
+			}
+			if link.Active != (link.Page == tt.current) {
+				t.Errorf("current=%d: wrong active flag on page %d", tt.current, link.Page)
+			}
+		}
+	}
+}
+
+func TestPaginatorMarshalJSON(t *testing.T) {
+	p := &Paginator{Total: 25, PerPage: 10, CurrentPage: 1, LastPage: 3}
+
+	plain, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(plain), "links") {
+		t.Errorf("Expected no links without a base URL, got %s", plain)
+	}
+
+	withLinks, err := json.Marshal(p.WithBaseURL("/api/users?status=active"))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded struct {
+		Total int64
+		Links struct {
+			First string
+			Next  *string
+			Prev  *string
+			Pages []PageLink
+		}
+	}
+	if err := json.Unmarshal(withLinks, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if decoded.Total != 25 {
+		t.Errorf("Expected total 25, got %d", decoded.Total)
+	}
+	if decoded.Links.First != "/api/users?status=active&page=1" {
+		t.Errorf("Unexpected first link %q", decoded.Links.First)
+	}
+	if decoded.Links.Next == nil || *decoded.Links.Next != "/api/users?status=active&page=2" {
+		t.Errorf("Unexpected next link %v", decoded.Links.Next)
+	}
+	if decoded.Links.Prev != nil {
+		t.Errorf("Expected null prev link on the first page, got %q", *decoded.Links.Prev)
+	}
+	if len(decoded.Links.Pages) != 3 {
+		t.Errorf("Expected 3 page links, got %d", len(decoded.Links.Pages))
+	}
+}
@@ -0,0 +1,188 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrPartitionUnsupported is returned when the dialect cannot express a
+// partitioning feature, such as partition selection outside MySQL
+var ErrPartitionUnsupported = errors.New("partitioning not supported by dialect")
+
+// Partition restricts a MySQL SELECT or DELETE to the named partitions,
+// rendering FROM table PARTITION (p2024_01, p2024_02)
+func (b *Builder) Partition(names ...string) *Builder {
+	switch b.dialect.(type) {
+	case nil, mysqlDialect:
+	default:
+		b.setErr(fmt.Errorf("%w: partition selection", ErrPartitionUnsupported))
+		return b
+	}
+	for _, name := range names {
+		b.checkIdentifier(name)
+	}
+	b.partitions = append(b.partitions, names...)
+	return b
+}
+
+// fromSQL renders the table of the FROM clause with its partition selection
+func (b *Builder) fromSQL() string {
+	if len(b.partitions) == 0 {
+		return b.quote(b.table)
+	}
+	return b.quote(b.table) + " PARTITION (" + strings.Join(b.quoteAll(b.partitions), ", ") + ")"
+}
+
+// PartitionBound declares one partition of a partitioned table
+type PartitionBound struct {
+	Name   string
+	Values []interface{} // Exclusive upper bound for RANGE (none for MAXVALUE), accepted values for LIST
+}
+
+// LessThan declares a RANGE partition holding the rows below value,
+// nil declares the catch-all MAXVALUE partition
+func LessThan(name string, value interface{}) PartitionBound {
+	if value == nil {
+		return PartitionBound{Name: name}
+	}
+	return PartitionBound{Name: name, Values: []interface{}{value}}
+}
+
+// ValuesIn declares a LIST partition holding the rows matching values
+func ValuesIn(name string, values ...interface{}) PartitionBound {
+	return PartitionBound{Name: name, Values: values}
+}
+
+// partitioning is the partitioning scheme declared on a SchemaBuilder
+type partitioning struct {
+	method string // RANGE or LIST
+	column string
+	bounds []PartitionBound
+}
+
+// PartitionByRange partitions the table on ranges of column, bounds are
+// given in ascending order, see LessThan
+func (s *SchemaBuilder) PartitionByRange(column string, bounds ...PartitionBound) *SchemaBuilder {
+	s.partitioning = &partitioning{method: "RANGE", column: column, bounds: bounds}
+	return s
+}
+
+// PartitionByList partitions the table on lists of column values, see ValuesIn
+func (s *SchemaBuilder) PartitionByList(column string, bounds ...PartitionBound) *SchemaBuilder {
+	s.partitioning = &partitioning{method: "LIST", column: column, bounds: bounds}
+	return s
+}
+
+// partitionedTableSQL appends the partitioning of p to a CREATE TABLE
+// statement. MySQL declares the partitions inline while Postgres creates
+// each one as a table attached to the parent.
+func (b *Builder) partitionedTableSQL(create, table string, p *partitioning) ([]string, error) {
+	switch b.dialect.(type) {
+	case nil, mysqlDialect:
+		defs := make([]string, len(p.bounds))
+		for i, bound := range p.bounds {
+			defs[i] = "PARTITION " + b.quote(bound.Name) + " " + mysqlPartitionValues(p.method, bound)
+		}
+		return []string{fmt.Sprintf("%s PARTITION BY %s COLUMNS (%s) (\n%s\n)",
+			create, p.method, b.quote(p.column), strings.Join(defs, ",\n"))}, nil
+
+	case postgresDialect:
+		statements := []string{fmt.Sprintf("%s PARTITION BY %s (%s)", create, p.method, b.quote(p.column))}
+		var from interface{}
+		for _, bound := range p.bounds {
+			var values string
+			if p.method == "LIST" {
+				values = "IN (" + sqlLiterals(bound.Values) + ")"
+			} else {
+				var to interface{}
+				if len(bound.Values) > 0 {
+					to = bound.Values[0]
+				}
+				values = postgresRangeValues(from, to)
+				from = to
+			}
+			statements = append(statements, b.partitionOfSQL(table, bound.Name, values))
+		}
+		return statements, nil
+	}
+	return nil, fmt.Errorf("%w: table partitioning", ErrPartitionUnsupported)
+}
+
+// CreatePartition adds a RANGE partition holding the rows from from
+// (inclusive) to to (exclusive) to a partitioned table. MySQL partitions
+// only have an upper bound, from is ignored there; nil bounds are open.
+func (b *Builder) CreatePartition(ctx context.Context, parent, name string, from, to interface{}) error {
+	var query string
+	switch b.dialect.(type) {
+	case nil, mysqlDialect:
+		query = fmt.Sprintf("ALTER TABLE %s ADD PARTITION (PARTITION %s %s)",
+			b.quote(parent), b.quote(name), mysqlPartitionValues("RANGE", LessThan(name, to)))
+	case postgresDialect:
+		query = b.partitionOfSQL(parent, name, postgresRangeValues(from, to))
+	default:
+		return fmt.Errorf("%w: table partitioning", ErrPartitionUnsupported)
+	}
+	_, err := b.execContext(ctx, query)
+	return err
+}
+
+// partitionOfSQL renders a Postgres partition attached to parent
+func (b *Builder) partitionOfSQL(parent, name, values string) string {
+	return fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES %s", b.quote(name), b.quote(parent), values)
+}
+
+// mysqlPartitionValues renders the VALUES part of a MySQL partition definition
+func mysqlPartitionValues(method string, bound PartitionBound) string {
+	if method == "LIST" {
+		return "VALUES IN (" + sqlLiterals(bound.Values) + ")"
+	}
+	if len(bound.Values) == 0 {
+		return "VALUES LESS THAN MAXVALUE"
+	}
+	return "VALUES LESS THAN (" + sqlLiterals(bound.Values) + ")"
+}
+
+// postgresRangeValues renders FROM (...) TO (...), nil bounds are open
+func postgresRangeValues(from, to interface{}) string {
+	lower, upper := "MINVALUE", "MAXVALUE"
+	if from != nil {
+		lower = sqlLiteral(from)
+	}
+	if to != nil {
+		upper = sqlLiteral(to)
+	}
+	return "FROM (" + lower + ") TO (" + upper + ")"
+}
+
+// sqlLiterals renders values as a comma separated list of literals
+func sqlLiterals(values []interface{}) string {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = sqlLiteral(value)
+	}
+	return strings.Join(literals, ", ")
+}
+
+// sqlLiteral renders a value inline for DDL, which cannot take bindings
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return "'" + v.Format("2006-01-02") + "'"
+		}
+		return "'" + v.Format("2006-01-02 15:04:05") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,171 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPartitionSelection(t *testing.T) {
+	builder := New(&MockDB{}).Table("logs").Partition("p2024_01", "p2024_02").Where("level", "=", "error")
+	expected := "SELECT * FROM logs PARTITION (p2024_01, p2024_02) WHERE level = ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+
+	var query string
+	db := &MockDB{
+		execFunc: func(ctx context.Context, q string, args ...interface{}) (sql.Result, error) {
+			query = q
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+	if _, err := New(db, MySQLDialect, WithQuotedIdentifiers(true)).Table("logs").Partition("p2023_12").Where("level", "=", "debug").DeleteWithContext(context.Background()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	expected = "DELETE FROM `logs` PARTITION (`p2023_12`) WHERE `level` = ?"
+	if query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, query)
+	}
+
+	_, err := New(&MockDB{}, PostgresDialect).Table("logs").Partition("p2024_01").Get(context.Background())
+	if !errors.Is(err, ErrPartitionUnsupported) {
+		t.Errorf("Expected ErrPartitionUnsupported, got %v", err)
+	}
+}
+
+func TestCreatePartitionedTable(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC) }
+	byMonth := func(s *SchemaBuilder) {
+		s.Column("id", "BIGINT NOT NULL").
+			Column("created_at", "DATE NOT NULL").
+			PartitionByRange("created_at",
+				LessThan("logs_2024_01", month(time.February)),
+				LessThan("logs_2024_02", month(time.March)),
+				LessThan("logs_future", nil))
+	}
+	byRegion := func(s *SchemaBuilder) {
+		s.Column("id", "BIGINT NOT NULL").
+			Column("region", "VARCHAR(8) NOT NULL").
+			PartitionByList("region",
+				ValuesIn("accounts_eu", "de", "fr"),
+				ValuesIn("accounts_us", "us"))
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		table    string
+		schema   func(*SchemaBuilder)
+		expected []string
+	}{
+		{
+			name:    "MySQLRange",
+			dialect: MySQLDialect,
+			table:   "logs",
+			schema:  byMonth,
+			expected: []string{"CREATE TABLE logs (\nid BIGINT NOT NULL,\ncreated_at DATE NOT NULL\n) PARTITION BY RANGE COLUMNS (created_at) (\n" +
+				"PARTITION logs_2024_01 VALUES LESS THAN ('2024-02-01'),\n" +
+				"PARTITION logs_2024_02 VALUES LESS THAN ('2024-03-01'),\n" +
+				"PARTITION logs_future VALUES LESS THAN MAXVALUE\n)"},
+		},
+		{
+			name:    "MySQLList",
+			dialect: MySQLDialect,
+			table:   "accounts",
+			schema:  byRegion,
+			expected: []string{"CREATE TABLE accounts (\nid BIGINT NOT NULL,\nregion VARCHAR(8) NOT NULL\n) PARTITION BY LIST COLUMNS (region) (\n" +
+				"PARTITION accounts_eu VALUES IN ('de', 'fr'),\n" +
+				"PARTITION accounts_us VALUES IN ('us')\n)"},
+		},
+		{
+			name:    "PostgresRange",
+			dialect: PostgresDialect,
+			table:   "logs",
+			schema:  byMonth,
+			expected: []string{
+				"CREATE TABLE logs (\nid BIGINT NOT NULL,\ncreated_at DATE NOT NULL\n) PARTITION BY RANGE (created_at)",
+				"CREATE TABLE logs_2024_01 PARTITION OF logs FOR VALUES FROM (MINVALUE) TO ('2024-02-01')",
+				"CREATE TABLE logs_2024_02 PARTITION OF logs FOR VALUES FROM ('2024-02-01') TO ('2024-03-01')",
+				"CREATE TABLE logs_future PARTITION OF logs FOR VALUES FROM ('2024-03-01') TO (MAXVALUE)",
+			},
+		},
+		{
+			name:    "PostgresList",
+			dialect: PostgresDialect,
+			table:   "accounts",
+			schema:  byRegion,
+			expected: []string{
+				"CREATE TABLE accounts (\nid BIGINT NOT NULL,\nregion VARCHAR(8) NOT NULL\n) PARTITION BY LIST (region)",
+				"CREATE TABLE accounts_eu PARTITION OF accounts FOR VALUES IN ('de', 'fr')",
+				"CREATE TABLE accounts_us PARTITION OF accounts FOR VALUES IN ('us')",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			db := &MockDB{
+				execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+					queries = append(queries, query)
+					return MockResult{}, nil
+				},
+			}
+
+			if err := New(db, tt.dialect).CreateTable(tt.table, tt.schema); err != nil {
+				t.Fatalf("CreateTable failed: %v", err)
+			}
+			if len(queries) != len(tt.expected) {
+				t.Fatalf("Expected %d statements, got %d: %q", len(tt.expected), len(queries), queries)
+			}
+			for i := range tt.expected {
+				if queries[i] != tt.expected[i] {
+					t.Errorf("Expected SQL:\n%s\nGot:\n%s", tt.expected[i], queries[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePartition(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	db := &MockDB{
+		execFunc: func(ctx context.Context, q string, args ...interface{}) (sql.Result, error) {
+			query = q
+			return MockResult{}, nil
+		},
+	}
+
+	if err := New(db, PostgresDialect).CreatePartition(ctx, "logs", "logs_2024_03", "2024-03-01", "2024-04-01"); err != nil {
+		t.Fatalf("CreatePartition failed: %v", err)
+	}
+	expected := "CREATE TABLE logs_2024_03 PARTITION OF logs FOR VALUES FROM ('2024-03-01') TO ('2024-04-01')"
+	if query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, query)
+	}
+
+	if err := New(db).CreatePartition(ctx, "logs", "p2024_03", nil, "2024-04-01"); err != nil {
+		t.Fatalf("CreatePartition failed: %v", err)
+	}
+	expected = "ALTER TABLE logs ADD PARTITION (PARTITION p2024_03 VALUES LESS THAN ('2024-04-01'))"
+	if query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, query)
+	}
+}
+
+func TestPartitioningUnsupported(t *testing.T) {
+	db := &MockDB{}
+	err := New(db, SQLiteDialect).CreateTable("logs", func(s *SchemaBuilder) {
+		s.Column("id", "INTEGER").PartitionByList("id", ValuesIn("p1", 1))
+	})
+	if !errors.Is(err, ErrPartitionUnsupported) {
+		t.Errorf("Expected ErrPartitionUnsupported, got %v", err)
+	}
+	if err := New(db, SQLiteDialect).CreatePartition(context.Background(), "logs", "p1", nil, 10); !errors.Is(err, ErrPartitionUnsupported) {
+		t.Errorf("Expected ErrPartitionUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Pluck returns the values of a single column, NULLs are returned as nil
+func (b *Builder) Pluck(ctx context.Context, column string) ([]interface{}, error) {
+	b.selectOnly(column)
+
+	rows, err := b.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []interface{}
+	for rows.Next() {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// selectOnly replaces the column list with column, dropping the bindings
+// of replaced sub-selects and raw expressions
+func (b *Builder) selectOnly(column string) {
+	b.columns = []string{column}
+	b.selectBindings = nil
+}
+
+// Value returns the first cell of the first row for the given column or
+// expression, such as MAX(id) or COUNT(*). It returns sql.ErrNoRows when
+// nothing matches and nil for NULL. The builder's columns and limit are
+// left unchanged, so it can be reused.
+func (b *Builder) Value(ctx context.Context, column string) (interface{}, error) {
+	var value interface{}
+	if err := b.ValueInto(ctx, column, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ValueInto is Value scanning the cell into dest, any destination accepted
+// by rows.Scan
+func (b *Builder) ValueInto(ctx context.Context, column string, dest interface{}) error {
+	q := b.Clone()
+	q.selectOnly(column)
+
+	rows, err := q.First(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(dest)
+}
+
+// PluckTypeError is returned by PluckAs and ValueAs when a value cannot be converted
+type PluckTypeError struct {
+	Column string
+	Index  int // Row of the value
+	Value  interface{}
+	Type   reflect.Type // Requested type
+}
+
+func (e *PluckTypeError) Error() string {
+	return fmt.Sprintf("cannot convert %s value %v (%T) at row %d to %s", e.Column, e.Value, e.Value, e.Index, e.Type)
+}
+
+// PluckAs is Pluck converting each value to T. NULLs become the zero value
+// of T, driver []byte values convert to string and numbers convert between
+// numeric types; anything else fails with a *PluckTypeError.
+func PluckAs[T any](ctx context.Context, b *Builder, column string) ([]T, error) {
+	values, err := b.Pluck(ctx, column)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(values))
+	for i, value := range values {
+		if result[i], err = convertTo[T](column, i, value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ValueAs is Value converting the result to T like PluckAs does
+func ValueAs[T any](ctx context.Context, b *Builder, column string) (T, error) {
+	value, err := b.Value(ctx, column)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return convertTo[T](column, 0, value)
+}
+
+// convertTo converts a scanned value to T, nil becomes the zero value
+func convertTo[T any](column string, index int, value interface{}) (T, error) {
+	var result T
+	if value == nil {
+		return result, nil
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	converted, ok := convertPlucked(value, target)
+	if !ok {
+		return result, &PluckTypeError{Column: column, Index: index, Value: value, Type: target}
+	}
+	return converted.Interface().(T), nil
+}
+
+// convertPlucked converts driver values to target when it is lossless in
+// meaning: []byte to string and numbers to other numeric types
+func convertPlucked(value interface{}, target reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	if bytes, ok := value.([]byte); ok && target.Kind() == reflect.String {
+		return reflect.ValueOf(string(bytes)).Convert(target), true
+	}
+	if isNumericKind(v.Kind()) && isNumericKind(target.Kind()) {
+		return v.Convert(target), true
+	}
+	return reflect.Value{}, false
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
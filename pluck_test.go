@@ -0,0 +1,158 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPluck(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"email"},
+		[]interface{}{"ann@example.com"},
+		[]interface{}{nil},
+		[]interface{}{"ben@example.com"},
+	)
+	defer mock.DB.Close()
+
+	values, err := New(mock.DB).Table("users").Select("id", "name").Where("active", "=", true).Pluck(ctx, "email")
+	if err != nil {
+		t.Fatalf("Pluck failed: %v", err)
+	}
+	if !reflect.DeepEqual(values, []interface{}{"ann@example.com", nil, "ben@example.com"}) {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 || calls[0].Query != "SELECT email FROM users WHERE active = ?" {
+		t.Errorf("Unexpected calls: %+v", calls)
+	}
+}
+
+func TestPluckKeepsClauses(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"email"}, []interface{}{"ann@example.com"})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("users").
+		SelectRaw("COALESCE(nickname, ?) AS label", "anonymous").
+		Where("active", "=", true).
+		OrderBy("created_at", "DESC").
+		Limit(2)
+	if _, err := builder.Pluck(ctx, "email"); err != nil {
+		t.Fatalf("Pluck failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	expected := "SELECT email FROM users WHERE active = ? ORDER BY created_at DESC LIMIT ?"
+	if calls[0].Query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, calls[0].Query)
+	}
+	if !reflect.DeepEqual(calls[0].Args, []interface{}{true, int64(2)}) {
+		t.Errorf("Expected the select bindings to be dropped, got %v", calls[0].Args)
+	}
+}
+
+func TestPluckAs(t *testing.T) {
+	ctx := context.Background()
+
+	mock := NewMockSQL().Returning([]string{"name"},
+		[]interface{}{[]byte("ann")},
+		[]interface{}{nil},
+		[]interface{}{"ben"},
+	)
+	defer mock.DB.Close()
+
+	names, err := PluckAs[string](ctx, New(mock.DB).Table("users"), "name")
+	if err != nil {
+		t.Fatalf("PluckAs failed: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"ann", "", "ben"}) {
+		t.Errorf("Unexpected names %q", names)
+	}
+
+	ids := NewMockSQL().Returning([]string{"id"}, []interface{}{int64(1)}, []interface{}{int64(2)})
+	defer ids.DB.Close()
+
+	got, err := PluckAs[int](ctx, New(ids.DB).Table("users"), "id")
+	if err != nil {
+		t.Fatalf("PluckAs failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Unexpected ids %v", got)
+	}
+}
+
+func TestPluckAsConversionError(t *testing.T) {
+	mock := NewMockSQL().Returning([]string{"id"}, []interface{}{int64(1)}, []interface{}{"two"})
+	defer mock.DB.Close()
+
+	_, err := PluckAs[int64](context.Background(), New(mock.DB).Table("users"), "id")
+	var typeErr *PluckTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Expected *PluckTypeError, got %v", err)
+	}
+	if typeErr.Column != "id" || typeErr.Index != 1 || typeErr.Value != "two" {
+		t.Errorf("Unexpected error details %+v", typeErr)
+	}
+}
+
+func TestValue(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"max"}, []interface{}{int64(42)})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("orders").Select("id", "total").Where("status", "=", "paid").Limit(20)
+	value, err := builder.Value(ctx, "MAX(id)")
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	if value != int64(42) {
+		t.Errorf("Expected 42, got %v", value)
+	}
+	if sql := builder.ToSQL(); sql != "SELECT id, total FROM orders WHERE status = ? LIMIT ?" {
+		t.Errorf("Value changed the builder: %s", sql)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 || calls[0].Query != "SELECT MAX(id) FROM orders WHERE status = ? LIMIT ?" {
+		t.Errorf("Unexpected calls: %+v", calls)
+	}
+
+	max, err := ValueAs[int](ctx, New(mock.DB).Table("orders"), "MAX(id)")
+	if err != nil || max != 42 {
+		t.Errorf("Expected 42, got %v (%v)", max, err)
+	}
+	if _, err := ValueAs[bool](ctx, New(mock.DB).Table("orders"), "MAX(id)"); err == nil {
+		t.Error("Expected a conversion error")
+	}
+
+	var id int
+	if err := New(mock.DB).Table("orders").ValueInto(ctx, "MAX(id)", &id); err != nil || id != 42 {
+		t.Errorf("Expected 42, got %v (%v)", id, err)
+	}
+}
+
+func TestValueNoRows(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"email"})
+	defer mock.DB.Close()
+
+	if _, err := New(mock.DB).Table("users").Value(ctx, "email"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+	var missing sql.NullString
+	if err := New(mock.DB).Table("users").ValueInto(ctx, "email", &missing); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+
+	nulls := NewMockSQL().Returning([]string{"email"}, []interface{}{nil})
+	defer nulls.DB.Close()
+
+	email, err := ValueAs[string](ctx, New(nulls.DB).Table("users"), "email")
+	if err != nil || email != "" {
+		t.Errorf("Expected empty string for NULL, got %q (%v)", email, err)
+	}
+}
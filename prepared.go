@@ -0,0 +1,209 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// preparer is implemented by connections that can prepare statements
+// on the driver (*sql.DB, *sql.Tx and *sql.Conn)
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// PreparedQuery is a rendered statement that can be executed repeatedly
+// with different bindings. It is safe for concurrent use.
+type PreparedQuery struct {
+	db           DB
+	table        string // Its cached counts are invalidated by Exec
+	query        string
+	placeholders int
+	stmt         *sql.Stmt // nil when the driver cannot prepare statements
+	metrics      *Metrics
+	events       *Builder // Holds the query event handlers of the builder
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// ErrPreparedQueryClosed is returned when executing a closed prepared query
+var ErrPreparedQueryClosed = errors.New("prepared query is closed")
+
+// Prepare freezes the SQL described by the builder so it can be executed
+// many times without rebuilding it. The statement is also prepared on the
+// driver when the connection supports it. Insert and Update columns are
+// sorted by name, Exec takes their values in that order.
+func (b *Builder) Prepare(ctx context.Context) (*PreparedQuery, error) {
+	query, err := b.statementSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	pq := &PreparedQuery{
+		db:           b.db,
+		table:        b.table,
+		query:        b.rebind(query),
+		placeholders: countPlaceholders(query),
+		metrics:      b.metrics,
+		events: &Builder{
+			beforeQueryHandlers: append([]QueryEventHandler(nil), b.beforeQueryHandlers...),
+			afterQueryHandlers:  append([]QueryEventHandler(nil), b.afterQueryHandlers...),
+		},
+	}
+
+	if p, ok := b.db.(preparer); ok {
+		stmt, err := p.PrepareContext(ctx, pq.query)
+		if err != nil {
+			return nil, err
+		}
+		pq.stmt = stmt
+	}
+
+	return pq, nil
+}
+
+// statementSQL renders the statement described by Insert/Update/Delete,
+// or the SELECT query when none of them was called
+func (b *Builder) statementSQL() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	if b.table == "" {
+		return "", errors.New("table name is required")
+	}
+
+	switch b.statement {
+	case statementInsert:
+		if len(b.columns) == 0 {
+			return "", errors.New("insert requires at least one column")
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(b.columns)), ", ")
+		return "INSERT INTO " + b.quote(b.table) + " (" + strings.Join(b.quoteAll(b.columns), ", ") + ") VALUES (" + placeholders + ")", nil
+
+	case statementUpdate:
+		if len(b.columns) == 0 {
+			return "", errors.New("update requires at least one column")
+		}
+		sets := make([]string, len(b.columns))
+		for i, column := range b.columns {
+			sets[i] = b.quote(column) + " = ?"
+		}
+		query := "UPDATE " + b.quote(b.table) + " SET " + strings.Join(sets, ", ")
+		if len(b.wheres) > 0 {
+			query += " WHERE " + b.whereSQL()
+		}
+		return query, nil
+
+	case statementDelete:
+		query, _ := b.deleteSQL()
+		return query, nil
+	}
+
+	return b.toSQL(), nil
+}
+
+// SQL returns the frozen statement
+func (p *PreparedQuery) SQL() string {
+	return p.query
+}
+
+// NumInput returns the number of bindings the statement expects
+func (p *PreparedQuery) NumInput() int {
+	return p.placeholders
+}
+
+// Exec executes the statement with the given bindings, invalidating the
+// counts cached for its table on success
+func (p *PreparedQuery) Exec(ctx context.Context, bindings ...interface{}) (sql.Result, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if err := p.check(bindings); err != nil {
+		return nil, err
+	}
+
+	event := p.events.beforeQuery(p.query, bindings)
+	start := time.Now()
+	var result sql.Result
+	var err error
+	if p.stmt != nil {
+		result, err = p.stmt.ExecContext(ctx, bindings...)
+	} else {
+		result, err = p.db.ExecContext(ctx, p.query, bindings...)
+	}
+	elapsed := time.Since(start)
+	p.metrics.record(elapsed, err)
+	p.events.afterQuery(event, elapsed, err)
+	if err == nil && p.table != "" {
+		InvalidateCountCache(p.table)
+	}
+	return result, err
+}
+
+// Query executes the statement with the given bindings and returns the rows
+func (p *PreparedQuery) Query(ctx context.Context, bindings ...interface{}) (*sql.Rows, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if err := p.check(bindings); err != nil {
+		return nil, err
+	}
+
+	event := p.events.beforeQuery(p.query, bindings)
+	start := time.Now()
+	var rows *sql.Rows
+	var err error
+	if p.stmt != nil {
+		rows, err = p.stmt.QueryContext(ctx, bindings...)
+	} else {
+		rows, err = p.db.QueryContext(ctx, p.query, bindings...)
+	}
+	elapsed := time.Since(start)
+	p.metrics.record(elapsed, err)
+	p.events.afterQuery(event, elapsed, err)
+	observeN1(event)
+	return rows, err
+}
+
+// Close releases the driver statement. Closing twice is a no-op.
+func (p *PreparedQuery) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	if p.stmt != nil {
+		return p.stmt.Close()
+	}
+	return nil
+}
+
+// check validates the statement state and binding count, callers must hold p.mu
+func (p *PreparedQuery) check(bindings []interface{}) error {
+	if p.closed {
+		return ErrPreparedQueryClosed
+	}
+	if len(bindings) != p.placeholders {
+		return fmt.Errorf("prepared query expects %d bindings, got %d", p.placeholders, len(bindings))
+	}
+	return nil
+}
+
+// countPlaceholders counts the ? placeholders outside of quoted literals,
+// with the scanner rebind uses
+func countPlaceholders(query string) int {
+	count := 0
+	replacePlaceholders(query, func(n int) string {
+		count = n
+		return "?"
+	})
+	return count
+}
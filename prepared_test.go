@@ -0,0 +1,349 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPrepareRendersStatement(t *testing.T) {
+	ctx := context.Background()
+	db := &MockDB{}
+
+	tests := []struct {
+		name     string
+		build    func() *Builder
+		expected string
+		inputs   int
+	}{
+		{
+			name: "Select",
+			build: func() *Builder {
+				return New(db).Table("users").Where("id", "=", 0)
+			},
+			expected: "SELECT * FROM users WHERE id = ?",
+			inputs:   1,
+		},
+		{
+			name: "Insert",
+			build: func() *Builder {
+				return New(db).Table("events").Insert(map[string]interface{}{
+					"topic":   nil,
+					"payload": nil,
+				})
+			},
+			expected: "INSERT INTO events (payload, topic) VALUES (?, ?)",
+			inputs:   2,
+		},
+		{
+			name: "Update",
+			build: func() *Builder {
+				return New(db).Table("users").Update(map[string]interface{}{"name": nil}).Where("id", "=", 0)
+			},
+			expected: "UPDATE users SET name = ? WHERE id = ?",
+			inputs:   2,
+		},
+		{
+			name: "Delete",
+			build: func() *Builder {
+				return New(db).Table("users").Where("id", "=", 0).Delete()
+			},
+			expected: "DELETE FROM users WHERE id = ?",
+			inputs:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq, err := tt.build().Prepare(ctx)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if pq.SQL() != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, pq.SQL())
+			}
+			if pq.NumInput() != tt.inputs {
+				t.Errorf("Expected %d inputs, got %d", tt.inputs, pq.NumInput())
+			}
+		})
+	}
+}
+
+func TestPrepareRequiresTable(t *testing.T) {
+	_, err := New(&MockDB{}).Where("id", "=", 1).Prepare(context.Background())
+	if err == nil {
+		t.Error("Expected error when preparing without a table")
+	}
+}
+
+func TestPreparedQueryBindingCount(t *testing.T) {
+	ctx := context.Background()
+	var executed int
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			executed++
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	pq, err := New(db).Table("events").Insert(map[string]interface{}{"topic": nil, "payload": nil}).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	if _, err := pq.Exec(ctx, "only-one"); err == nil {
+		t.Error("Expected error for too few bindings")
+	}
+	if _, err := pq.Exec(ctx, 1, 2, 3); err == nil {
+		t.Error("Expected error for too many bindings")
+	}
+	if executed != 0 {
+		t.Errorf("Expected no statement to be executed, got %d", executed)
+	}
+
+	if _, err := pq.Exec(ctx, "{}", "orders"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if executed != 1 {
+		t.Errorf("Expected 1 execution, got %d", executed)
+	}
+}
+
+func TestPreparedQueryClose(t *testing.T) {
+	ctx := context.Background()
+	pq, err := New(&MockDB{}).Table("users").Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	if err := pq.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := pq.Close(); err != nil {
+		t.Errorf("Second Close should be a no-op, got %v", err)
+	}
+	if _, err := pq.Query(ctx); err != ErrPreparedQueryClosed {
+		t.Errorf("Expected ErrPreparedQueryClosed, got %v", err)
+	}
+}
+
+func TestPreparedQueryUsesDriverStatement(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnExec(func(ctx context.Context, query string, args []interface{}) (driver.Result, error) {
+		return driver.RowsAffected(1), nil
+	})
+	defer mock.DB.Close()
+
+	pq, err := New(mock.DB).Table("events").Insert(map[string]interface{}{"topic": nil}).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	defer pq.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := pq.Exec(ctx, int64(i)); err != nil {
+				t.Errorf("Exec failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	calls := mock.Calls()
+	if len(calls) != 20 {
+		t.Fatalf("Expected 20 executions, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if call.Query != "INSERT INTO events (topic) VALUES (?)" {
+			t.Errorf("Unexpected query: %s", call.Query)
+		}
+	}
+	// Statements are prepared lazily per connection, never per execution
+	if mock.Prepares() >= len(calls) {
+		t.Errorf("Expected statement to be reused, got %d prepares for %d executions", mock.Prepares(), len(calls))
+	}
+}
+
+func TestPreparedQueryReusedWithDifferentBindings(t *testing.T) {
+	ctx := context.Background()
+	names := map[int64]string{1: "ann", 2: "ben", 3: "cid"}
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		id := args[0].(int64)
+		return &MockResultSet{
+			Columns: []string{"id", "name"},
+			Rows:    [][]interface{}{{id, names[id]}},
+		}, nil
+	})
+	defer mock.DB.Close()
+
+	pq, err := New(mock.DB).Table("users").Select("id", "name").Where("id", "=", 0).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	defer pq.Close()
+
+	for _, id := range []int64{1, 2, 3} {
+		rows, err := pq.Query(ctx, id)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+
+		var gotID int64
+		var name string
+		if !rows.Next() {
+			t.Fatalf("Expected a row for id %d", id)
+		}
+		if err := rows.Scan(&gotID, &name); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		rows.Close()
+
+		if gotID != id || name != names[id] {
+			t.Errorf("Expected %d/%s, got %d/%s", id, names[id], gotID, name)
+		}
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected 3 executions, got %d", len(calls))
+	}
+	for i, call := range calls {
+		if call.Query != "SELECT id, name FROM users WHERE id = ?" {
+			t.Errorf("Unexpected query: %s", call.Query)
+		}
+		if len(call.Args) != 1 || call.Args[0] != int64(i+1) {
+			t.Errorf("Execution %d: expected args [%d], got %v", i, i+1, call.Args)
+		}
+	}
+	if mock.Prepares() != 1 {
+		t.Errorf("Expected the statement to be prepared once, got %d", mock.Prepares())
+	}
+}
+
+func TestPreparedQueryExecWithDifferentBindings(t *testing.T) {
+	ctx := context.Background()
+	var got [][]interface{}
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			got = append(got, args)
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	pq, err := New(db).Table("users").Update(map[string]interface{}{"name": nil}).Where("id", "=", 0).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	rows := [][]interface{}{{"ann", 1}, {"ben", 2}}
+	for _, args := range rows {
+		if _, err := pq.Exec(ctx, args...); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+
+	if len(got) != len(rows) {
+		t.Fatalf("Expected %d executions, got %d", len(rows), len(got))
+	}
+	for i := range rows {
+		if got[i][0] != rows[i][0] || got[i][1] != rows[i][1] {
+			t.Errorf("Execution %d: expected %v, got %v", i, rows[i], got[i])
+		}
+	}
+}
+
+func TestPreparedQueryEvents(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("disk full")
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			if args[0] == "bad" {
+				return nil, failure
+			}
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	var before, after []QueryEvent
+	pq, err := New(db).
+		BeforeQuery(func(e *QueryEvent) { before = append(before, *e) }).
+		AfterQuery(func(e *QueryEvent) { after = append(after, *e) }).
+		Table("users").Update(map[string]interface{}{"name": nil}).Where("id", "=", 0).Prepare(ctx)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	if _, err := pq.Exec(ctx, "ann", 1); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if _, err := pq.Exec(ctx, "bad", 2); !errors.Is(err, failure) {
+		t.Fatalf("Expected the driver error, got %v", err)
+	}
+
+	if len(before) != 2 || len(after) != 2 {
+		t.Fatalf("Expected both handlers per Exec, got %d before and %d after", len(before), len(after))
+	}
+	if before[0].SQL != pq.SQL() || before[1].Bindings[0] != "bad" {
+		t.Errorf("Unexpected before events %+v", before)
+	}
+	if after[0].Err != nil || !errors.Is(after[1].Err, failure) {
+		t.Errorf("Expected the error in the second after event, got %+v", after)
+	}
+}
+
+func TestCountPlaceholders(t *testing.T) {
+	query := "SELECT * FROM t WHERE a = ? AND b = '?' AND c = \"?\" AND d IN (?, ?)"
+	if n := countPlaceholders(query); n != 3 {
+		t.Errorf("Expected 3 placeholders, got %d", n)
+	}
+	if strings.Count(query, "?") != 5 {
+		t.Fatal("test query changed")
+	}
+}
+
+func BenchmarkPreparedExec(b *testing.B) {
+	ctx := context.Background()
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+	row := map[string]interface{}{"topic": "orders", "partition": 1, "offset": 42, "payload": "{}"}
+
+	pq, err := New(db).Table("events").Insert(row).Prepare(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pq.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pq.Exec(ctx, i, "orders", "{}", 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRebuildExec(b *testing.B) {
+	ctx := context.Background()
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			return MockResult{rowsAffected: 1}, nil
+		},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		row := map[string]interface{}{"topic": "orders", "partition": 1, "offset": i, "payload": "{}"}
+		if _, err := New(db).Table("events").InsertGetId(ctx, row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -3,8 +3,14 @@ package qix
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,7 +30,12 @@ type TxDB interface {
 // Builder represents the main query builder struct
 type Builder struct {
 	table               string
+	schema              string // Schema of unqualified tables, see InSchema
 	columns             []string
+	rawExpressions      map[string]bool // Columns and groups added by SelectRaw and GroupByRaw, never quoted
+	partitions          []string        // MySQL partition selection, see Partition
+	distinct            bool
+	distinctOn          []string // Postgres DISTINCT ON columns, see DistinctOn
 	wheres              []where
 	joins               []join
 	groups              []string
@@ -32,12 +43,45 @@ type Builder struct {
 	orders              []order
 	limit               *int
 	offset              *int
-	bindings            []interface{}
-	db                  DB // tambahkan field db
+	bindings            []interface{} // Bindings of WHERE conditions
+	valueBindings       []interface{} // Values of INSERT/UPDATE statements, see writeBindings
+	selectBindings      []interface{} // Bindings of sub-selects in the column list
+	fromBindings        []interface{} // Bindings of a FromSub source
+	joinBindings        []interface{} // Bindings of join conditions and joined subqueries
+	groupBindings       []interface{} // Bindings of raw GROUP BY expressions
+	havingBindings      []interface{} // Bindings of HAVING conditions
+	db                  DB            // tambahkan field db
 	unions              []union
 	beforeQueryHandlers []QueryEventHandler
 	afterQueryHandlers  []QueryEventHandler
-}
+	statement           statementType // Statement described by Insert/Update/Delete
+	metrics             *Metrics      // Optional execution counters
+	emptyInNoop         bool          // Ignore empty WhereIn/WhereNotIn instead of matching nothing/everything
+	inModels            []inModel     // WhereInModel subqueries, see WithMaterialized
+	materializeIn       bool          // Resolve WhereInModel subqueries client-side before executing
+	autoAlias           bool          // Name unaliased subqueries sq1, sq2, ...
+	aliasCount          int
+	strictIdentifiers   bool      // Panic on invalid table names instead of deferring the error
+	err                 error     // First error found while building, returned on execution
+	dialect             Dialect   // Placeholder style, nil renders ?
+	serverCancel        bool      // Kill cancelled MySQL queries on the server, see WithServerSideCancel
+	quoteIdentifiers    bool      // Quote table and column names through the dialect
+	lintLevel           LintLevel // Query lint reporting, see WithQueryLint
+	lock                lockMode  // Row lock of SELECT queries, see LockForUpdate
+	cursorKey           []byte    // Key cursors are encrypted with, see WithCursorSecret
+	txHooks             *txHooks  // Callbacks of the running transaction, see AfterCommit
+	ctes                []cte     // Common table expressions, see WithCTE
+}
+
+// statementType identifies the kind of statement a builder renders
+type statementType int
+
+const (
+	statementSelect statementType = iota
+	statementInsert
+	statementUpdate
+	statementDelete
+)
 
 // where represents a where clause condition
 type where struct {
@@ -46,6 +90,7 @@ type where struct {
 	value    interface{}
 	boolean  string
 	isColumn bool
+	isNull   bool // IS [NOT] NULL clause, rendered without a binding
 }
 
 type join struct {
@@ -59,16 +104,45 @@ type having struct {
 	operator string
 	value    interface{}
 	boolean  string
+	isNull   bool
+	isRaw    bool // column holds a raw expression, see HavingRaw
 }
 
 type order struct {
 	column    string
 	direction string
+	random    bool // Random order, see InRandomOrder
+	seed      int64
+	seeded    bool
+	raw       bool          // column holds a raw expression, see OrderByRaw
+	bindings  []interface{} // Bindings of a raw expression
+}
+
+// Option configures a Builder created with New
+type Option interface {
+	apply(*Builder)
+}
+
+// optionFunc adapts a function to the Option interface
+type optionFunc func(*Builder)
+
+func (f optionFunc) apply(b *Builder) {
+	f(b)
+}
+
+// WithEmptyInMatchesNothing controls how WhereIn and WhereNotIn treat an
+// empty value list. When enabled (the default) an empty WhereIn renders
+// "1 = 0" and an empty WhereNotIn renders "1 = 1". Disabling it restores
+// the legacy behavior where both calls are ignored.
+func WithEmptyInMatchesNothing(enabled bool) Option {
+	return optionFunc(func(b *Builder) {
+		b.emptyInNoop = !enabled
+	})
 }
 
 // New creates a new instance of query builder with database connection
-func New(db DB) *Builder {
-	return &Builder{
+func New(db DB, opts ...Option) *Builder {
+	b := &Builder{
 		columns:  make([]string, 0),
 		wheres:   make([]where, 0),
 		joins:    make([]join, 0),
@@ -78,11 +152,75 @@ func New(db DB) *Builder {
 		bindings: make([]interface{}, 0),
 		db:       db,
 	}
+	for _, opt := range opts {
+		opt.apply(b)
+	}
+	return b
+}
+
+// newQuery creates an empty builder sharing the connection and options of b
+func (b *Builder) newQuery() *Builder {
+	q := New(b.db)
+	q.emptyInNoop = b.emptyInNoop
+	q.metrics = b.metrics
+	q.autoAlias = b.autoAlias
+	q.strictIdentifiers = b.strictIdentifiers
+	q.dialect = b.dialect
+	q.serverCancel = b.serverCancel
+	q.quoteIdentifiers = b.quoteIdentifiers
+	q.lintLevel = b.lintLevel
+	q.cursorKey = b.cursorKey
+	q.schema = b.schema
+	q.txHooks = b.txHooks
+	return q
+}
+
+// Clone returns a copy of the builder that can be modified without
+// affecting the original. Unions are cloned too; the connection and
+// metrics are shared.
+func (b *Builder) Clone() *Builder {
+	c := *b
+	c.columns = append([]string{}, b.columns...)
+	c.wheres = append([]where{}, b.wheres...)
+	c.joins = append([]join{}, b.joins...)
+	c.groups = append([]string{}, b.groups...)
+	c.havings = append([]having{}, b.havings...)
+	c.orders = append([]order{}, b.orders...)
+	c.bindings = append([]interface{}{}, b.bindings...)
+	c.valueBindings = append([]interface{}(nil), b.valueBindings...)
+	c.selectBindings = append([]interface{}(nil), b.selectBindings...)
+	c.fromBindings = append([]interface{}(nil), b.fromBindings...)
+	c.joinBindings = append([]interface{}(nil), b.joinBindings...)
+	c.groupBindings = append([]interface{}(nil), b.groupBindings...)
+	c.havingBindings = append([]interface{}(nil), b.havingBindings...)
+	c.beforeQueryHandlers = append([]QueryEventHandler(nil), b.beforeQueryHandlers...)
+	c.afterQueryHandlers = append([]QueryEventHandler(nil), b.afterQueryHandlers...)
+	c.inModels = append([]inModel(nil), b.inModels...)
+	c.partitions = append([]string(nil), b.partitions...)
+	c.distinctOn = append([]string(nil), b.distinctOn...)
+	c.ctes = append([]cte(nil), b.ctes...)
+	c.rawExpressions = maps.Clone(b.rawExpressions)
+
+	if b.limit != nil {
+		limit := *b.limit
+		c.limit = &limit
+	}
+	if b.offset != nil {
+		offset := *b.offset
+		c.offset = &offset
+	}
+
+	c.unions = make([]union, len(b.unions))
+	for i, u := range b.unions {
+		c.unions[i] = union{query: u.query.Clone(), typ: u.typ}
+	}
+	return &c
 }
 
 // Table sets the table name for the query
 func (b *Builder) Table(name string) *Builder {
-	b.table = name
+	b.checkIdentifier(name)
+	b.table = qualifyTable(b.schema, name)
 	return b
 }
 
@@ -92,6 +230,33 @@ func (b *Builder) Select(columns ...string) *Builder {
 	return b
 }
 
+// Distinct makes the query return only distinct rows
+func (b *Builder) Distinct() *Builder {
+	b.distinct = true
+	return b
+}
+
+// ErrDistinctOnUnsupported is returned when DistinctOn is used with a
+// dialect other than Postgres
+var ErrDistinctOnUnsupported = errors.New("distinct on not supported by dialect")
+
+// DistinctOn keeps the first row of each group of equal columns values,
+// rendering Postgres SELECT DISTINCT ON (columns). Other dialects fail
+// with ErrDistinctOnUnsupported when the query is executed.
+func (b *Builder) DistinctOn(columns ...string) *Builder {
+	if _, ok := b.dialect.(postgresDialect); !ok {
+		b.setErr(ErrDistinctOnUnsupported)
+		return b
+	}
+	b.distinctOn = append(b.distinctOn, columns...)
+	return b
+}
+
+// SelectDistinct selects distinct values of the given columns
+func (b *Builder) SelectDistinct(columns ...string) *Builder {
+	return b.Distinct().Select(columns...)
+}
+
 // Where adds a where clause to the query
 func (b *Builder) Where(column string, operator string, value interface{}) *Builder {
 	b.wheres = append(b.wheres, where{
@@ -100,12 +265,13 @@ func (b *Builder) Where(column string, operator string, value interface{}) *Buil
 		value:    value,
 		boolean:  "AND",
 	})
-	b.bindings = append(b.bindings, value)
+	b.bindings = append(b.bindings, b.bind(column, value))
 	return b
 }
 
 // Join adds a JOIN clause to the query
 func (b *Builder) Join(table string, condition string) *Builder {
+	b.checkIdentifier(table)
 	b.joins = append(b.joins, join{
 		table:     table,
 		condition: condition,
@@ -116,6 +282,7 @@ func (b *Builder) Join(table string, condition string) *Builder {
 
 // LeftJoin adds a LEFT JOIN clause to the query
 func (b *Builder) LeftJoin(table string, condition string) *Builder {
+	b.checkIdentifier(table)
 	b.joins = append(b.joins, join{
 		table:     table,
 		condition: condition,
@@ -130,6 +297,16 @@ func (b *Builder) GroupBy(columns ...string) *Builder {
 	return b
 }
 
+// GroupByRaw adds a raw GROUP BY expression, such as DATE(created_at) or
+// CASE WHEN ... END, in call order with GroupBy. It is never quoted; its
+// bindings come after those of the WHERE clause.
+func (b *Builder) GroupByRaw(expression string, bindings ...interface{}) *Builder {
+	b.markRaw(expression)
+	b.groupBindings = append(b.groupBindings, bindings...)
+	b.groups = append(b.groups, expression)
+	return b
+}
+
 // Having adds HAVING clause to the query
 func (b *Builder) Having(column string, operator string, value interface{}) *Builder {
 	b.havings = append(b.havings, having{
@@ -138,7 +315,28 @@ func (b *Builder) Having(column string, operator string, value interface{}) *Bui
 		value:    value,
 		boolean:  "AND",
 	})
-	b.bindings = append(b.bindings, value)
+	b.havingBindings = append(b.havingBindings, value)
+	return b
+}
+
+// HavingRaw adds a raw HAVING expression, such as SUM(total) > ?, written
+// as is and combined with AND
+func (b *Builder) HavingRaw(expression string, bindings ...interface{}) *Builder {
+	return b.havingRaw(expression, "AND", bindings)
+}
+
+// OrHavingRaw adds a raw HAVING expression combined with OR
+func (b *Builder) OrHavingRaw(expression string, bindings ...interface{}) *Builder {
+	return b.havingRaw(expression, "OR", bindings)
+}
+
+func (b *Builder) havingRaw(expression, boolean string, bindings []interface{}) *Builder {
+	b.havings = append(b.havings, having{
+		column:  expression,
+		boolean: boolean,
+		isRaw:   true,
+	})
+	b.havingBindings = append(b.havingBindings, bindings...)
 	return b
 }
 
@@ -151,6 +349,70 @@ func (b *Builder) OrderBy(column string, direction string) *Builder {
 	return b
 }
 
+// OrderByAsc adds an ascending ORDER BY on column
+func (b *Builder) OrderByAsc(column string) *Builder {
+	return b.OrderBy(column, "ASC")
+}
+
+// OrderByDesc adds a descending ORDER BY on column
+func (b *Builder) OrderByDesc(column string) *Builder {
+	return b.OrderBy(column, "DESC")
+}
+
+// Latest orders by column newest first, created_at when no column is given
+func (b *Builder) Latest(column ...string) *Builder {
+	return b.OrderByDesc(timestampColumn(column))
+}
+
+// Oldest orders by column oldest first, created_at when no column is given
+func (b *Builder) Oldest(column ...string) *Builder {
+	return b.OrderByAsc(timestampColumn(column))
+}
+
+// timestampColumn returns the optional column of Latest and Oldest
+func timestampColumn(column []string) string {
+	if len(column) > 0 {
+		return column[0]
+	}
+	return "created_at"
+}
+
+// OrderByRaw adds a raw ORDER BY expression, such as
+// FIELD(status, ?, ?) or CASE WHEN ... END DESC, in call order with OrderBy
+func (b *Builder) OrderByRaw(sql string, bindings ...interface{}) *Builder {
+	b.orders = append(b.orders, order{
+		column:   sql,
+		raw:      true,
+		bindings: bindings,
+	})
+	return b
+}
+
+// ErrRandomSeedUnsupported is returned when a seeded random order is used
+// with SQLite, which can't seed RANDOM()
+var ErrRandomSeedUnsupported = errors.New("seeded random order not supported by dialect")
+
+// InRandomOrder orders rows randomly, rendering RAND() for MySQL and
+// RANDOM() for Postgres and SQLite. With a seed paginated queries with the
+// same seed return the same order on every page: MySQL renders RAND(seed)
+// and Postgres calls setseed before RANDOM(). SQLite has no seeded random
+// order, a seed fails with ErrRandomSeedUnsupported when the query runs.
+// Calling it again replaces the previous random order.
+func (b *Builder) InRandomOrder(seed ...int64) *Builder {
+	o := order{random: true}
+	if len(seed) > 0 {
+		o.seed, o.seeded = seed[0], true
+	}
+	for i := range b.orders {
+		if b.orders[i].random {
+			b.orders[i] = o
+			return b
+		}
+	}
+	b.orders = append(b.orders, o)
+	return b
+}
+
 // Limit sets the LIMIT clause
 func (b *Builder) Limit(limit int) *Builder {
 	b.limit = &limit
@@ -164,65 +426,178 @@ func (b *Builder) Offset(offset int) *Builder {
 }
 
 // Aggregate functions
+
+// Count selects COUNT(column). After Distinct() without selected columns,
+// Count of a column renders COUNT(DISTINCT column). Other DISTINCT queries
+// are counted from a subquery: SELECT COUNT(column) FROM (SELECT DISTINCT ...) AS sub.
+// It adds to the selected columns; prefer CountValue to run a count.
 func (b *Builder) Count(column string) *Builder {
+	if b.distinct && len(b.distinctOn) == 0 && len(b.columns) == 0 && column != "*" {
+		b.distinct = false
+		return b.Select("COUNT(DISTINCT " + b.quote(column) + ")")
+	}
+	if b.distinct || len(b.distinctOn) > 0 {
+		sub := b.Clone()
+		b.distinct = false
+		b.distinctOn = nil
+		b.columns = make([]string, 0)
+		b.wheres = make([]where, 0)
+		b.joins = make([]join, 0)
+		b.groups = make([]string, 0)
+		b.havings = make([]having, 0)
+		b.orders = make([]order, 0)
+		b.limit, b.offset = nil, nil
+		b.bindings = make([]interface{}, 0)
+		b.selectBindings, b.joinBindings, b.groupBindings, b.havingBindings = nil, nil, nil, nil
+		b.unions = nil
+		b.partitions = nil
+		b.FromSub(sub, "sub")
+	}
 	return b.Select("COUNT(" + column + ")")
 }
 
+// Max selects MAX(column) next to the selected columns; prefer MaxValue to run it
 func (b *Builder) Max(column string) *Builder {
 	return b.Select("MAX(" + column + ")")
 }
 
+// Min selects MIN(column) next to the selected columns; prefer MinValue to run it
 func (b *Builder) Min(column string) *Builder {
 	return b.Select("MIN(" + column + ")")
 }
 
+// Avg selects AVG(column) next to the selected columns; prefer AvgValue to run it
 func (b *Builder) Avg(column string) *Builder {
 	return b.Select("AVG(" + column + ")")
 }
 
+// Sum selects SUM(column) next to the selected columns; prefer SumValue to run it
 func (b *Builder) Sum(column string) *Builder {
 	return b.Select("SUM(" + column + ")")
 }
 
 // Insert operation
+// Columns are sorted by name so the binding order is stable between calls
 func (b *Builder) Insert(data map[string]interface{}) *Builder {
-	columns := make([]string, 0, len(data))
+	columns := sortedKeys(data)
 
-	for column, value := range data {
-		columns = append(columns, column)
-		b.bindings = append(b.bindings, value)
+	b.valueBindings = make([]interface{}, len(columns))
+	for i, column := range columns {
+		b.valueBindings[i] = b.bind(column, data[column])
 	}
 
 	b.columns = columns
+	b.statement = statementInsert
 	return b
 }
 
 // Update operation
+// Columns are sorted by name, like Insert
 func (b *Builder) Update(data map[string]interface{}) *Builder {
-	for column, value := range data {
+	for _, column := range sortedKeys(data) {
 		b.columns = append(b.columns, column)
-		b.bindings = append(b.bindings, value)
+		b.valueBindings = append(b.valueBindings, b.bind(column, data[column]))
 	}
+	b.statement = statementUpdate
 	return b
 }
 
 // Delete operation
 func (b *Builder) Delete() *Builder {
+	b.statement = statementDelete
 	return b
 }
 
-// SubSelect adds a subquery
+// sortedKeys returns the keys of a column map in a deterministic order
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SelectRaw adds an expression to the column list as written, such as
+// COALESCE(nickname, name) AS label or ROW_NUMBER() OVER (...). It is never
+// quoted, so it must not contain user input; pass values as bindings, they
+// are bound before those of the rest of the query.
+func (b *Builder) SelectRaw(expression string, bindings ...interface{}) *Builder {
+	b.markRaw(expression)
+	b.selectBindings = append(b.selectBindings, bindings...)
+	return b.Select(expression)
+}
+
+// markRaw records an expression that is rendered without quoting
+func (b *Builder) markRaw(expression string) {
+	if b.rawExpressions == nil {
+		b.rawExpressions = make(map[string]bool)
+	}
+	b.rawExpressions[expression] = true
+}
+
+// AddSelectRaw is SelectRaw, the columns already selected are kept
+func (b *Builder) AddSelectRaw(expression string, bindings ...interface{}) *Builder {
+	return b.SelectRaw(expression, bindings...)
+}
+
+// SubSelect adds a subquery to the column list
 func (b *Builder) SubSelect(subQuery *Builder, alias string) *Builder {
-	// Implementation for subquery will need more complex logic
-	// This is a basic implementation
-	return b.Select("(" + subQuery.ToSQL() + ") as " + alias)
+	b.selectBindings = append(b.selectBindings, subQuery.GetBindings()...)
+	return b.Select("(" + subQuery.toSQL() + ")" + b.aliasSQL(alias, " as "))
+}
+
+// FromSub uses a subquery as the source of the query
+func (b *Builder) FromSub(subQuery *Builder, alias string) *Builder {
+	b.table = "(" + subQuery.toSQL() + ")" + b.aliasSQL(alias, " AS ")
+	b.fromBindings = subQuery.GetBindings()
+	return b
+}
+
+// AutoAlias names subqueries passed without an alias sq1, sq2, ... in call order
+func (b *Builder) AutoAlias() *Builder {
+	b.autoAlias = true
+	return b
 }
 
-// ToSQL converts the query builder to SQL string
+// aliasSQL renders the alias of a subquery, generating one when AutoAlias is on
+func (b *Builder) aliasSQL(alias string, keyword string) string {
+	if alias == "" && b.autoAlias {
+		b.aliasCount++
+		alias = fmt.Sprintf("sq%d", b.aliasCount)
+	}
+	if alias == "" {
+		return ""
+	}
+	return keyword + alias
+}
+
+// ToSQL converts the query builder to SQL string, with placeholders in
+// the style of the builder's dialect
 func (b *Builder) ToSQL() string {
+	return b.rebind(b.toSQL())
+}
+
+// ToSQLWithBindings returns the SQL of ToSQL together with its bindings in
+// placeholder order. Rendering never changes the builder, so it is safe to
+// call repeatedly before executing the query.
+func (b *Builder) ToSQLWithBindings() (string, []interface{}) {
+	query, bindings := b.toSQLWithBindings()
+	return b.rebind(query), bindings
+}
+
+// toSQLWithBindings is ToSQLWithBindings with ? placeholders
+func (b *Builder) toSQLWithBindings() (string, []interface{}) {
+	return b.toSQL(), b.GetBindings()
+}
+
+// toSQL renders the query with ? placeholders so it can be embedded in
+// another query before the placeholders are numbered
+func (b *Builder) toSQL() string {
 	var query strings.Builder
 
 	// Build base query
+	query.WriteString(b.cteSQL())
 	query.WriteString(b.buildBaseQuery())
 
 	// Add UNION clauses
@@ -233,7 +608,6 @@ func (b *Builder) ToSQL() string {
 			query.WriteString(" UNION ")
 		}
 		query.WriteString(union.query.buildBaseQuery())
-		b.bindings = append(b.bindings, union.query.bindings...)
 	}
 
 	return query.String()
@@ -244,17 +618,22 @@ func (b *Builder) buildBaseQuery() string {
 	var query strings.Builder
 
 	// Build SELECT clause
+	query.WriteString("SELECT ")
+	if len(b.distinctOn) > 0 {
+		query.WriteString("DISTINCT ON (" + strings.Join(b.quoteAll(b.distinctOn), ", ") + ") ")
+	} else if b.distinct {
+		query.WriteString("DISTINCT ")
+	}
 	if len(b.columns) > 0 {
-		query.WriteString("SELECT ")
-		query.WriteString(strings.Join(b.columns, ", "))
+		query.WriteString(strings.Join(b.quoteExpressions(b.columns), ", "))
 	} else {
-		query.WriteString("SELECT *")
+		query.WriteString("*")
 	}
 
 	// Add FROM clause
 	if b.table != "" {
 		query.WriteString(" FROM ")
-		query.WriteString(b.table)
+		query.WriteString(b.fromSQL())
 	}
 
 	// Add JOINs
@@ -262,10 +641,10 @@ func (b *Builder) buildBaseQuery() string {
 		query.WriteString(" ")
 		query.WriteString(join.joinType)
 		query.WriteString(" JOIN ")
-		query.WriteString(join.table)
+		query.WriteString(b.quote(join.table))
 		if join.condition != "" {
 			query.WriteString(" ON ")
-			query.WriteString(join.condition)
+			query.WriteString(b.quoteCondition(join.condition))
 		}
 	}
 
@@ -278,7 +657,7 @@ func (b *Builder) buildBaseQuery() string {
 	// Add GROUP BY
 	if len(b.groups) > 0 {
 		query.WriteString(" GROUP BY ")
-		query.WriteString(strings.Join(b.groups, ", "))
+		query.WriteString(strings.Join(b.quoteExpressions(b.groups), ", "))
 	}
 
 	// Add HAVING
@@ -290,121 +669,300 @@ func (b *Builder) buildBaseQuery() string {
 				query.WriteString(having.boolean)
 				query.WriteString(" ")
 			}
-			query.WriteString(having.column)
+			if having.isRaw {
+				query.WriteString(having.column)
+				continue
+			}
+			query.WriteString(b.quote(having.column))
 			query.WriteString(" ")
 			query.WriteString(having.operator)
-			query.WriteString(" ?")
+			if having.isNull {
+				query.WriteString(" NULL")
+			} else {
+				query.WriteString(" ?")
+			}
 		}
 	}
 
 	// Add ORDER BY
-	if len(b.orders) > 0 {
-		query.WriteString(" ORDER BY ")
-		orderClauses := make([]string, len(b.orders))
-		for i, order := range b.orders {
-			orderClauses[i] = order.column + " " + order.direction
-		}
-		query.WriteString(strings.Join(orderClauses, ", "))
-	}
+	query.WriteString(b.orderBySQL())
 
 	// Add LIMIT and OFFSET
-	if b.limit != nil {
-		query.WriteString(" LIMIT ?")
-		b.bindings = append(b.bindings, *b.limit)
-	}
-	if b.offset != nil {
-		query.WriteString(" OFFSET ?")
-		b.bindings = append(b.bindings, *b.offset)
-	}
+	query.WriteString(b.limitClause())
+
+	query.WriteString(b.lockClause())
 
 	return query.String()
 }
 
-// WhereIn adds a WHERE IN clause to the query
-func (b *Builder) WhereIn(column string, values ...interface{}) *Builder {
-	if len(values) == 0 {
-		return b
+// quoteExpressions quotes refs like quoteAll, except raw expressions
+func (b *Builder) quoteExpressions(refs []string) []string {
+	if !b.quoteIdentifiers || len(b.rawExpressions) == 0 {
+		return b.quoteAll(refs)
 	}
+	quoted := make([]string, len(refs))
+	for i, ref := range refs {
+		if b.rawExpressions[ref] {
+			quoted[i] = ref
+		} else {
+			quoted[i] = b.quote(ref)
+		}
+	}
+	return quoted
+}
 
-	// Create placeholders array
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholders[i] = "?"
-		b.bindings = append(b.bindings, values[i])
+// orderBySQL renders the ORDER BY clause, or nothing without orders
+func (b *Builder) orderBySQL() string {
+	if len(b.orders) == 0 {
+		return ""
 	}
+	orderClauses := make([]string, len(b.orders))
+	for i, order := range b.orders {
+		if order.random {
+			orderClauses[i] = b.randomOrder(order)
+			continue
+		}
+		if order.raw {
+			orderClauses[i] = order.column
+			continue
+		}
+		orderClauses[i] = b.quote(order.column) + " " + order.direction
+	}
+	return " ORDER BY " + strings.Join(orderClauses, ", ")
+}
 
-	// Create the where clause with the correct parentheses treatment
-	whereClause := where{
-		column:   column,
-		operator: "IN",
-		value:    strings.Join(placeholders, ", "), // Remove parentheses here
-		boolean:  "AND",
+// randomOrder renders a random order for the dialect
+func (b *Builder) randomOrder(o order) string {
+	switch b.dialect.(type) {
+	case postgresDialect:
+		if o.seeded {
+			// The uncorrelated subquery runs once, before the first RANDOM()
+			return "(SELECT setseed(" + postgresSeed(o.seed) + ")::text), RANDOM()"
+		}
+		return "RANDOM()"
+	case sqliteDialect:
+		if o.seeded {
+			b.setErr(fmt.Errorf("%w: RANDOM(%d)", ErrRandomSeedUnsupported, o.seed))
+		}
+		return "RANDOM()"
+	}
+	if o.seeded {
+		return fmt.Sprintf("RAND(%d)", o.seed)
 	}
+	return "RAND()"
+}
 
-	// Add to wheres slice
-	b.wheres = append(b.wheres, whereClause)
-	return b
+// postgresSeed maps seed to the [-1, 1) range setseed accepts, distinct
+// for seeds within 32 bits
+func postgresSeed(seed int64) string {
+	return strconv.FormatFloat(math.Mod(float64(seed), 1<<31)/(1<<31), 'g', -1, 64)
 }
 
-// WhereNotIn adds a WHERE NOT IN clause to the query
-func (b *Builder) WhereNotIn(column string, values ...interface{}) *Builder {
-	if len(values) == 0 {
-		return b
+// orderBindings returns the bindings of raw ORDER BY expressions
+func (b *Builder) orderBindings() []interface{} {
+	var bindings []interface{}
+	for _, order := range b.orders {
+		bindings = append(bindings, order.bindings...)
 	}
+	return bindings
+}
+
+// WhereIn adds a WHERE IN clause to the query.
+// An empty value list matches no rows, see WithEmptyInMatchesNothing.
+func (b *Builder) WhereIn(column string, values ...interface{}) *Builder {
+	return b.whereIn(column, "IN", "AND", values)
+}
+
+// OrWhereIn adds an OR WHERE IN clause to the query, with the empty list
+// semantics of WhereIn
+func (b *Builder) OrWhereIn(column string, values ...interface{}) *Builder {
+	return b.whereIn(column, "IN", "OR", values)
+}
+
+// WhereNotIn adds a WHERE NOT IN clause to the query.
+// An empty value list matches every row, see WithEmptyInMatchesNothing.
+func (b *Builder) WhereNotIn(column string, values ...interface{}) *Builder {
+	return b.whereIn(column, "NOT IN", "AND", notInValues(values))
+}
 
-	// Handle array/slice value
+// OrWhereNotIn adds an OR WHERE NOT IN clause to the query, with the empty
+// list semantics of WhereNotIn
+func (b *Builder) OrWhereNotIn(column string, values ...interface{}) *Builder {
+	return b.whereIn(column, "NOT IN", "OR", notInValues(values))
+}
+
+// notInValues unwraps a single []interface{} argument of WhereNotIn
+func notInValues(values []interface{}) []interface{} {
 	if len(values) == 1 {
 		if arr, ok := values[0].([]interface{}); ok {
-			values = arr
+			return arr
 		}
 	}
+	return values
+}
 
+func (b *Builder) whereIn(column, operator, boolean string, values []interface{}) *Builder {
+	if len(values) == 0 {
+		if b.emptyInNoop {
+			return b
+		}
+		// IN () never matches, NOT IN () always does
+		if operator == "IN" {
+			return b.whereRaw("1 = 0", boolean, nil)
+		}
+		return b.whereRaw("1 = 1", boolean, nil)
+	}
+
+	// Create placeholders array
 	placeholders := make([]string, len(values))
 	for i := range values {
 		placeholders[i] = "?"
-		b.bindings = append(b.bindings, values[i])
+		b.bindings = append(b.bindings, b.bind(column, values[i]))
 	}
 
 	b.wheres = append(b.wheres, where{
 		column:   column,
-		operator: "NOT IN",
+		operator: operator,
 		value:    strings.Join(placeholders, ", "),
+		boolean:  boolean,
+	})
+	return b
+}
+
+// WhereIntegerInRaw adds a WHERE IN clause with the ids inlined as
+// literals instead of placeholders, for large trusted lists that would
+// exceed the driver's parameter limit. Being int64 values they can't carry
+// SQL. Empty lists follow WithEmptyInMatchesNothing like WhereIn.
+func (b *Builder) WhereIntegerInRaw(column string, ids []int64) *Builder {
+	if len(ids) == 0 {
+		if b.emptyInNoop {
+			return b
+		}
+		return b.WhereFalse()
+	}
+	b.wheres = append(b.wheres, where{
+		column:   column,
+		operator: "IN",
+		value:    integerList(ids),
 		boolean:  "AND",
 	})
 	return b
 }
 
-// WhereNull adds a WHERE IS NULL clause to the query
-func (b *Builder) WhereNull(column string) *Builder {
+// WhereIntegerNotInRaw is the NOT IN counterpart of WhereIntegerInRaw, an
+// empty list matches every row like WhereNotIn
+func (b *Builder) WhereIntegerNotInRaw(column string, ids []int64) *Builder {
+	if len(ids) == 0 {
+		if b.emptyInNoop {
+			return b
+		}
+		return b.WhereTrue()
+	}
 	b.wheres = append(b.wheres, where{
 		column:   column,
-		operator: "IS",
-		value:    "NULL",
+		operator: "NOT IN",
+		value:    integerList(ids),
 		boolean:  "AND",
 	})
 	return b
 }
 
+// integerList renders ids as a comma separated list of literals
+func integerList(ids []int64) string {
+	buf := make([]byte, 0, len(ids)*8)
+	for i, id := range ids {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = strconv.AppendInt(buf, id, 10)
+	}
+	return string(buf)
+}
+
+// WhereFalse adds a condition that never matches
+func (b *Builder) WhereFalse() *Builder {
+	return b.WhereRaw("1 = 0")
+}
+
+// WhereTrue adds a condition that always matches
+func (b *Builder) WhereTrue() *Builder {
+	return b.WhereRaw("1 = 1")
+}
+
+// WhereNull adds a WHERE IS NULL clause to the query
+func (b *Builder) WhereNull(column string) *Builder {
+	return b.WhereNullWithBoolean(column, "AND", false)
+}
+
 // WhereNotNull adds a WHERE IS NOT NULL clause to the query
 func (b *Builder) WhereNotNull(column string) *Builder {
+	return b.WhereNullWithBoolean(column, "AND", true)
+}
+
+// OrWhereNull adds an OR WHERE IS NULL clause to the query
+func (b *Builder) OrWhereNull(column string) *Builder {
+	return b.WhereNullWithBoolean(column, "OR", false)
+}
+
+// OrWhereNotNull adds an OR WHERE IS NOT NULL clause to the query
+func (b *Builder) OrWhereNotNull(column string) *Builder {
+	return b.WhereNullWithBoolean(column, "OR", true)
+}
+
+// WhereNullWithBoolean adds an IS NULL (or IS NOT NULL when not is true)
+// clause joined to the previous conditions with boolean ("AND" or "OR").
+// Null clauses never add bindings.
+func (b *Builder) WhereNullWithBoolean(column string, boolean string, not bool) *Builder {
+	operator := "IS"
+	if not {
+		operator = "IS NOT"
+	}
+
 	b.wheres = append(b.wheres, where{
 		column:   column,
-		operator: "IS NOT",
-		value:    "NULL",
-		boolean:  "AND",
+		operator: operator,
+		boolean:  normalizeBoolean(boolean),
+		isNull:   true,
 	})
 	return b
 }
 
+// normalizeBoolean returns "OR" for any casing of "or" and "AND" otherwise
+func normalizeBoolean(boolean string) string {
+	if strings.EqualFold(strings.TrimSpace(boolean), "OR") {
+		return "OR"
+	}
+	return "AND"
+}
+
 // WhereBetween adds a WHERE BETWEEN clause to the query
 func (b *Builder) WhereBetween(column string, start, end interface{}) *Builder {
+	return b.whereBetween(column, "BETWEEN", "AND", start, end)
+}
+
+// WhereNotBetween adds a WHERE NOT BETWEEN clause to the query
+func (b *Builder) WhereNotBetween(column string, start, end interface{}) *Builder {
+	return b.whereBetween(column, "NOT BETWEEN", "AND", start, end)
+}
+
+// OrWhereBetween adds an OR WHERE BETWEEN clause to the query
+func (b *Builder) OrWhereBetween(column string, start, end interface{}) *Builder {
+	return b.whereBetween(column, "BETWEEN", "OR", start, end)
+}
+
+// OrWhereNotBetween adds an OR WHERE NOT BETWEEN clause to the query
+func (b *Builder) OrWhereNotBetween(column string, start, end interface{}) *Builder {
+	return b.whereBetween(column, "NOT BETWEEN", "OR", start, end)
+}
+
+func (b *Builder) whereBetween(column, operator, boolean string, start, end interface{}) *Builder {
 	b.wheres = append(b.wheres, where{
 		column:   column,
-		operator: "BETWEEN",
+		operator: operator,
 		value:    "? AND ?",
-		boolean:  "AND",
+		boolean:  boolean,
 	})
-	b.bindings = append(b.bindings, start, end)
+	b.bindings = append(b.bindings, b.bind(column, start), b.bind(column, end))
 	return b
 }
 
@@ -416,7 +974,7 @@ func (b *Builder) OrWhere(column string, operator string, value interface{}) *Bu
 		value:    value,
 		boolean:  "OR",
 	})
-	b.bindings = append(b.bindings, value)
+	b.bindings = append(b.bindings, b.bind(column, value))
 	return b
 }
 
@@ -428,7 +986,7 @@ func (b *Builder) WhereDate(column string, operator string, value interface{}) *
 		value:    value,
 		boolean:  "AND",
 	})
-	b.bindings = append(b.bindings, value)
+	b.bindings = append(b.bindings, b.bind(column, value))
 	return b
 }
 
@@ -440,7 +998,7 @@ func (b *Builder) WhereYear(column string, operator string, value interface{}) *
 		value:    value,
 		boolean:  "AND",
 	})
-	b.bindings = append(b.bindings, value)
+	b.bindings = append(b.bindings, b.bind(column, value))
 	return b
 }
 
@@ -506,7 +1064,7 @@ func (b *Builder) WhereDay(column string, operator string, value interface{}) *B
 		value:    value,
 		boolean:  "AND",
 	})
-	b.bindings = append(b.bindings, value)
+	b.bindings = append(b.bindings, b.bind(column, value))
 	return b
 }
 
@@ -536,32 +1094,73 @@ func (b *Builder) OrWhereColumn(column1 string, operator string, column2 string)
 
 // Get executes the SELECT query and returns the rows
 func (b *Builder) Get(ctx context.Context) (*sql.Rows, error) {
-	query := b.ToSQL()
-	return b.db.QueryContext(ctx, query, b.bindings...)
+	if err := b.materializeInModels(ctx); err != nil {
+		return nil, err
+	}
+	query, bindings := b.toSQLWithBindings()
+	return b.queryContext(ctx, query, bindings...)
 }
 
 // First executes the SELECT query and returns the first row
 func (b *Builder) First(ctx context.Context) (*sql.Rows, error) {
+	if err := b.materializeInModels(ctx); err != nil {
+		return nil, err
+	}
 	b.Limit(1)
-	query := b.ToSQL()
-	return b.db.QueryContext(ctx, query, b.bindings...)
-}
+	query, bindings := b.toSQLWithBindings()
+	return b.queryContext(ctx, query, bindings...)
+}
+
+// Exists reports whether the query matches at least one row, running
+// SELECT EXISTS(SELECT 1 ... LIMIT 1). With unions the selected columns
+// are kept for every branch to match and no limit is added. The builder
+// itself is left unchanged.
+func (b *Builder) Exists(ctx context.Context) (bool, error) {
+	sub := b.Clone()
+	if err := sub.materializeInModels(ctx); err != nil {
+		return false, err
+	}
+	sub.lock = lockNone
+	if len(sub.unions) == 0 {
+		sub.columns = []string{"1"}
+		sub.selectBindings = nil
+		sub.Limit(1)
+	}
 
-// InsertGetId executes the INSERT query and returns the last inserted ID
-func (b *Builder) InsertGetId(ctx context.Context, data map[string]interface{}) (int64, error) {
-	b.Insert(data)
+	query, bindings := sub.toSQLWithBindings()
+	rows, err := sub.queryContext(ctx, "SELECT EXISTS("+query+")", bindings...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
 
-	columns := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
+	var exists bool
+	if rows.Next() {
+		if err := rows.Scan(&exists); err != nil {
+			return false, err
+		}
+	}
+	return exists, rows.Err()
+}
 
-	for column := range data {
-		columns = append(columns, column)
-		placeholders = append(placeholders, "?")
+// DoesntExist reports whether the query matches no rows
+func (b *Builder) DoesntExist(ctx context.Context) (bool, error) {
+	exists, err := b.Exists(ctx)
+	if err != nil {
+		return false, err
 	}
+	return !exists, nil
+}
 
-	query := "INSERT INTO " + b.table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+// InsertGetId executes the INSERT query and returns the last inserted ID
+func (b *Builder) InsertGetId(ctx context.Context, data map[string]interface{}) (int64, error) {
+	// Render from the column list Insert bound the values in
+	query, err := b.Insert(data).statementSQL()
+	if err != nil {
+		return 0, err
+	}
 
-	result, err := b.db.ExecContext(ctx, query, b.bindings...)
+	result, err := b.execContext(ctx, query, b.writeBindings()...)
 	if err != nil {
 		return 0, err
 	}
@@ -571,36 +1170,148 @@ func (b *Builder) InsertGetId(ctx context.Context, data map[string]interface{})
 
 // UpdateWithContext executes the UPDATE query with context
 func (b *Builder) UpdateWithContext(ctx context.Context, data map[string]interface{}) (int64, error) {
-	b.Update(data)
+	// Render from the column list Update bound the values in
+	query, err := b.Update(data).statementSQL()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := b.execContext(ctx, query, b.writeBindings()...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Increment atomically adds amount, 1 by default, to column of the
+// matching rows: UPDATE t SET column = column + ? WHERE ... Further columns
+// to set can be passed as a map[string]interface{} after the amount, e.g.
+// Increment(ctx, "views", 1, map[string]interface{}{"updated_at": now}).
+// It returns the number of affected rows.
+func (b *Builder) Increment(ctx context.Context, column string, amount ...interface{}) (int64, error) {
+	return b.step(ctx, column, "+", amount)
+}
+
+// Decrement atomically subtracts amount, 1 by default, from column like
+// Increment
+func (b *Builder) Decrement(ctx context.Context, column string, amount ...interface{}) (int64, error) {
+	return b.step(ctx, column, "-", amount)
+}
+
+// step runs the UPDATE of Increment and Decrement
+func (b *Builder) step(ctx context.Context, column, operator string, args []interface{}) (int64, error) {
+	if b.table == "" {
+		return 0, errors.New("table name is required")
+	}
+
+	var amount interface{} = 1
+	var extra map[string]interface{}
+	if n := len(args); n > 0 {
+		if m, ok := args[n-1].(map[string]interface{}); ok {
+			extra, args = m, args[:n-1]
+		}
+	}
+	if len(args) > 1 {
+		return 0, errors.New("increment takes an amount and a map of extra columns")
+	}
+	if len(args) == 1 {
+		amount = args[0]
+	}
 
-	sets := make([]string, 0, len(data))
-	for column := range data {
-		sets = append(sets, column+" = ?")
+	if err := b.materializeInModels(ctx); err != nil {
+		return 0, err
 	}
 
-	query := "UPDATE " + b.table + " SET " + strings.Join(sets, ", ")
+	quoted := b.quote(column)
+	sets := []string{quoted + " = " + quoted + " " + operator + " ?"}
+	bindings := []interface{}{b.bind(column, amount)}
+	for _, col := range sortedKeys(extra) {
+		sets = append(sets, b.quote(col)+" = ?")
+		bindings = append(bindings, b.bind(col, extra[col]))
+	}
 
+	query := "UPDATE " + b.quote(b.table) + " SET " + strings.Join(sets, ", ")
 	if len(b.wheres) > 0 {
 		query += " WHERE " + b.whereSQL()
 	}
-
-	result, err := b.db.ExecContext(ctx, query, b.bindings...)
+	result, err := b.execContext(ctx, query, append(bindings, b.bindings...)...)
 	if err != nil {
 		return 0, err
 	}
-
 	return result.RowsAffected()
 }
 
-// DeleteWithContext executes the DELETE query with context
-func (b *Builder) DeleteWithContext(ctx context.Context) (int64, error) {
-	query := "DELETE FROM " + b.table
+// writeBindings returns the bindings of the INSERT/UPDATE statement in
+// placeholder order: the SET values before the WHERE bindings, whatever
+// order Where and Update were called in. INSERT has no WHERE clause.
+func (b *Builder) writeBindings() []interface{} {
+	bindings := make([]interface{}, 0, len(b.valueBindings)+len(b.bindings))
+	bindings = append(bindings, b.valueBindings...)
+	if b.statement == statementInsert {
+		return bindings
+	}
+	return append(bindings, b.bindings...)
+}
 
-	if len(b.wheres) > 0 {
-		query += " WHERE " + b.whereSQL()
+// queryContext runs a query on the builder's connection and records metrics
+func (b *Builder) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if b.err != nil {
+		return nil, b.err
 	}
+	if err := b.lint(); err != nil {
+		return nil, err
+	}
+	query = b.rebind(query)
+	event := b.beforeQuery(query, args)
+	stop := b.watchCancel(ctx)
+	start := time.Now()
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	elapsed := time.Since(start)
+	if cancelErr := stop(err); cancelErr != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		rows, err = nil, cancelErr
+	}
+	b.metrics.record(elapsed, err)
+	b.afterQuery(event, elapsed, err)
+	observeN1(event)
+	return rows, err
+}
 
-	result, err := b.db.ExecContext(ctx, query, b.bindings...)
+// execContext runs a statement on the builder's connection and records metrics
+func (b *Builder) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.lint(); err != nil {
+		return nil, err
+	}
+	query = b.rebind(query)
+	event := b.beforeQuery(query, args)
+	stop := b.watchCancel(ctx)
+	start := time.Now()
+	result, err := b.db.ExecContext(ctx, query, args...)
+	elapsed := time.Since(start)
+	if cancelErr := stop(err); cancelErr != nil {
+		result, err = nil, cancelErr
+	}
+	b.metrics.record(elapsed, err)
+	b.afterQuery(event, elapsed, err)
+	if err == nil && b.table != "" {
+		InvalidateCountCache(b.table)
+	}
+	return result, err
+}
+
+// DeleteWithContext executes the DELETE query with context
+func (b *Builder) DeleteWithContext(ctx context.Context) (int64, error) {
+	if err := b.materializeInModels(ctx); err != nil {
+		return 0, err
+	}
+	query, bindings := b.deleteSQL()
+	result, err := b.execContext(ctx, query, bindings...)
 	if err != nil {
 		return 0, err
 	}
@@ -608,6 +1319,33 @@ func (b *Builder) DeleteWithContext(ctx context.Context) (int64, error) {
 	return result.RowsAffected()
 }
 
+// deleteSQL renders the DELETE statement and its bindings. MySQL takes the
+// ORDER BY and LIMIT directly, other dialects cannot limit a DELETE so the
+// rows are picked by a limited subquery on the row identifier.
+func (b *Builder) deleteSQL() (string, []interface{}) {
+	var where string
+	if len(b.wheres) > 0 {
+		where = " WHERE " + b.whereSQL()
+	}
+	if b.limit == nil {
+		return "DELETE FROM " + b.fromSQL() + where, b.bindings
+	}
+
+	bindings := append(append(append([]interface{}(nil), b.bindings...), b.orderBindings()...), *b.limit)
+	switch b.dialect.(type) {
+	case nil, mysqlDialect:
+		return "DELETE FROM " + b.fromSQL() + where + b.orderBySQL() + " LIMIT ?", bindings
+	}
+
+	rowID := "ctid"
+	if _, ok := b.dialect.(sqliteDialect); ok {
+		rowID = "rowid"
+	}
+	table := b.quote(b.table)
+	return "DELETE FROM " + table + " WHERE " + rowID + " IN (SELECT " + rowID + " FROM " + table +
+		where + b.orderBySQL() + " LIMIT ?)", bindings
+}
+
 // whereSQL generates the WHERE clause SQL
 func (b *Builder) whereSQL() string {
 	var whereClauses []string
@@ -621,25 +1359,29 @@ func (b *Builder) whereSQL() string {
 			// For raw or nested conditions
 			whereClauses = append(whereClauses, where.column)
 
-		case where.value == "NULL":
+		case where.isNull:
 			// For IS NULL conditions
-			whereClauses = append(whereClauses, fmt.Sprintf("%v %v %v", where.column, where.operator, where.value))
+			whereClauses = append(whereClauses, b.quote(where.column)+" "+where.operator+" NULL")
 
 		case where.isColumn:
 			// For column comparisons
-			whereClauses = append(whereClauses, fmt.Sprintf("%v %v %v", where.column, where.operator, where.value))
+			whereClauses = append(whereClauses, fmt.Sprintf("%v %v %v", b.quote(where.column), where.operator, b.quote(fmt.Sprint(where.value))))
 
-		case where.operator == "IN" || where.operator == "NOT IN" || where.operator == "EXISTS":
+		case where.operator == "IN" || where.operator == "NOT IN":
 			// Special handling for IN operator
-			whereClauses = append(whereClauses, fmt.Sprintf("%v %v (%v)", where.column, where.operator, where.value))
+			whereClauses = append(whereClauses, fmt.Sprintf("%v %v (%v)", b.quote(where.column), where.operator, where.value))
 
-		case where.operator == "BETWEEN":
+		case where.operator == "BETWEEN" || where.operator == "NOT BETWEEN":
 			// Special handling for BETWEEN operator
-			whereClauses = append(whereClauses, fmt.Sprintf("%v %v %v", where.column, where.operator, where.value))
+			whereClauses = append(whereClauses, fmt.Sprintf("%v %v %v", b.quote(where.column), where.operator, where.value))
+
+		case where.operator == "ILIKE":
+			// Case-insensitive LIKE depends on the dialect
+			whereClauses = append(whereClauses, b.quote(where.column)+" "+b.insensitiveLike())
 
 		default:
 			// For normal conditions
-			whereClauses = append(whereClauses, where.column+" "+where.operator+" ?")
+			whereClauses = append(whereClauses, b.quote(where.column)+" "+where.operator+" ?")
 		}
 	}
 	return strings.Join(whereClauses, " ")
@@ -658,59 +1400,129 @@ func (b *Builder) Transaction(ctx context.Context, fn func(*Builder) error) erro
 	}
 
 	// Create a new builder with the transaction
-	txBuilder := &Builder{
-		table:    b.table,
-		columns:  b.columns,
-		wheres:   b.wheres,
-		joins:    b.joins,
-		groups:   b.groups,
-		havings:  b.havings,
-		orders:   b.orders,
-		limit:    b.limit,
-		offset:   b.offset,
-		bindings: b.bindings,
-		db:       tx,
-	}
+	txBuilder := b.Clone()
+	txBuilder.db = tx
+	hooks := &txHooks{}
+	txBuilder.txHooks = hooks
 
 	if err := fn(txBuilder); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("error rolling back: %v (original error: %v)", rbErr, err)
 		}
+		hooks.rolledBack(ctx)
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	hooks.committed(ctx)
+	return nil
 }
 
-// BatchInsert executes multiple INSERT in a single query
-func (b *Builder) BatchInsert(ctx context.Context, data []map[string]interface{}) error {
+// BatchInsert executes multiple INSERT in a single query, or one per chunk
+// with WithInsertChunkSize. A failing chunk stops the insert with a
+// *BatchError locating the row when the driver reports it; earlier chunks
+// stay inserted. With BisectOnError the failing chunk is split instead to
+// insert every valid row and report each offending one.
+func (b *Builder) BatchInsert(ctx context.Context, data []map[string]interface{}, opts ...BatchInsertOption) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	// Get columns from first row
-	columns := make([]string, 0)
-	for column := range data[0] {
-		columns = append(columns, column)
+	var cfg batchInsertConfig
+	for _, opt := range opts {
+		opt.applyBatchInsert(&cfg)
+	}
+	size := cfg.chunkSize
+	if size < 1 {
+		size = len(data)
 	}
 
+	// Get columns from first row, every row binds its values in this order
+	columns := sortedKeys(data[0])
+
+	var errs []error
+	for start, chunk := 0, 0; start < len(data); start, chunk = start+size, chunk+1 {
+		rows := data[start:min(start+size, len(data))]
+		err := b.insertRows(ctx, columns, rows)
+		if err == nil {
+			continue
+		}
+		if !cfg.bisect {
+			return newBatchError(chunk, start, rows, err)
+		}
+		errs = append(errs, b.bisectInsert(ctx, columns, rows, chunk, start, err)...)
+	}
+	return errors.Join(errs...)
+}
+
+// insertRows inserts rows with a single INSERT statement
+func (b *Builder) insertRows(ctx context.Context, columns []string, data []map[string]interface{}) error {
+	query, args, err := b.insertSQL("INSERT INTO ", columns, data)
+	if err != nil {
+		return err
+	}
+	_, err = b.execContext(ctx, query, args...)
+	return err
+}
+
+// insertSQL renders a multi-row insert starting with the insert keywords,
+// binding the normalized values of every row in the order of columns
+func (b *Builder) insertSQL(insert string, columns []string, data []map[string]interface{}) (string, []interface{}, error) {
 	// Build placeholders and collect values
-	var placeholders []string
-	for _, row := range data {
-		rowPlaceholders := make([]string, len(columns))
-		for i, col := range columns {
-			rowPlaceholders[i] = "?"
-			b.bindings = append(b.bindings, row[col])
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	placeholders := make([]string, len(data))
+	args := make([]interface{}, 0, len(data)*len(columns))
+	for i, values := range data {
+		normalized, err := normalizeRow(columns, values)
+		if err != nil {
+			return "", nil, err
 		}
-		placeholders = append(placeholders, "("+strings.Join(rowPlaceholders, ", ")+")")
+		placeholders[i] = row
+		args = append(args, normalized...)
 	}
 
-	query := "INSERT INTO " + b.table +
-		" (" + strings.Join(columns, ", ") + ") VALUES " +
+	query := insert + b.quote(b.table) +
+		" (" + strings.Join(b.quoteAll(columns), ", ") + ") VALUES " +
 		strings.Join(placeholders, ", ")
+	return query, args, nil
+}
 
-	_, err := b.db.ExecContext(ctx, query, b.bindings...)
-	return err
+// InsertUsing copies the rows selected by sub into the table with
+// INSERT INTO table (columns) SELECT ..., without reading them into Go.
+// When both lists are explicit, columns must match the select list of sub
+// in length; an empty columns inserts into every column in table order.
+// It returns the number of rows inserted.
+func (b *Builder) InsertUsing(ctx context.Context, columns []string, sub *Builder) (int64, error) {
+	if b.table == "" {
+		return 0, errors.New("table name is required")
+	}
+	if sub.err != nil {
+		return 0, sub.err
+	}
+	// A wildcard hides the number of selected columns
+	wildcard := slices.ContainsFunc(sub.columns, func(column string) bool {
+		return strings.Contains(column, "*")
+	})
+	if len(columns) > 0 && len(sub.columns) > 0 && !wildcard && len(columns) != len(sub.columns) {
+		return 0, fmt.Errorf("insert using %d columns but the subquery selects %d", len(columns), len(sub.columns))
+	}
+	if err := sub.materializeInModels(ctx); err != nil {
+		return 0, err
+	}
+
+	query := "INSERT INTO " + b.quote(b.table)
+	if len(columns) > 0 {
+		query += " (" + strings.Join(b.quoteAll(columns), ", ") + ")"
+	}
+	selectSQL, bindings := sub.toSQLWithBindings()
+
+	result, err := b.execContext(ctx, query+" "+selectSQL, bindings...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 // BulkUpdate executes multiple UPDATE in a single query
@@ -721,14 +1533,15 @@ func (b *Builder) BulkUpdate(ctx context.Context, data []map[string]interface{},
 
 	// Build CASE statements for each column
 	var sets []string
-	for column := range data[0] {
+	var args []interface{}
+	for _, column := range sortedKeys(data[0]) {
 		if column == key {
 			continue
 		}
-		caseStmt := column + " = CASE " + key
+		caseStmt := b.quote(column) + " = CASE " + b.quote(key)
 		for _, row := range data {
 			caseStmt += fmt.Sprintf(" WHEN ? THEN ?")
-			b.bindings = append(b.bindings, row[key], row[column])
+			args = append(args, b.bind(key, row[key]), b.bind(column, row[column]))
 		}
 		caseStmt += " END"
 		sets = append(sets, caseStmt)
@@ -738,18 +1551,19 @@ func (b *Builder) BulkUpdate(ctx context.Context, data []map[string]interface{},
 	keys := make([]interface{}, len(data))
 	for i, row := range data {
 		keys[i] = row[key]
-		b.bindings = append(b.bindings, row[key])
+		args = append(args, b.bind(key, row[key]))
 	}
 
-	query := "UPDATE " + b.table + " SET " + strings.Join(sets, ", ") +
-		" WHERE " + key + " IN (" + strings.Repeat("?,", len(keys)-1) + "?)"
+	query := "UPDATE " + b.quote(b.table) + " SET " + strings.Join(sets, ", ") +
+		" WHERE " + b.quote(key) + " IN (" + strings.Repeat("?,", len(keys)-1) + "?)"
 
-	_, err := b.db.ExecContext(ctx, query, b.bindings...)
+	_, err := b.execContext(ctx, query, args...)
 	return err
 }
 
 // RightJoin adds a RIGHT JOIN clause
 func (b *Builder) RightJoin(table string, condition string) *Builder {
+	b.checkIdentifier(table)
 	b.joins = append(b.joins, join{
 		table:     table,
 		condition: condition,
@@ -760,6 +1574,7 @@ func (b *Builder) RightJoin(table string, condition string) *Builder {
 
 // CrossJoin adds a CROSS JOIN clause
 func (b *Builder) CrossJoin(table string) *Builder {
+	b.checkIdentifier(table)
 	b.joins = append(b.joins, join{
 		table:    table,
 		joinType: "CROSS",
@@ -769,46 +1584,146 @@ func (b *Builder) CrossJoin(table string) *Builder {
 
 // JoinSub adds a subquery JOIN
 func (b *Builder) JoinSub(subQuery *Builder, as string, condition string) *Builder {
+	return b.joinSub("INNER", subQuery, as, condition)
+}
+
+// LeftJoinSub adds a subquery LEFT JOIN
+func (b *Builder) LeftJoinSub(subQuery *Builder, as string, condition string) *Builder {
+	return b.joinSub("LEFT", subQuery, as, condition)
+}
+
+// RightJoinSub adds a subquery RIGHT JOIN
+func (b *Builder) RightJoinSub(subQuery *Builder, as string, condition string) *Builder {
+	return b.joinSub("RIGHT", subQuery, as, condition)
+}
+
+// CrossJoinSub adds a subquery CROSS JOIN
+func (b *Builder) CrossJoinSub(subQuery *Builder, as string) *Builder {
+	return b.joinSub("CROSS", subQuery, as, "")
+}
+
+// joinSub adds a subquery join, its bindings go to the join bindings group
+func (b *Builder) joinSub(joinType string, subQuery *Builder, as string, condition string) *Builder {
 	b.joins = append(b.joins, join{
-		table:     "(" + subQuery.ToSQL() + ") AS " + as,
+		table:     "(" + subQuery.toSQL() + ")" + b.aliasSQL(as, " AS "),
 		condition: condition,
-		joinType:  "INNER",
+		joinType:  joinType,
 	})
-	b.bindings = append(b.bindings, subQuery.bindings...)
+	b.joinBindings = append(b.joinBindings, subQuery.GetBindings()...)
 	return b
 }
 
 // WhereExists adds WHERE EXISTS clause
 func (b *Builder) WhereExists(subQuery *Builder) *Builder {
-	b.wheres = append(b.wheres, where{
-		column:   "EXISTS",
-		operator: "",
-		value:    "(" + subQuery.ToSQL() + ")",
-		boolean:  "AND",
-	})
-	b.bindings = append(b.bindings, subQuery.bindings...)
-	return b
+	return b.whereExists(subQuery, "EXISTS", "AND")
+}
+
+// WhereNotExists adds a WHERE NOT EXISTS clause
+func (b *Builder) WhereNotExists(subQuery *Builder) *Builder {
+	return b.whereExists(subQuery, "NOT EXISTS", "AND")
+}
+
+// OrWhereExists adds an OR EXISTS clause
+func (b *Builder) OrWhereExists(subQuery *Builder) *Builder {
+	return b.whereExists(subQuery, "EXISTS", "OR")
+}
+
+// OrWhereNotExists adds an OR NOT EXISTS clause
+func (b *Builder) OrWhereNotExists(subQuery *Builder) *Builder {
+	return b.whereExists(subQuery, "NOT EXISTS", "OR")
+}
+
+// whereExists renders the subquery inline, its bindings take the place of
+// the condition among the WHERE bindings
+func (b *Builder) whereExists(subQuery *Builder, operator, boolean string) *Builder {
+	return b.whereRaw(operator+" ("+subQuery.toSQL()+")", boolean, subQuery.GetBindings())
+}
+
+// WhereInSubQuery adds a WHERE column IN (subquery) clause
+func (b *Builder) WhereInSubQuery(column string, subQuery *Builder) *Builder {
+	return b.whereInSubQuery(column, "IN", "AND", subQuery)
+}
+
+// WhereNotInSubQuery adds a WHERE column NOT IN (subquery) clause
+func (b *Builder) WhereNotInSubQuery(column string, subQuery *Builder) *Builder {
+	return b.whereInSubQuery(column, "NOT IN", "AND", subQuery)
+}
+
+// OrWhereInSubQuery adds an OR column IN (subquery) clause
+func (b *Builder) OrWhereInSubQuery(column string, subQuery *Builder) *Builder {
+	return b.whereInSubQuery(column, "IN", "OR", subQuery)
+}
+
+// OrWhereNotInSubQuery adds an OR column NOT IN (subquery) clause
+func (b *Builder) OrWhereNotInSubQuery(column string, subQuery *Builder) *Builder {
+	return b.whereInSubQuery(column, "NOT IN", "OR", subQuery)
+}
+
+func (b *Builder) whereInSubQuery(column, operator, boolean string, subQuery *Builder) *Builder {
+	return b.whereRaw(b.quote(column)+" "+operator+" ("+subQuery.toSQL()+")", boolean, subQuery.GetBindings())
+}
+
+// WhereLike adds WHERE LIKE clause, the pattern is normalized with opts
+func (b *Builder) WhereLike(column string, pattern string, opts ...SearchNormalize) *Builder {
+	return b.whereLike(column, "LIKE", "AND", pattern, opts)
+}
+
+// WhereNotLike adds a WHERE NOT LIKE clause
+func (b *Builder) WhereNotLike(column string, pattern string, opts ...SearchNormalize) *Builder {
+	return b.whereLike(column, "NOT LIKE", "AND", pattern, opts)
+}
+
+// OrWhereLike adds an OR WHERE LIKE clause
+func (b *Builder) OrWhereLike(column string, pattern string, opts ...SearchNormalize) *Builder {
+	return b.whereLike(column, "LIKE", "OR", pattern, opts)
 }
 
-// WhereLike adds WHERE LIKE clause
-func (b *Builder) WhereLike(column string, pattern string) *Builder {
+// OrWhereNotLike adds an OR WHERE NOT LIKE clause
+func (b *Builder) OrWhereNotLike(column string, pattern string, opts ...SearchNormalize) *Builder {
+	return b.whereLike(column, "NOT LIKE", "OR", pattern, opts)
+}
+
+// WhereLikeInsensitive adds a case-insensitive LIKE: ILIKE on Postgres,
+// LIKE with a case-insensitive utf8mb4 collation on MySQL and plain LIKE
+// on SQLite, which ignores ASCII case already
+func (b *Builder) WhereLikeInsensitive(column string, pattern string, opts ...SearchNormalize) *Builder {
+	return b.whereLike(column, "ILIKE", "AND", pattern, opts)
+}
+
+func (b *Builder) whereLike(column, operator, boolean, pattern string, opts []SearchNormalize) *Builder {
+	pattern = normalizeSearch(pattern, opts)
 	b.wheres = append(b.wheres, where{
 		column:   column,
-		operator: "LIKE",
+		operator: operator,
 		value:    pattern,
-		boolean:  "AND",
+		boolean:  boolean,
 	})
 	b.bindings = append(b.bindings, pattern)
 	return b
 }
 
+// insensitiveLike renders the comparison of an ILIKE condition for the dialect
+func (b *Builder) insensitiveLike() string {
+	switch b.dialect.(type) {
+	case postgresDialect:
+		return "ILIKE ?"
+	case sqliteDialect:
+		return "LIKE ?"
+	}
+	return "LIKE ? COLLATE utf8mb4_general_ci"
+}
+
 // WhereRaw adds raw WHERE condition
 func (b *Builder) WhereRaw(sql string, bindings ...interface{}) *Builder {
+	return b.whereRaw(sql, "AND", bindings)
+}
+
+func (b *Builder) whereRaw(sql, boolean string, bindings []interface{}) *Builder {
 	b.wheres = append(b.wheres, where{
 		column:   sql,
 		operator: "",
 		value:    "",
-		boolean:  "AND",
+		boolean:  boolean,
 	})
 	b.bindings = append(b.bindings, bindings...)
 	return b
@@ -819,7 +1734,7 @@ type QueryFunc func(*Builder)
 
 // WhereFunc adds a WHERE clause using a callback function
 func (b *Builder) WhereFunc(fn QueryFunc) *Builder {
-	subBuilder := New(b.db)
+	subBuilder := b.newQuery()
 	fn(subBuilder)
 
 	// Merge conditions from subBuilder
@@ -830,7 +1745,7 @@ func (b *Builder) WhereFunc(fn QueryFunc) *Builder {
 
 // OrWhereFunc adds an OR WHERE clause using a callback function
 func (b *Builder) OrWhereFunc(fn QueryFunc) *Builder {
-	subBuilder := New(b.db)
+	subBuilder := b.newQuery()
 	fn(subBuilder)
 
 	// Convert first condition to OR
@@ -845,7 +1760,23 @@ func (b *Builder) OrWhereFunc(fn QueryFunc) *Builder {
 
 // JoinFunc adds a JOIN clause using a callback function
 func (b *Builder) JoinFunc(table string, fn QueryFunc) *Builder {
-	subBuilder := New(b.db)
+	return b.joinFunc("INNER", table, fn)
+}
+
+// LeftJoinFunc adds a LEFT JOIN clause using a callback function
+func (b *Builder) LeftJoinFunc(table string, fn QueryFunc) *Builder {
+	return b.joinFunc("LEFT", table, fn)
+}
+
+// RightJoinFunc adds a RIGHT JOIN clause using a callback function
+func (b *Builder) RightJoinFunc(table string, fn QueryFunc) *Builder {
+	return b.joinFunc("RIGHT", table, fn)
+}
+
+// joinFunc converts the callback's where conditions into a join condition
+func (b *Builder) joinFunc(joinType string, table string, fn QueryFunc) *Builder {
+	b.checkIdentifier(table)
+	subBuilder := b.newQuery()
 	fn(subBuilder)
 
 	// Convert WHERE conditions to JOIN conditions
@@ -854,10 +1785,12 @@ func (b *Builder) JoinFunc(table string, fn QueryFunc) *Builder {
 		if where.isColumn {
 			conditions = append(conditions, fmt.Sprintf("%v %v %v",
 				where.column, where.operator, where.value))
+		} else if where.isNull {
+			conditions = append(conditions, where.column+" "+where.operator+" NULL")
 		} else {
 			conditions = append(conditions, fmt.Sprintf("%v %v ?",
 				where.column, where.operator))
-			b.bindings = append(b.bindings, where.value)
+			b.joinBindings = append(b.joinBindings, where.value)
 		}
 	}
 
@@ -865,7 +1798,7 @@ func (b *Builder) JoinFunc(table string, fn QueryFunc) *Builder {
 	b.joins = append(b.joins, join{
 		table:     table,
 		condition: joinCondition,
-		joinType:  "INNER",
+		joinType:  joinType,
 	})
 
 	return b
@@ -873,7 +1806,7 @@ func (b *Builder) JoinFunc(table string, fn QueryFunc) *Builder {
 
 // HavingFunc adds a HAVING clause using a callback function
 func (b *Builder) HavingFunc(fn QueryFunc) *Builder {
-	subBuilder := New(b.db)
+	subBuilder := b.newQuery()
 	fn(subBuilder)
 
 	for _, where := range subBuilder.wheres {
@@ -882,15 +1815,16 @@ func (b *Builder) HavingFunc(fn QueryFunc) *Builder {
 			operator: where.operator,
 			value:    where.value,
 			boolean:  where.boolean,
+			isNull:   where.isNull,
 		})
 	}
-	b.bindings = append(b.bindings, subBuilder.bindings...)
+	b.havingBindings = append(b.havingBindings, subBuilder.bindings...)
 	return b
 }
 
 // WhereNested adds a nested WHERE clause
 func (b *Builder) WhereNested(callback func(*Builder)) *Builder {
-	subBuilder := New(b.db)
+	subBuilder := b.newQuery()
 	callback(subBuilder)
 
 	if len(subBuilder.wheres) > 0 {
@@ -954,24 +1888,74 @@ func (b *Builder) WhenNot(condition bool, callback func(*Builder)) *Builder {
 	return b
 }
 
+// WhereNotEmpty adds a where clause only when value is not empty: nil, a
+// zero number, an empty string, an empty slice or map and nil pointers are
+// skipped. Handy for optional filters taken from a request.
+func (b *Builder) WhereNotEmpty(column string, operator string, value interface{}) *Builder {
+	if isEmptyValue(value) {
+		return b
+	}
+	return b.Where(column, operator, value)
+}
+
+// isEmptyValue reports whether a filter value should be ignored
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
 // Unless is an alias for WhenNot
 func (b *Builder) Unless(condition bool, callback func(*Builder)) *Builder {
 	return b.WhenNot(condition, callback)
 }
 
+// ApplyAll applies the given filters in order, nil filters are skipped
+func (b *Builder) ApplyAll(filters ...func(*Builder) *Builder) *Builder {
+	for _, filter := range filters {
+		if filter != nil {
+			b = filter(b)
+		}
+	}
+	return b
+}
+
 // Debug returns the query with interpolated values
 func (b *Builder) Debug() string {
-	sql := b.ToSQL()
-	for _, binding := range b.bindings {
-		sql = strings.Replace(sql, "?", fmt.Sprintf("%v", binding), 1)
-	}
-	return sql
+	query, bindings := b.toSQLWithBindings()
+	return replacePlaceholders(query, func(n int) string {
+		if n > len(bindings) {
+			return "?"
+		}
+		return fmt.Sprintf("%v", bindings[n-1])
+	})
+}
+
+// DebugPretty returns the query formatted with FormatSQL, with values
+// interpolated as SQL literals
+func (b *Builder) DebugPretty() string {
+	query, bindings := b.toSQLWithBindings()
+	return FormatSQL(replacePlaceholders(query, func(n int) string {
+		if n > len(bindings) {
+			return "?"
+		}
+		return sqlLiteral(bindings[n-1])
+	}))
 }
 
 // Explain returns the query execution plan
 func (b *Builder) Explain() (string, error) {
 	ctx := context.Background()
-	rows, err := b.db.QueryContext(ctx, "EXPLAIN "+b.ToSQL(), b.bindings...)
+	query, bindings := b.toSQLWithBindings()
+	rows, err := b.queryContext(ctx, "EXPLAIN "+query, bindings...)
 	if err != nil {
 		return "", err
 	}
@@ -1000,90 +1984,180 @@ func (b *Builder) Explain() (string, error) {
 	return explanation.String(), nil
 }
 
-// GetBindings returns the current query bindings
+// GetBindings returns the query bindings in the order their placeholders
+// appear in ToSQL, or in the statement described by Insert/Update, whatever
+// order the clauses were added in
 func (b *Builder) GetBindings() []interface{} {
-	return b.bindings
+	if b.statement == statementInsert || b.statement == statementUpdate {
+		return b.writeBindings()
+	}
+	bindings := b.baseBindings()
+	if len(b.ctes) > 0 {
+		bindings = append(b.cteBindings(), bindings...)
+	}
+	for _, union := range b.unions {
+		bindings = append(bindings, union.query.baseBindings()...)
+	}
+	return bindings
+}
+
+// baseBindings returns the bindings of buildBaseQuery, grouped by clause
+func (b *Builder) baseBindings() []interface{} {
+	bindings := make([]interface{}, 0, len(b.selectBindings)+len(b.fromBindings)+
+		len(b.joinBindings)+len(b.bindings)+len(b.groupBindings)+len(b.havingBindings)+2)
+	bindings = append(bindings, b.selectBindings...)
+	bindings = append(bindings, b.fromBindings...)
+	bindings = append(bindings, b.joinBindings...)
+	bindings = append(bindings, b.bindings...)
+	bindings = append(bindings, b.groupBindings...)
+	bindings = append(bindings, b.havingBindings...)
+	bindings = append(bindings, b.orderBindings()...)
+	if b.limit != nil {
+		bindings = append(bindings, *b.limit)
+	}
+	if b.offset != nil {
+		bindings = append(bindings, *b.offset)
+	}
+	return bindings
 }
 
 // Schema operations
 type SchemaBuilder struct {
-	columns map[string]string
-	indexes map[string][]string
+	columns      []schemaColumn
+	indexes      map[string][]string
+	partitioning *partitioning // See PartitionByRange and PartitionByList
+}
+
+type schemaColumn struct {
+	name string
+	typ  string
 }
 
 func NewSchemaBuilder() *SchemaBuilder {
 	return &SchemaBuilder{
-		columns: make(map[string]string),
 		indexes: make(map[string][]string),
 	}
 }
 
+// Column adds a column with its SQL type and constraints, e.g. "BIGINT NOT NULL"
+func (s *SchemaBuilder) Column(name, typ string) *SchemaBuilder {
+	s.columns = append(s.columns, schemaColumn{name: name, typ: typ})
+	return s
+}
+
 // CreateTable creates a new table
 func (b *Builder) CreateTable(name string, callback func(*SchemaBuilder)) error {
+	statements, err := b.createTableSQL(name, callback)
+	if err != nil {
+		return err
+	}
+	for _, query := range statements {
+		if _, err := b.execContext(context.Background(), query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTableSQL renders the statements creating the table, partitioned
+// Postgres tables need one extra statement per partition
+func (b *Builder) createTableSQL(name string, callback func(*SchemaBuilder)) ([]string, error) {
 	schema := NewSchemaBuilder()
 	callback(schema)
 
-	var cols []string
-	for col, typ := range schema.columns {
-		cols = append(cols, fmt.Sprintf("%s %s", col, typ))
+	cols := make([]string, len(schema.columns))
+	for i, col := range schema.columns {
+		cols[i] = fmt.Sprintf("%s %s", b.quote(col.name), col.typ)
 	}
 
-	query := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", name, strings.Join(cols, ",\n"))
-	_, err := b.db.ExecContext(context.Background(), query)
-	return err
+	query := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", b.quote(name), strings.Join(cols, ",\n"))
+	if schema.partitioning == nil {
+		return []string{query}, nil
+	}
+	return b.partitionedTableSQL(query, name, schema.partitioning)
 }
 
 // Query events
 type QueryEvent struct {
 	SQL      string
 	Bindings []interface{}
-	Duration time.Duration
+	Duration time.Duration // Set for after query handlers
+	Err      error         // Error returned by the query, set for after query handlers
 }
 
 type QueryEventHandler func(*QueryEvent)
 
-// BeforeQuery adds a before query event handler
+// BeforeQuery adds a handler called right before each statement is sent
+// to the database. Handlers are kept by Clone and Transaction.
 func (b *Builder) BeforeQuery(handler QueryEventHandler) *Builder {
 	b.beforeQueryHandlers = append(b.beforeQueryHandlers, handler)
 	return b
 }
 
-// AfterQuery adds an after query event handler
+// AfterQuery adds a handler called after each statement returns, failed
+// ones included
 func (b *Builder) AfterQuery(handler QueryEventHandler) *Builder {
 	b.afterQueryHandlers = append(b.afterQueryHandlers, handler)
 	return b
 }
 
+// beforeQuery creates the event of a statement about to run and passes it
+// to the before query handlers
+func (b *Builder) beforeQuery(query string, args []interface{}) *QueryEvent {
+	event := &QueryEvent{SQL: query, Bindings: append([]interface{}(nil), args...)}
+	for _, handler := range b.beforeQueryHandlers {
+		handler(event)
+	}
+	return event
+}
+
+// afterQuery completes the event with the outcome of the statement and
+// passes it to the after query handlers
+func (b *Builder) afterQuery(event *QueryEvent, elapsed time.Duration, err error) {
+	event.Duration = elapsed
+	event.Err = err
+	for _, handler := range b.afterQueryHandlers {
+		handler(event)
+	}
+}
+
 // Batch processing
 type Paginator struct {
-	Items       []map[string]interface{}
-	Total       int64
-	PerPage     int
-	CurrentPage int
-	LastPage    int
+	Items          []map[string]interface{}
+	Total          int64
+	TotalFromCache bool // Total was served by WithCachedCount and may be stale
+	PerPage        int
+	CurrentPage    int
+	LastPage       int
+
+	// Navigation URLs, populated by WithBaseURL
+	FirstPageURL string `json:"-"`
+	LastPageURL  string `json:"-"`
+	NextPageURL  string `json:"-"`
+	PrevPageURL  string `json:"-"`
+
+	baseURL    string
+	linkWindow int
 }
 
 // Paginate returns paginated results
-func (b *Builder) Paginate(page, perPage int) (*Paginator, error) {
+func (b *Builder) Paginate(page, perPage int, opts ...PaginateOption) (*Paginator, error) {
 	ctx := context.Background()
 
+	var cfg paginateConfig
+	for _, opt := range opts {
+		opt.applyPaginate(&cfg)
+	}
+
 	// Get total count
-	countBuilder := *b
-	count, err := countBuilder.Count("*").Get(ctx)
+	total, cached, err := b.paginateTotal(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	var total int64
-
-	// count total
-	if count.Next() {
-		count.Scan(&total)
-	}
-
-	// Get paginated results
+	// Get paginated results from a copy, so b can be paginated again
 	offset := (page - 1) * perPage
-	rows, err := b.Limit(perPage).Offset(offset).Get(ctx)
+	rows, err := b.Clone().Limit(perPage).Offset(offset).Get(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1107,10 +2181,11 @@ func (b *Builder) Paginate(page, perPage int) (*Paginator, error) {
 	}
 
 	return &Paginator{
-		Items:       items,
-		Total:       total,
-		PerPage:     perPage,
-		CurrentPage: page,
-		LastPage:    int(math.Ceil(float64(total) / float64(perPage))),
+		Items:          items,
+		Total:          total,
+		TotalFromCache: cached,
+		PerPage:        perPage,
+		CurrentPage:    page,
+		LastPage:       int(math.Ceil(float64(total) / float64(perPage))),
 	}, nil
 }
@@ -3,7 +3,10 @@ package qix
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -146,6 +149,35 @@ func TestOrderByAndLimit(t *testing.T) {
 	}
 }
 
+func TestOrderShorthands(t *testing.T) {
+	tests := []struct {
+		name      string
+		shorthand func(*Builder) *Builder
+		verbose   func(*Builder) *Builder
+	}{
+		{"OrderByAsc", func(b *Builder) *Builder { return b.OrderByAsc("name") }, func(b *Builder) *Builder { return b.OrderBy("name", "ASC") }},
+		{"OrderByDesc", func(b *Builder) *Builder { return b.OrderByDesc("name") }, func(b *Builder) *Builder { return b.OrderBy("name", "DESC") }},
+		{"Latest default", func(b *Builder) *Builder { return b.Latest() }, func(b *Builder) *Builder { return b.OrderBy("created_at", "DESC") }},
+		{"Latest column", func(b *Builder) *Builder { return b.Latest("published_at") }, func(b *Builder) *Builder { return b.OrderBy("published_at", "DESC") }},
+		{"Oldest default", func(b *Builder) *Builder { return b.Oldest() }, func(b *Builder) *Builder { return b.OrderBy("created_at", "ASC") }},
+		{"Oldest column", func(b *Builder) *Builder { return b.Oldest("published_at") }, func(b *Builder) *Builder { return b.OrderBy("published_at", "ASC") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.shorthand(New(&MockDB{}).Table("posts")).Limit(5).ToSQL()
+			expected := tt.verbose(New(&MockDB{}).Table("posts")).Limit(5).ToSQL()
+			if got != expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", expected, got)
+			}
+		})
+	}
+
+	if sql := New(&MockDB{}).Table("posts").Latest().OrderByAsc("id").ToSQL(); sql != "SELECT * FROM posts ORDER BY created_at DESC, id ASC" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+}
+
 func TestAggregateFunctions(t *testing.T) {
 	db := &MockDB{}
 	builder := New(db)
@@ -252,6 +284,15 @@ func TestWhereHelpers(t *testing.T) {
 			},
 			expected: "SELECT * FROM orders WHERE created_at BETWEEN ? AND ?",
 		},
+		{
+			name: "WhereNotBetween",
+			build: func() *Builder {
+				return New(db).Table("orders").WhereNotBetween("total", 10, 20).
+					OrWhereBetween("created_at", "2023-01-01", "2023-12-31").
+					OrWhereNotBetween("discount", 0, 5)
+			},
+			expected: "SELECT * FROM orders WHERE total NOT BETWEEN ? AND ? OR created_at BETWEEN ? AND ? OR discount NOT BETWEEN ? AND ?",
+		},
 		{
 			name: "Complex Where Conditions",
 			build: func() *Builder {
@@ -718,3 +759,997 @@ func TestPagination(t *testing.T) {
 		t.Errorf("Expected per page to be 20, got %d", paginator.PerPage)
 	}
 }
+
+func TestPaginateCountsWithoutColumns(t *testing.T) {
+	mock := NewMockSQL().Returning([]string{"count"}, []interface{}{int64(3)})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("users").Select("id", "name").Where("active", "=", true).OrderBy("id", "ASC")
+	if _, err := builder.Paginate(1, 20); err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	expected := "SELECT COUNT(*) FROM users WHERE active = ? LIMIT ?"
+	if len(calls) != 2 || calls[0].Query != expected {
+		t.Fatalf("Expected count SQL: %s\nGot: %+v", expected, calls)
+	}
+	if calls[1].Query != "SELECT id, name FROM users WHERE active = ? ORDER BY id ASC LIMIT ? OFFSET ?" {
+		t.Errorf("Unexpected page SQL: %s", calls[1].Query)
+	}
+	if got := builder.ToSQL(); got != "SELECT id, name FROM users WHERE active = ? ORDER BY id ASC" {
+		t.Errorf("Paginate changed the query: %s", got)
+	}
+}
+
+func TestWhereIntegerInRaw(t *testing.T) {
+	db := &MockDB{}
+	builder := New(db, PostgresDialect).Table("users").
+		Where("active", "=", true).
+		WhereIntegerInRaw("id", []int64{3, -1, 9223372036854775807}).
+		WhereIntegerNotInRaw("team_id", []int64{7})
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "SELECT * FROM users WHERE active = $1 AND id IN (3, -1, 9223372036854775807) AND team_id NOT IN (7)"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if len(bindings) != 1 {
+		t.Errorf("Expected only the active binding, got %v", bindings)
+	}
+
+	ids := make([]int64, 50000)
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+	sql, bindings = New(db).Table("users").WhereIntegerInRaw("id", ids).ToSQLWithBindings()
+	if strings.Contains(sql, "?") || len(bindings) != 0 {
+		t.Errorf("Expected no placeholders for a raw list, got %d bindings", len(bindings))
+	}
+
+	empty := New(db).Table("users").WhereIntegerInRaw("id", nil).WhereIntegerNotInRaw("team_id", []int64{}).ToSQL()
+	if empty != "SELECT * FROM users WHERE 1 = 0 AND 1 = 1" {
+		t.Errorf("Unexpected empty list SQL: %s", empty)
+	}
+	legacy := New(db, WithEmptyInMatchesNothing(false)).Table("users").WhereIntegerInRaw("id", nil).ToSQL()
+	if legacy != "SELECT * FROM users" {
+		t.Errorf("Expected the empty list to be ignored, got %s", legacy)
+	}
+}
+
+// benchmarkIDs are the ids of the WhereIn benchmarks
+var benchmarkIDs = func() []int64 {
+	ids := make([]int64, 10000)
+	for i := range ids {
+		ids[i] = int64(i) * 3
+	}
+	return ids
+}()
+
+func BenchmarkWhereInBindings(b *testing.B) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	values := make([]interface{}, len(benchmarkIDs))
+	for i, id := range benchmarkIDs {
+		values[i] = id
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := New(mock.DB).Table("users").WhereIn("id", values...).Get(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
+
+func BenchmarkWhereIntegerInRaw(b *testing.B) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := New(mock.DB).Table("users").WhereIntegerInRaw("id", benchmarkIDs).Get(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
+
+func TestEmptyInSemantics(t *testing.T) {
+	db := &MockDB{}
+	tests := []struct {
+		name     string
+		build    func() *Builder
+		expected string
+	}{
+		{
+			name: "Empty WhereIn matches nothing",
+			build: func() *Builder {
+				return New(db).Table("users").WhereIn("id")
+			},
+			expected: "SELECT * FROM users WHERE 1 = 0",
+		},
+		{
+			name: "Empty WhereNotIn matches everything",
+			build: func() *Builder {
+				return New(db).Table("users").Where("active", "=", true).WhereNotIn("id", []interface{}{})
+			},
+			expected: "SELECT * FROM users WHERE active = ? AND 1 = 1",
+		},
+		{
+			name: "Legacy empty WhereIn is ignored",
+			build: func() *Builder {
+				return New(db, WithEmptyInMatchesNothing(false)).Table("users").WhereIn("id").WhereNotIn("role")
+			},
+			expected: "SELECT * FROM users",
+		},
+		{
+			name: "WhereFalse and WhereTrue",
+			build: func() *Builder {
+				return New(db).Table("users").WhereTrue().OrWhereFunc(func(q *Builder) {
+					q.WhereFalse()
+				})
+			},
+			expected: "SELECT * FROM users WHERE 1 = 1 OR 1 = 0",
+		},
+		{
+			name: "OR composed empty WhereIn",
+			build: func() *Builder {
+				return New(db).Table("users").
+					Where("role", "=", "admin").
+					OrWhereFunc(func(q *Builder) {
+						q.WhereIn("id")
+					})
+			},
+			expected: "SELECT * FROM users WHERE role = ? OR 1 = 0",
+		},
+		{
+			name: "Empty OrWhereIn and OrWhereNotIn",
+			build: func() *Builder {
+				return New(db).Table("users").Where("role", "=", "admin").OrWhereIn("id").OrWhereNotIn("team_id")
+			},
+			expected: "SELECT * FROM users WHERE role = ? OR 1 = 0 OR 1 = 1",
+		},
+		{
+			name: "Empty WhereIn inside nested OR group",
+			build: func() *Builder {
+				return New(db).Table("users").
+					Where("active", "=", true).
+					WhereNested(func(q *Builder) {
+						q.WhereIn("team_id").OrWhere("vip", "=", true)
+					})
+			},
+			expected: "SELECT * FROM users WHERE active = ? AND (1 = 0 OR vip = ?)",
+		},
+		{
+			name: "Nested groups inherit legacy option",
+			build: func() *Builder {
+				return New(db, WithEmptyInMatchesNothing(false)).Table("users").
+					Where("active", "=", true).
+					WhereNested(func(q *Builder) {
+						q.WhereIn("team_id").Where("vip", "=", true)
+					})
+			},
+			expected: "SELECT * FROM users WHERE active = ? AND (vip = ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := tt.build().ToSQL()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestOrWhereVariants(t *testing.T) {
+	builder := New(&MockDB{}).Table("users").
+		Where("active", "=", true).
+		WhereNested(func(q *Builder) {
+			q.WhereIn("role", "admin", "owner").OrWhereNull("role_id").OrWhereNotIn("team_id", 3, 4)
+		}).
+		WhereNotNull("email").
+		OrWhereIn("id", 1, 2).
+		OrWhereNotNull("verified_at")
+
+	expected := "SELECT * FROM users WHERE active = ? AND (role IN (?, ?) OR role_id IS NULL OR team_id NOT IN (?, ?)) " +
+		"AND email IS NOT NULL OR id IN (?, ?) OR verified_at IS NOT NULL"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	want := []interface{}{true, "admin", "owner", 3, 4, 1, 2}
+	if got := builder.GetBindings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected bindings %v, got %v", want, got)
+	}
+}
+
+func TestWhereExistsVariants(t *testing.T) {
+	db := &MockDB{}
+	orders := New(db).Table("orders").Select("1").WhereColumn("orders.user_id", "=", "users.id").Where("orders.total", ">", 100)
+	bans := New(db).Table("bans").Select("1").WhereColumn("bans.user_id", "=", "users.id").Where("bans.active", "=", true)
+
+	builder := New(db, PostgresDialect).Table("users").
+		Where("users.active", "=", true).
+		WhereExists(orders).
+		WhereNotExists(bans).
+		Where("users.age", ">", 18).
+		OrWhereExists(New(db).Table("admins").Select("1").Where("admins.level", "=", 3)).
+		OrWhereNotExists(New(db).Table("invites").Select("1").WhereColumn("invites.user_id", "=", "users.id"))
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "SELECT * FROM users WHERE users.active = $1 " +
+		"AND EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id AND orders.total > $2) " +
+		"AND NOT EXISTS (SELECT 1 FROM bans WHERE bans.user_id = users.id AND bans.active = $3) " +
+		"AND users.age > $4 OR EXISTS (SELECT 1 FROM admins WHERE admins.level = $5) " +
+		"OR NOT EXISTS (SELECT 1 FROM invites WHERE invites.user_id = users.id)"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if want := []interface{}{true, 100, true, 18, 3}; !reflect.DeepEqual(bindings, want) {
+		t.Errorf("Expected bindings %v, got %v", want, bindings)
+	}
+}
+
+func TestWhereInSubQueryVariants(t *testing.T) {
+	db := &MockDB{}
+	paid := New(db).Table("orders").Select("user_id").Where("status", "=", "paid")
+	banned := New(db).Table("bans").Select("user_id").Where("active", "=", true)
+
+	builder := New(db, PostgresDialect).Table("users").
+		Where("tenant_id", "=", 7).
+		WhereInSubQuery("id", paid).
+		WhereNotInSubQuery("id", banned).
+		Where("age", ">", 18).
+		OrWhereInSubQuery("team_id", New(db).Table("teams").Select("id").Where("plan", "=", "pro")).
+		OrWhereNotInSubQuery("role_id", New(db).Table("roles").Select("id").WhereIn("name", "guest", "bot"))
+
+	sql, bindings := builder.ToSQLWithBindings()
+	expected := "SELECT * FROM users WHERE tenant_id = $1 " +
+		"AND id IN (SELECT user_id FROM orders WHERE status = $2) " +
+		"AND id NOT IN (SELECT user_id FROM bans WHERE active = $3) " +
+		"AND age > $4 OR team_id IN (SELECT id FROM teams WHERE plan = $5) " +
+		"OR role_id NOT IN (SELECT id FROM roles WHERE name IN ($6, $7))"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if want := []interface{}{7, "paid", true, 18, "pro", "guest", "bot"}; !reflect.DeepEqual(bindings, want) {
+		t.Errorf("Expected bindings %v, got %v", want, bindings)
+	}
+}
+
+func TestWhereNullWithBoolean(t *testing.T) {
+	db := &MockDB{}
+	tests := []struct {
+		name     string
+		build    func() *Builder
+		expected string
+		bindings int
+	}{
+		{
+			name: "AND null and not null",
+			build: func() *Builder {
+				return New(db).Table("users").WhereNull("deleted_at").WhereNotNull("email_verified_at")
+			},
+			expected: "SELECT * FROM users WHERE deleted_at IS NULL AND email_verified_at IS NOT NULL",
+		},
+		{
+			name: "OR null",
+			build: func() *Builder {
+				return New(db).Table("users").
+					Where("role", "=", "admin").
+					WhereNullWithBoolean("banned_at", "or", false)
+			},
+			expected: "SELECT * FROM users WHERE role = ? OR banned_at IS NULL",
+			bindings: 1,
+		},
+		{
+			name: "OR not null inside nested group",
+			build: func() *Builder {
+				return New(db).Table("users").
+					WhereNull("deleted_at").
+					WhereNested(func(q *Builder) {
+						q.WhereNotNull("verified_at").WhereNullWithBoolean("invited_by", "OR", true)
+					})
+			},
+			expected: "SELECT * FROM users WHERE deleted_at IS NULL AND (verified_at IS NOT NULL OR invited_by IS NOT NULL)",
+		},
+		{
+			name: "String NULL value is still bound",
+			build: func() *Builder {
+				return New(db).Table("users").Where("nickname", "=", "NULL").WhereNull("deleted_at")
+			},
+			expected: "SELECT * FROM users WHERE nickname = ? AND deleted_at IS NULL",
+			bindings: 1,
+		},
+		{
+			name: "Null conditions in join and having callbacks",
+			build: func() *Builder {
+				return New(db).Table("users").
+					JoinFunc("orders", func(q *Builder) {
+						q.WhereColumn("users.id", "=", "orders.user_id").WhereNull("orders.deleted_at")
+					}).
+					GroupBy("users.id").
+					HavingFunc(func(q *Builder) {
+						q.WhereNotNull("MAX(orders.paid_at)")
+					})
+			},
+			expected: "SELECT * FROM users INNER JOIN orders ON users.id = orders.user_id AND orders.deleted_at IS NULL GROUP BY users.id HAVING MAX(orders.paid_at) IS NOT NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := tt.build()
+			sql := builder.ToSQL()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+			if len(builder.GetBindings()) != tt.bindings {
+				t.Errorf("Expected %d bindings, got %v", tt.bindings, builder.GetBindings())
+			}
+		})
+	}
+}
+
+func TestJoinVariantsBindingOrder(t *testing.T) {
+	db := &MockDB{}
+	tests := []struct {
+		name     string
+		build    func() *Builder
+		expected string
+		bindings []interface{}
+	}{
+		{
+			name: "LeftJoinFunc",
+			build: func() *Builder {
+				return New(db).Table("users").
+					Where("users.active", "=", true).
+					LeftJoinFunc("orders", func(q *Builder) {
+						q.WhereColumn("users.id", "=", "orders.user_id").
+							Where("orders.status", "=", "paid")
+					})
+			},
+			expected: "SELECT * FROM users LEFT JOIN orders ON users.id = orders.user_id AND orders.status = ? WHERE users.active = ?",
+			bindings: []interface{}{"paid", true},
+		},
+		{
+			name: "RightJoinFunc",
+			build: func() *Builder {
+				return New(db).Table("users").
+					RightJoinFunc("orders", func(q *Builder) {
+						q.WhereColumn("users.id", "=", "orders.user_id")
+					})
+			},
+			expected: "SELECT * FROM users RIGHT JOIN orders ON users.id = orders.user_id",
+			bindings: []interface{}{},
+		},
+		{
+			name: "Where before LeftJoinSub",
+			build: func() *Builder {
+				sub := New(db).Table("orders").
+					Select("user_id", "SUM(total) as spent").
+					Where("created_at", ">", "2024-01-01").
+					GroupBy("user_id")
+				return New(db).Table("users").
+					Where("country", "=", "ID").
+					LeftJoinSub(sub, "spending", "users.id = spending.user_id").
+					Where("spending.spent", ">", 100)
+			},
+			expected: "SELECT * FROM users LEFT JOIN (SELECT user_id, SUM(total) as spent FROM orders WHERE created_at > ? GROUP BY user_id) AS spending ON users.id = spending.user_id WHERE country = ? AND spending.spent > ?",
+			bindings: []interface{}{"2024-01-01", "ID", 100},
+		},
+		{
+			name: "RightJoinSub and CrossJoinSub",
+			build: func() *Builder {
+				regions := New(db).Table("regions").Where("enabled", "=", 1)
+				latest := New(db).Table("orders").OrderBy("id", "DESC").Limit(5)
+				return New(db).Table("users").
+					Where("id", ">", 10).
+					RightJoinSub(regions, "r", "users.region_id = r.id").
+					CrossJoinSub(latest, "latest")
+			},
+			expected: "SELECT * FROM users RIGHT JOIN (SELECT * FROM regions WHERE enabled = ?) AS r ON users.region_id = r.id CROSS JOIN (SELECT * FROM orders ORDER BY id DESC LIMIT ?) AS latest WHERE id > ?",
+			bindings: []interface{}{1, 5, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := tt.build()
+			sql := builder.ToSQL()
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+			if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, tt.bindings) {
+				t.Errorf("Expected bindings %v, got %v", tt.bindings, bindings)
+			}
+		})
+	}
+}
+
+func TestApplyAll(t *testing.T) {
+	status := "active"
+	minAge := 18
+
+	filters := []func(*Builder) *Builder{
+		func(q *Builder) *Builder { return q.Where("status", "=", status) },
+		nil,
+		func(q *Builder) *Builder { return q.Where("age", ">=", minAge) },
+		func(q *Builder) *Builder {
+			return q.When(true, func(q *Builder) { q.OrderBy("created_at", "DESC") })
+		},
+	}
+
+	builder := New(&MockDB{}).Table("users").ApplyAll(filters...)
+
+	expected := "SELECT * FROM users WHERE status = ? AND age >= ? ORDER BY created_at DESC"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{"active", 18}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+}
+
+func TestInRandomOrder(t *testing.T) {
+	page := func(n int) *Builder {
+		return New(&MockDB{}).Table("variants").
+			Where("experiment_id", "=", 3).
+			InRandomOrder(20240501).
+			Limit(10).
+			Offset((n - 1) * 10)
+	}
+
+	expected := "SELECT * FROM variants WHERE experiment_id = ? ORDER BY RAND(20240501) LIMIT ? OFFSET ?"
+	if sql := page(1).ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if page(2).ToSQL() != page(2).ToSQL() {
+		t.Error("Expected the same seed to generate identical SQL")
+	}
+
+	postgres := page(1).SetDialect(PostgresDialect).ToSQL()
+	expected = "SELECT * FROM variants WHERE experiment_id = $1 ORDER BY (SELECT setseed(0.009425217751413584)::text), RANDOM() LIMIT $2 OFFSET $3"
+	if postgres != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, postgres)
+	}
+	if postgres == page(1).SetDialect(PostgresDialect).InRandomOrder(7).ToSQL() {
+		t.Error("Expected another seed to generate another setseed")
+	}
+
+	if _, err := page(1).SetDialect(SQLiteDialect).Get(context.Background()); !errors.Is(err, ErrRandomSeedUnsupported) {
+		t.Errorf("Expected ErrRandomSeedUnsupported on SQLite, got %v", err)
+	}
+
+	other := New(&MockDB{}).Table("variants").InRandomOrder(7).OrderBy("id", "ASC").ToSQL()
+	if other != "SELECT * FROM variants ORDER BY RAND(7), id ASC" {
+		t.Errorf("Unexpected SQL: %s", other)
+	}
+}
+
+func TestInRandomOrderDialects(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected string
+	}{
+		{"Default", nil, "SELECT * FROM users ORDER BY RAND() LIMIT ?"},
+		{"MySQL", MySQLDialect, "SELECT * FROM users ORDER BY RAND() LIMIT ?"},
+		{"Postgres", PostgresDialect, "SELECT * FROM users ORDER BY RANDOM() LIMIT $1"},
+		{"SQLite", SQLiteDialect, "SELECT * FROM users ORDER BY RANDOM() LIMIT ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.dialect != nil {
+				opts = append(opts, tt.dialect)
+			}
+			if sql := New(&MockDB{}, opts...).Table("users").InRandomOrder().Limit(5).ToSQL(); sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+		})
+	}
+
+	replaced := New(&MockDB{}).Table("users").InRandomOrder(3).OrderBy("id", "ASC").InRandomOrder().ToSQL()
+	if replaced != "SELECT * FROM users ORDER BY RAND(), id ASC" {
+		t.Errorf("Expected the random order to be replaced, got %s", replaced)
+	}
+}
+
+func TestClone(t *testing.T) {
+	base := New(&MockDB{}).Table("users").
+		Select("id", "name").
+		Where("active", "=", true).
+		Join("teams", "teams.id = users.team_id").
+		GroupBy("team_id").
+		Having("COUNT(*)", ">", 1).
+		OrderBy("id", "ASC").
+		Limit(10).
+		Union(New(&MockDB{}).Table("admins").Where("level", "=", 3))
+	// Leave spare capacity so appends on a shared backing array would leak
+	base.columns = append(make([]string, 0, 8), base.columns...)
+	base.wheres = append(make([]where, 0, 8), base.wheres...)
+	base.bindings = append(make([]interface{}, 0, 8), base.bindings...)
+
+	sql := base.ToSQL()
+	bindings := base.GetBindings()
+
+	clone := base.Clone()
+	clone.Select("email").
+		Where("age", ">", 18).
+		Join("roles", "roles.id = users.role_id").
+		GroupBy("role_id").
+		Having("SUM(score)", ">", 5).
+		OrderBy("name", "DESC").
+		Limit(5).
+		Offset(20)
+	clone.unions[0].query.Where("region", "=", "eu")
+
+	if got := base.ToSQL(); got != sql {
+		t.Errorf("Original changed after modifying the clone:\n%s\n%s", sql, got)
+	}
+	if got := base.GetBindings(); !reflect.DeepEqual(got, bindings) {
+		t.Errorf("Original bindings changed: %v, expected %v", got, bindings)
+	}
+
+	expected := "SELECT id, name, email FROM users INNER JOIN teams ON teams.id = users.team_id INNER JOIN roles ON roles.id = users.role_id " +
+		"WHERE active = ? AND age > ? GROUP BY team_id, role_id HAVING COUNT(*) > ? AND SUM(score) > ? ORDER BY id ASC, name DESC LIMIT ? OFFSET ? " +
+		"UNION SELECT * FROM admins WHERE level = ? AND region = ?"
+	if got := clone.ToSQL(); got != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, got)
+	}
+	if got := clone.GetBindings(); !reflect.DeepEqual(got, []interface{}{true, 18, 1, 5, 5, 20, 3, "eu"}) {
+		t.Errorf("Unexpected clone bindings %v", got)
+	}
+}
+
+func TestCloneAsTemplate(t *testing.T) {
+	base := New(&MockDB{}).Table("orders").Where("tenant_id", "=", 7)
+	base.wheres = append(make([]where, 0, 8), base.wheres...)
+	base.bindings = append(make([]interface{}, 0, 8), base.bindings...)
+
+	open := base.Clone().Where("status", "=", "open").Limit(10)
+	count := base.Clone().Count("*")
+
+	tests := []struct {
+		name     string
+		builder  *Builder
+		sql      string
+		bindings []interface{}
+	}{
+		{"Base", base, "SELECT * FROM orders WHERE tenant_id = ?", []interface{}{7}},
+		{"Open", open, "SELECT * FROM orders WHERE tenant_id = ? AND status = ? LIMIT ?", []interface{}{7, "open", 10}},
+		{"Count", count, "SELECT COUNT(*) FROM orders WHERE tenant_id = ?", []interface{}{7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.builder.ToSQL(); got != tt.sql {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.sql, got)
+			}
+			if got := tt.builder.GetBindings(); !reflect.DeepEqual(got, tt.bindings) {
+				t.Errorf("Expected bindings %v, got %v", tt.bindings, got)
+			}
+		})
+	}
+
+	if base.limit != nil || base.offset != nil {
+		t.Error("Limit on a clone leaked into the original")
+	}
+}
+
+func TestDistinctOn(t *testing.T) {
+	builder := New(&MockDB{}, PostgresDialect).Table("orders").
+		DistinctOn("customer_id").
+		Select("customer_id", "total").
+		OrderBy("customer_id", "ASC").
+		OrderBy("created_at", "DESC")
+
+	expected := "SELECT DISTINCT ON (customer_id) customer_id, total FROM orders ORDER BY customer_id ASC, created_at DESC"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+
+	count := builder.Clone().Count("*")
+	expected = "SELECT COUNT(*) FROM (" + expected + ") AS sub"
+	if sql := count.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+
+	for _, dialect := range []Option{MySQLDialect, SQLiteDialect} {
+		_, err := New(&MockDB{}, dialect).Table("orders").DistinctOn("customer_id").Get(context.Background())
+		if !errors.Is(err, ErrDistinctOnUnsupported) {
+			t.Errorf("Expected ErrDistinctOnUnsupported, got %v", err)
+		}
+	}
+}
+
+func TestExists(t *testing.T) {
+	ctx := context.Background()
+	found := int64(1)
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		return &MockResultSet{Columns: []string{"exists"}, Rows: [][]interface{}{{found}}}, nil
+	})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("orders").
+		Join("users", "users.id = orders.user_id").
+		Where("status", "=", "open").
+		GroupBy("user_id")
+	sql := builder.ToSQL()
+	bindings := builder.GetBindings()
+
+	exists, err := builder.Exists(ctx)
+	if err != nil || !exists {
+		t.Fatalf("Expected true, got %v (%v)", exists, err)
+	}
+	found = 0
+	missing, err := builder.DoesntExist(ctx)
+	if err != nil || !missing {
+		t.Fatalf("Expected DoesntExist to be true, got %v (%v)", missing, err)
+	}
+
+	expected := "SELECT EXISTS(SELECT 1 FROM orders INNER JOIN users ON users.id = orders.user_id WHERE status = ? GROUP BY user_id LIMIT ?)"
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Query != expected {
+		t.Fatalf("Expected SQL: %s\nGot: %v", expected, calls)
+	}
+	if !reflect.DeepEqual(calls[0].Args, []interface{}{"open", int64(1)}) {
+		t.Errorf("Unexpected bindings %v", calls[0].Args)
+	}
+
+	if got := builder.ToSQL(); got != sql {
+		t.Errorf("Exists changed the builder:\n%s\n%s", sql, got)
+	}
+	if got := builder.GetBindings(); !reflect.DeepEqual(got, bindings) {
+		t.Errorf("Exists changed the bindings: %v, expected %v", got, bindings)
+	}
+}
+
+func TestExistsEmptyResult(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"exists"})
+	defer mock.DB.Close()
+
+	builder := New(mock.DB).Table("orders").
+		SelectRaw("total * ? AS net", 0.8).
+		WhereRaw("total > ?", 100).
+		WhereIn("status", "open", "paid")
+
+	exists, err := builder.Exists(ctx)
+	if err != nil || exists {
+		t.Fatalf("Expected false without rows, got %v (%v)", exists, err)
+	}
+	if missing, err := builder.DoesntExist(ctx); err != nil || !missing {
+		t.Fatalf("Expected DoesntExist to be true, got %v (%v)", missing, err)
+	}
+
+	calls := mock.Calls()
+	expected := "SELECT EXISTS(SELECT 1 FROM orders WHERE total > ? AND status IN (?, ?) LIMIT ?)"
+	if calls[0].Query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, calls[0].Query)
+	}
+	// The select bindings are dropped with the replaced columns
+	if want := []interface{}{int64(100), "open", "paid", int64(1)}; !reflect.DeepEqual(calls[0].Args, want) {
+		t.Errorf("Expected bindings %v, got %v", want, calls[0].Args)
+	}
+}
+
+func TestExistsUnion(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"exists"}, []interface{}{int64(1)})
+	defer mock.DB.Close()
+
+	admins := New(mock.DB).Table("admins").Select("id").Where("active", "=", true)
+	builder := New(mock.DB).Table("users").Select("id").Where("a", "=", 1).Union(admins)
+
+	if exists, err := builder.Exists(ctx); err != nil || !exists {
+		t.Fatalf("Expected true, got %v (%v)", exists, err)
+	}
+
+	// Every branch keeps its columns, a LIMIT would apply to the first only
+	expected := "SELECT EXISTS(SELECT id FROM users WHERE a = ? UNION SELECT id FROM admins WHERE active = ?)"
+	calls := mock.Calls()
+	if len(calls) != 1 || calls[0].Query != expected {
+		t.Fatalf("Expected SQL: %s\nGot: %v", expected, calls)
+	}
+	if want := []interface{}{int64(1), true}; !reflect.DeepEqual(calls[0].Args, want) {
+		t.Errorf("Expected bindings %v, got %v", want, calls[0].Args)
+	}
+}
+
+func TestExistsInTransaction(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		return &MockResultSet{Columns: []string{"exists"}, Rows: [][]interface{}{{true}}}, nil
+	})
+	defer mock.DB.Close()
+
+	var exists bool
+	err := New(mock.DB).Transaction(ctx, func(tx *Builder) error {
+		var err error
+		exists, err = tx.Table("orders").Where("id", "=", 1).Exists(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if !exists {
+		t.Error("Expected the row to exist")
+	}
+	if calls := mock.Calls(); len(calls) != 1 || !strings.HasPrefix(calls[0].Query, "SELECT EXISTS(") {
+		t.Errorf("Unexpected calls %v", calls)
+	}
+}
+
+func TestWhereNotEmpty(t *testing.T) {
+	var nilName *string
+	name := ""
+	builder := New(&MockDB{}).Table("orders").
+		WhereNotEmpty("status", "=", "paid").
+		WhereNotEmpty("customer", "=", "").
+		WhereNotEmpty("total", ">", 0).
+		WhereNotEmpty("region", "=", nil).
+		WhereNotEmpty("tags", "=", []string{}).
+		WhereNotEmpty("owner", "=", nilName).
+		WhereNotEmpty("note", "=", &name).
+		WhereNotEmpty("priority", ">=", 2)
+
+	expected := "SELECT * FROM orders WHERE status = ? AND note = ? AND priority >= ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if bindings := builder.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{"paid", &name, 2}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	db := &MockDB{}
+
+	builder := New(db).Table("orders").SelectDistinct("customer_id", "region").Where("status", "=", "paid")
+	expected := "SELECT DISTINCT customer_id, region FROM orders WHERE status = ?"
+	if sql := builder.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+
+	if sql := New(db).Table("orders").Distinct().ToSQL(); sql != "SELECT DISTINCT * FROM orders" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	count := builder.Clone().Count("*")
+	expected = "SELECT COUNT(*) FROM (SELECT DISTINCT customer_id, region FROM orders WHERE status = ?) AS sub"
+	if sql := count.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+	if bindings := count.GetBindings(); !reflect.DeepEqual(bindings, []interface{}{"paid"}) {
+		t.Errorf("Unexpected bindings %v", bindings)
+	}
+
+	if sql := New(db).Table("users").Distinct().ToSQL(); sql != "SELECT DISTINCT * FROM users" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if sql := New(db).Table("users").Distinct().Count("id").ToSQL(); sql != "SELECT COUNT(DISTINCT id) FROM users" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if sql := New(db, PostgresDialect, WithQuotedIdentifiers(true)).Table("users").Distinct().Count("users.id").ToSQL(); sql != `SELECT COUNT(DISTINCT "users"."id") FROM "users"` {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	quoted := New(db, MySQLDialect, WithQuotedIdentifiers(true)).Table("orders").SelectDistinct("region").Count("*")
+	expected = "SELECT COUNT(*) FROM (SELECT DISTINCT `region` FROM `orders`) AS sub"
+	if sql := quoted.ToSQL(); sql != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, sql)
+	}
+}
+
+func TestWriteColumnsAlignWithBindings(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	var args []interface{}
+	db := &MockDB{
+		execFunc: func(ctx context.Context, q string, a ...interface{}) (sql.Result, error) {
+			query, args = q, a
+			return MockResult{lastID: 1, rowsAffected: 1}, nil
+		},
+	}
+
+	data := make(map[string]interface{})
+	columns := make([]string, 10)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("col%d", i)
+		data[columns[i]] = "value-" + columns[i]
+	}
+	values := make([]interface{}, len(columns))
+	sets := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = data[column]
+		sets[i] = column + " = ?"
+	}
+
+	insertSQL := "INSERT INTO users (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	updateSQL := "UPDATE users SET " + strings.Join(sets, ", ")
+
+	for i := 0; i < 50; i++ {
+		if _, err := New(db).Table("users").InsertGetId(ctx, data); err != nil {
+			t.Fatalf("InsertGetId failed: %v", err)
+		}
+		if query != insertSQL || !reflect.DeepEqual(args, values) {
+			t.Fatalf("Insert misaligned:\n%s\n%v", query, args)
+		}
+
+		if _, err := New(db).Table("users").UpdateWithContext(ctx, data); err != nil {
+			t.Fatalf("UpdateWithContext failed: %v", err)
+		}
+		if query != updateSQL || !reflect.DeepEqual(args, values) {
+			t.Fatalf("Update misaligned:\n%s\n%v", query, args)
+		}
+	}
+}
+
+func TestBatchWritesAlignWithBindings(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	var args []interface{}
+	db := &MockDB{
+		execFunc: func(ctx context.Context, q string, a ...interface{}) (sql.Result, error) {
+			query, args = q, a
+			return MockResult{rowsAffected: 2}, nil
+		},
+	}
+
+	rows := []map[string]interface{}{
+		{"name": "ann", "email": "ann@example.com", "role": "admin", "id": 1},
+		{"name": "ben", "email": "ben@example.com", "role": "user", "id": 2},
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := New(db).Table("users").BatchInsert(ctx, rows); err != nil {
+			t.Fatalf("BatchInsert failed: %v", err)
+		}
+		expected := "INSERT INTO users (email, id, name, role) VALUES (?, ?, ?, ?), (?, ?, ?, ?)"
+		values := []interface{}{"ann@example.com", 1, "ann", "admin", "ben@example.com", 2, "ben", "user"}
+		if query != expected || !reflect.DeepEqual(args, values) {
+			t.Fatalf("BatchInsert misaligned:\n%s\n%v", query, args)
+		}
+
+		if err := New(db).Table("users").BulkUpdate(ctx, rows, "id"); err != nil {
+			t.Fatalf("BulkUpdate failed: %v", err)
+		}
+		expected = "UPDATE users SET email = CASE id WHEN ? THEN ? WHEN ? THEN ? END, " +
+			"name = CASE id WHEN ? THEN ? WHEN ? THEN ? END, " +
+			"role = CASE id WHEN ? THEN ? WHEN ? THEN ? END WHERE id IN (?,?)"
+		values = []interface{}{1, "ann@example.com", 2, "ben@example.com", 1, "ann", 2, "ben", 1, "admin", 2, "user", 1, 2}
+		if query != expected || !reflect.DeepEqual(args, values) {
+			t.Fatalf("BulkUpdate misaligned:\n%s\n%v", query, args)
+		}
+	}
+}
+
+func TestQueryEventHandlers(t *testing.T) {
+	ctx := context.Background()
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			if strings.HasPrefix(query, "DELETE") {
+				return nil, errors.New("table is locked")
+			}
+			return MockResult{lastID: 1, rowsAffected: 1}, nil
+		},
+	}
+
+	var before, after []QueryEvent
+	builder := New(db, PostgresDialect).
+		BeforeQuery(func(e *QueryEvent) { before = append(before, *e) }).
+		AfterQuery(func(e *QueryEvent) { after = append(after, *e) })
+
+	bindings := []interface{}{"paid"}
+	if _, err := builder.Clone().Table("orders").Where("status", "=", bindings[0]).Get(ctx); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := builder.Clone().Table("orders").InsertGetId(ctx, map[string]interface{}{"status": "new"}); err != nil {
+		t.Fatalf("InsertGetId failed: %v", err)
+	}
+	if _, err := builder.Clone().Table("orders").Where("id", "=", 1).UpdateWithContext(ctx, map[string]interface{}{"status": "paid"}); err != nil {
+		t.Fatalf("UpdateWithContext failed: %v", err)
+	}
+	if _, err := builder.Clone().Table("orders").DeleteWithContext(ctx); err == nil {
+		t.Fatal("Expected delete to fail")
+	}
+
+	expected := []string{
+		"SELECT * FROM orders WHERE status = $1",
+		"INSERT INTO orders (status) VALUES ($1)",
+		"UPDATE orders SET status = $1 WHERE id = $2",
+		"DELETE FROM orders",
+	}
+	if len(before) != len(expected) || len(after) != len(expected) {
+		t.Fatalf("Expected %d before and after events, got %d and %d", len(expected), len(before), len(after))
+	}
+	for i := range expected {
+		if before[i].SQL != expected[i] || after[i].SQL != expected[i] {
+			t.Errorf("Event %d: expected SQL %s, got %s / %s", i, expected[i], before[i].SQL, after[i].SQL)
+		}
+	}
+	if !reflect.DeepEqual(after[2].Bindings, []interface{}{"paid", 1}) {
+		t.Errorf("Unexpected update bindings %v", after[2].Bindings)
+	}
+	if after[3].Err == nil || after[0].Err != nil {
+		t.Errorf("Expected only the delete to record an error, got %v and %v", after[0].Err, after[3].Err)
+	}
+	if before[0].Duration != 0 {
+		t.Errorf("Expected no duration before the query, got %v", before[0].Duration)
+	}
+}
+
+func TestQueryEventHandlersInTransaction(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	var queries []string
+	builder := New(mock.DB).AfterQuery(func(e *QueryEvent) { queries = append(queries, e.SQL) })
+
+	err := builder.Transaction(ctx, func(tx *Builder) error {
+		_, err := tx.Table("orders").DeleteWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if !reflect.DeepEqual(queries, []string{"DELETE FROM orders"}) {
+		t.Errorf("Expected the transaction query to be observed, got %v", queries)
+	}
+}
+
+func TestInsertUsing(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	var args []interface{}
+	db := &MockDB{
+		execFunc: func(ctx context.Context, q string, a ...interface{}) (sql.Result, error) {
+			query, args = q, a
+			return MockResult{rowsAffected: 4}, nil
+		},
+	}
+
+	sub := New(db).Table("orders").
+		Select("orders.id", "orders.total", "users.email").
+		Join("users", "users.id = orders.user_id").
+		Where("orders.created_at", "<", "2024-01-01").
+		Where("users.active", "=", true).
+		OrderBy("orders.id", "ASC").
+		Limit(500)
+	inserted, err := New(db, PostgresDialect).Table("archived_orders").InsertUsing(ctx, []string{"id", "total", "email"}, sub)
+	if err != nil || inserted != 4 {
+		t.Fatalf("Expected 4 inserted rows, got %d (%v)", inserted, err)
+	}
+
+	expected := "INSERT INTO archived_orders (id, total, email) SELECT orders.id, orders.total, users.email FROM orders " +
+		"INNER JOIN users ON users.id = orders.user_id WHERE orders.created_at < $1 AND users.active = $2 " +
+		"ORDER BY orders.id ASC LIMIT $3"
+	if query != expected {
+		t.Errorf("Expected SQL: %s\nGot: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"2024-01-01", true, 500}) {
+		t.Errorf("Unexpected bindings %v", args)
+	}
+
+	if _, err := New(db).Table("archived_orders").InsertUsing(ctx, []string{"id"}, New(db).Table("orders").Select("id", "total")); err == nil {
+		t.Error("Expected error for mismatched column counts")
+	}
+	if _, err := New(db).Table("archived_orders").InsertUsing(ctx, []string{"id", "total"}, New(db).Table("orders").Select("orders.*")); err != nil {
+		t.Errorf("Expected wildcard select to skip the count check, got %v", err)
+	}
+	if query != "INSERT INTO archived_orders (id, total) SELECT orders.* FROM orders" {
+		t.Errorf("Unexpected SQL: %s", query)
+	}
+}
@@ -0,0 +1,251 @@
+// Package qixtest loads database fixtures for tests of code built on qix.
+package qixtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wibu-gaptek/qix"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownReference is returned when a fixture references a label that
+// no fixture defines
+var ErrUnknownReference = errors.New("unknown fixture reference")
+
+// fixtureSet holds the labeled rows of each table
+type fixtureSet map[string]map[string]map[string]interface{}
+
+// LoadFixtures inserts the fixtures found in dir and returns the primary
+// key of every row by label.
+//
+// Each file is named after its table (users.yml, users.yaml or users.json)
+// and maps row labels to column values. A string value "@label" is
+// replaced by the primary key of the row with that label, so
+// "author_id: @john" points a post at the user labeled john. Tables are
+// inserted in dependency order, derived from the registered models and
+// from the references themselves; rows of a table in label order.
+// Primary keys not given in the fixture are read from LastInsertId.
+func LoadFixtures(ctx context.Context, db qix.DB, dir string, opts ...qix.Option) (map[string]interface{}, error) {
+	set, err := readFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := insertOrder(set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{})
+	for _, table := range order {
+		pk := qix.TablePrimaryKey(table)
+		for _, label := range sortedLabels(set[table]) {
+			row := make(map[string]interface{}, len(set[table][label]))
+			for column, value := range set[table][label] {
+				if ref, ok := reference(value); ok {
+					key, found := keys[ref]
+					if !found {
+						return nil, fmt.Errorf("%w: %s.%s.%s = @%s", ErrUnknownReference, table, label, column, ref)
+					}
+					value = key
+				}
+				row[column] = value
+			}
+
+			id, err := qix.New(db, opts...).Table(table).InsertGetId(ctx, row)
+			if err != nil {
+				return nil, fmt.Errorf("insert fixture %s.%s: %w", table, label, err)
+			}
+			if explicit, ok := row[pk]; ok {
+				keys[label] = explicit
+			} else {
+				keys[label] = id
+			}
+		}
+	}
+	return keys, nil
+}
+
+// ResetTables deletes every row of the given tables, children before the
+// tables they reference
+func ResetTables(ctx context.Context, db qix.DB, tables ...string) error {
+	set := make(fixtureSet, len(tables))
+	for _, table := range tables {
+		set[table] = nil
+	}
+	order, err := insertOrder(set)
+	if err != nil {
+		return err
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		if _, err := qix.New(db).Table(order[i]).DeleteWithContext(ctx); err != nil {
+			return fmt.Errorf("reset %s: %w", order[i], err)
+		}
+	}
+	return nil
+}
+
+// readFixtures parses the fixture files of dir
+func readFixtures(dir string) (fixtureSet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(fixtureSet)
+	labels := make(map[string]string) // label -> table
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml" && ext != ".json") {
+			continue
+		}
+		table := strings.TrimSuffix(entry.Name(), ext)
+		if _, exists := set[table]; exists {
+			return nil, fmt.Errorf("duplicate fixture file for table %s", table)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rows, err := decodeFixture(data, ext)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", entry.Name(), err)
+		}
+
+		for label := range rows {
+			if other, exists := labels[label]; exists {
+				return nil, fmt.Errorf("fixture label %s is used by %s and %s", label, other, table)
+			}
+			labels[label] = table
+		}
+		set[table] = rows
+	}
+	return set, nil
+}
+
+// decodeFixture parses a fixture file, JSON numbers are kept as int64 when
+// they are integers so keys compare equal to the generated ones
+func decodeFixture(data []byte, ext string) (map[string]map[string]interface{}, error) {
+	var rows map[string]map[string]interface{}
+	if ext == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, err
+		}
+	} else if err := yaml.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for column, value := range row {
+			switch v := value.(type) {
+			case json.Number:
+				if n, err := v.Int64(); err == nil {
+					row[column] = n
+				} else if f, err := v.Float64(); err == nil {
+					row[column] = f
+				}
+			case int:
+				row[column] = int64(v)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// insertOrder sorts the tables so each comes after the tables it depends
+// on, ties are broken alphabetically
+func insertOrder(set fixtureSet) ([]string, error) {
+	owner := make(map[string]string) // label -> table
+	for table, rows := range set {
+		for label := range rows {
+			owner[label] = table
+		}
+	}
+
+	dependencies := make(map[string]map[string]bool, len(set))
+	for table, rows := range set {
+		dependencies[table] = make(map[string]bool)
+		for _, dependency := range qix.TableDependencies(table) {
+			if _, ok := set[dependency]; ok {
+				dependencies[table][dependency] = true
+			}
+		}
+		for _, row := range rows {
+			for _, value := range row {
+				if ref, ok := reference(value); ok && owner[ref] != "" && owner[ref] != table {
+					dependencies[table][owner[ref]] = true
+				}
+			}
+		}
+	}
+
+	tables := make([]string, 0, len(set))
+	for table := range set {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var order []string
+	done := make(map[string]bool, len(tables))
+	for len(order) < len(tables) {
+		progress := false
+		for _, table := range tables {
+			if done[table] {
+				continue
+			}
+			ready := true
+			for dependency := range dependencies[table] {
+				if !done[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, table)
+				done[table] = true
+				progress = true
+			}
+		}
+		if !progress {
+			var pending []string
+			for _, table := range tables {
+				if !done[table] {
+					pending = append(pending, table)
+				}
+			}
+			return nil, fmt.Errorf("circular fixture dependencies between %s", strings.Join(pending, ", "))
+		}
+	}
+	return order, nil
+}
+
+// reference returns the label of an "@label" value
+func reference(value interface{}) (string, bool) {
+	s, ok := value.(string)
+	if !ok || len(s) < 2 || s[0] != '@' {
+		return "", false
+	}
+	return s[1:], true
+}
+
+// sortedLabels returns the labels of a table's rows in order
+func sortedLabels(rows map[string]map[string]interface{}) []string {
+	labels := make([]string, 0, len(rows))
+	for label := range rows {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,120 @@
+package qixtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wibu-gaptek/qix"
+)
+
+// FixtureWriter is referenced by FixtureArticle
+type FixtureWriter struct {
+	ID   int64  `db:"id,pk,auto"`
+	Name string `db:"name"`
+}
+
+// FixtureArticle belongs to a FixtureWriter
+type FixtureArticle struct {
+	ID       int64         `db:"id,pk,auto"`
+	Title    string        `db:"title"`
+	AuthorID int64         `db:"author_id"`
+	Author   FixtureWriter `rel:"belongsTo,localKey:author_id,foreignKey:id"`
+}
+
+type execCall struct {
+	query string
+	args  []interface{}
+}
+
+// recordingDB records statements and hands out increasing insert ids
+type recordingDB struct {
+	calls  []execCall
+	nextID int64
+}
+
+func (db *recordingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (db *recordingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.calls = append(db.calls, execCall{query, args})
+	db.nextID++
+	return result(db.nextID), nil
+}
+
+type result int64
+
+func (r result) LastInsertId() (int64, error) { return int64(r), nil }
+func (r result) RowsAffected() (int64, error) { return 1, nil }
+
+func registerModels(t *testing.T, db qix.DB) {
+	t.Helper()
+	if _, err := qix.NewModel(db, &FixtureWriter{}); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+	if _, err := qix.NewModel(db, &FixtureArticle{}); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	db := &recordingDB{}
+	registerModels(t, db)
+
+	keys, err := LoadFixtures(context.Background(), db, "testdata")
+	if err != nil {
+		t.Fatalf("LoadFixtures failed: %v", err)
+	}
+
+	expectedKeys := map[string]interface{}{
+		"jane":      int64(42),
+		"john":      int64(2),
+		"follow_up": int64(3),
+		"intro":     int64(4),
+	}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, keys)
+	}
+
+	expected := []execCall{
+		{"INSERT INTO fixture_writer (id, name) VALUES (?, ?)", []interface{}{int64(42), "Jane"}},
+		{"INSERT INTO fixture_writer (name) VALUES (?)", []interface{}{"John"}},
+		{"INSERT INTO fixture_article (author_id, title, views) VALUES (?, ?, ?)", []interface{}{int64(42), "Follow up", int64(3)}},
+		{"INSERT INTO fixture_article (author_id, title) VALUES (?, ?)", []interface{}{int64(2), "Intro"}},
+	}
+	if !reflect.DeepEqual(db.calls, expected) {
+		t.Errorf("Expected statements:\n%v\nGot:\n%v", expected, db.calls)
+	}
+}
+
+func TestLoadFixturesUnknownReference(t *testing.T) {
+	_, err := LoadFixtures(context.Background(), &recordingDB{}, "testdata/broken")
+	if !errors.Is(err, ErrUnknownReference) {
+		t.Fatalf("Expected ErrUnknownReference, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "@nobody") {
+		t.Errorf("Expected the reference in the error, got %v", err)
+	}
+}
+
+func TestResetTables(t *testing.T) {
+	db := &recordingDB{}
+	registerModels(t, db)
+
+	if err := ResetTables(context.Background(), db, "fixture_writer", "fixture_article"); err != nil {
+		t.Fatalf("ResetTables failed: %v", err)
+	}
+
+	var queries []string
+	for _, call := range db.calls {
+		queries = append(queries, call.query)
+	}
+	expected := []string{"DELETE FROM fixture_article", "DELETE FROM fixture_writer"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("Expected %v, got %v", expected, queries)
+	}
+}
@@ -0,0 +1,139 @@
+package qix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidRelation is returned by RegisterModels for relations whose
+// target model or key columns can't be found
+var ErrInvalidRelation = errors.New("invalid relation")
+
+// RegisterModels registers the models of values up front, so the first
+// query doesn't pay for parsing them and relations resolve to their
+// registered metadata. Options among values, such as PostgresDialect,
+// apply to every model. The relations declared with rel tags are then
+// checked: their target must be one of values or already registered, and
+// their keys must be columns of the models holding them. Every problem
+// found is returned, joined.
+func RegisterModels(db DB, values ...interface{}) error {
+	var opts []Option
+	var models []interface{}
+	for _, value := range values {
+		if opt, ok := value.(Option); ok {
+			opts = append(opts, opt)
+		} else {
+			models = append(models, value)
+		}
+	}
+
+	var errs []error
+	registered := make([]*Model, 0, len(models))
+	for _, value := range models {
+		m, err := NewModel(db, value, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", value, err))
+			continue
+		}
+		registered = append(registered, m)
+	}
+	for _, m := range registered {
+		errs = append(errs, m.validateRelations()...)
+	}
+	return errors.Join(errs...)
+}
+
+// MustRegisterModels is RegisterModels for program initialization, it
+// panics when a model is invalid
+func MustRegisterModels(db DB, values ...interface{}) {
+	if err := RegisterModels(db, values...); err != nil {
+		panic(err)
+	}
+}
+
+// validateRelations checks the declared relations of m against the
+// registered models
+func (m *Model) validateRelations() []error {
+	var errs []error
+	for _, f := range m.fields {
+		rel := f.relation
+		if rel == nil || rel.inferred {
+			// Inferred relations may be plain struct columns
+			continue
+		}
+		name := m.structType().Name() + "." + f.name
+		target, ok := m.relManager.registry[rel.modelType]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s: target model %s is not registered",
+				ErrInvalidRelation, name, rel.modelType.Name()))
+			continue
+		}
+		switch rel.relType {
+		case relationHasOne, relationHasMany:
+			if !hasColumn(target, rel.foreignKey) {
+				errs = append(errs, fmt.Errorf("%w: %s: foreign key %q is not a column of %s",
+					ErrInvalidRelation, name, rel.foreignKey, rel.modelType.Name()))
+			}
+		case relationBelongsTo:
+			if !hasColumn(m, rel.localKey) {
+				errs = append(errs, fmt.Errorf("%w: %s: local key %q is not a column of %s",
+					ErrInvalidRelation, name, rel.localKey, m.structType().Name()))
+			}
+			if !hasColumn(target, rel.foreignKey) {
+				errs = append(errs, fmt.Errorf("%w: %s: foreign key %q is not a column of %s",
+					ErrInvalidRelation, name, rel.foreignKey, rel.modelType.Name()))
+			}
+		}
+	}
+	return errs
+}
+
+// hasColumn reports whether m maps column to a field
+func hasColumn(m *Model, column string) bool {
+	for _, f := range m.fields {
+		if f.relation == nil && f.column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// TableDependencies returns the tables the rows of table reference
+// according to the registered models: the targets of its belongsTo
+// relations and the owners of hasOne/hasMany relations pointing at it.
+// Inserting the dependencies first satisfies the foreign keys.
+func TableDependencies(table string) []string {
+	seen := make(map[string]bool)
+	for _, m := range globalRelManager.registry {
+		for _, f := range m.fields {
+			rel := f.relation
+			if rel == nil {
+				continue
+			}
+			switch {
+			case m.table == table && rel.relType == relationBelongsTo:
+				seen[rel.targetTable] = true
+			case rel.targetTable == table && (rel.relType == relationHasOne || rel.relType == relationHasMany):
+				seen[m.table] = true
+			}
+		}
+	}
+	delete(seen, table)
+
+	tables := make([]string, 0, len(seen))
+	for dependency := range seen {
+		tables = append(tables, dependency)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// TablePrimaryKey returns the primary key column of the registered model
+// of table, "id" when no model is registered for it
+func TablePrimaryKey(table string) string {
+	if m, ok := globalRelManager.modelCache[table]; ok {
+		return m.pk
+	}
+	return "id"
+}
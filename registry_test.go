@@ -0,0 +1,91 @@
+package qix
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableDependencies(t *testing.T) {
+	for _, value := range []interface{}{&IncArticle{}, &IncComment{}, &IncAuthor{}} {
+		if _, err := NewModel(&MockDB{}, value); err != nil {
+			t.Fatalf("Failed to create model: %v", err)
+		}
+	}
+
+	// Comments belong to authors and are owned by articles through hasMany
+	if deps := TableDependencies("inc_comment"); !reflect.DeepEqual(deps, []string{"inc_article", "inc_author"}) {
+		t.Errorf("Unexpected dependencies %v", deps)
+	}
+	if deps := TableDependencies("inc_author"); len(deps) != 0 {
+		t.Errorf("Expected no dependencies, got %v", deps)
+	}
+	if pk := TablePrimaryKey("inc_author"); pk != "id" {
+		t.Errorf("Expected id, got %s", pk)
+	}
+}
+
+type RegOwner struct {
+	ID      int64      `db:"id,pk"`
+	Pets    []RegPet   `rel:"hasMany,foreignKey:owner_id"`
+	Toys    []RegToy   `rel:"hasMany"`
+	Address RegAddress `rel:"hasOne"`
+}
+
+type RegPet struct {
+	ID      int64     `db:"id,pk"`
+	OwnerID int64     `db:"owner_id"`
+	Owner   *RegOwner `rel:"belongsTo,localKey:owner_id"`
+	Vet     *RegVet   `rel:"belongsTo"`
+	Toy     *RegToy   `rel:"belongsTo"`
+}
+
+type RegToy struct {
+	ID int64 `db:"id,pk"`
+}
+
+// RegAddress and RegVet are never registered
+type RegAddress struct {
+	ID int64 `db:"id,pk"`
+}
+
+type RegVet struct {
+	ID int64 `db:"id,pk"`
+}
+
+func TestRegisterModels(t *testing.T) {
+	err := RegisterModels(&MockDB{}, PostgresDialect, RegOwner{}, RegPet{}, RegToy{})
+	if !errors.Is(err, ErrInvalidRelation) {
+		t.Fatalf("Expected ErrInvalidRelation, got %v", err)
+	}
+	for _, problem := range []string{
+		"RegOwner.Address: target model RegAddress is not registered",
+		`RegOwner.Toys: foreign key "reg_owner_id" is not a column of RegToy`,
+		"RegPet.Vet: target model RegVet is not registered",
+		`RegPet.Toy: local key "toy_id" is not a column of RegPet`,
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Expected %q in %v", problem, err)
+		}
+	}
+	if strings.Count(err.Error(), "\n") != 3 {
+		t.Errorf("Expected exactly 4 problems, got %v", err)
+	}
+
+	// Models are registered despite the problems, with the options applied
+	owner, ok := globalRelManager.registry[reflect.TypeOf(RegOwner{})]
+	if !ok || owner.builder.dialect != PostgresDialect {
+		t.Error("Expected RegOwner registered with the Postgres dialect")
+	}
+
+	if err := RegisterModels(&MockDB{}, RegToy{}, RegPet{}); err == nil || strings.Contains(err.Error(), "RegOwner") {
+		t.Errorf("Expected only the pet relations to fail, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustRegisterModels to panic")
+		}
+	}()
+	MustRegisterModels(&MockDB{}, RegPet{})
+}
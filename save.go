@@ -0,0 +1,316 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SaveStatus is the outcome of saving one row
+type SaveStatus int
+
+const (
+	SaveSkipped    SaveStatus = iota // Not attempted because an earlier row failed
+	SaveCreated                      // Inserted
+	SaveUpdated                      // Updated by primary key
+	SaveFailed                       // Returned an error
+	SaveRolledBack                   // Saved, then undone when its chunk was rolled back
+)
+
+func (s SaveStatus) String() string {
+	switch s {
+	case SaveCreated:
+		return "created"
+	case SaveUpdated:
+		return "updated"
+	case SaveFailed:
+		return "failed"
+	case SaveRolledBack:
+		return "rolled back"
+	}
+	return "skipped"
+}
+
+// SaveResult is the outcome of one row of SaveMany
+type SaveResult struct {
+	Index  int
+	Status SaveStatus
+	Err    error
+}
+
+// SaveReport lists the outcome of every row passed to SaveMany, by index
+type SaveReport struct {
+	Results []SaveResult
+}
+
+// Failed returns the rows that returned an error
+func (r *SaveReport) Failed() []SaveResult {
+	var failed []SaveResult
+	for _, result := range r.Results {
+		if result.Status == SaveFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Count returns how many rows ended with the given status
+func (r *SaveReport) Count(status SaveStatus) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// SaveOption configures SaveMany
+type SaveOption func(*saveConfig)
+
+type saveConfig struct {
+	chunkSize       int
+	continueOnError bool
+}
+
+// SaveChunkSize sets how many rows are saved per transaction (default 100)
+func SaveChunkSize(size int) SaveOption {
+	return func(c *saveConfig) {
+		if size > 0 {
+			c.chunkSize = size
+		}
+	}
+}
+
+// ContinueOnError saves every row independently and records failures in
+// the report instead of rolling back the chunk and stopping
+func ContinueOnError() SaveOption {
+	return func(c *saveConfig) {
+		c.continueOnError = true
+	}
+}
+
+// Save inserts data when its primary key is zero and updates it otherwise.
+// On insert the generated id is written back when data is a pointer.
+func (m *Model) Save(ctx context.Context, data interface{}) (created bool, err error) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return false, errors.New("data must be a struct or pointer to struct")
+	}
+
+	pkField := v.FieldByName(getPkFieldName(m.fields, m.pk))
+	if pkField.IsValid() && !pkField.IsZero() {
+		_, err := m.Update(ctx, data)
+		return false, err
+	}
+
+	id, err := m.Create(ctx, data)
+	if err != nil {
+		return false, err
+	}
+	if pkField.IsValid() && pkField.CanSet() && id != 0 {
+		switch pkField.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			pkField.SetInt(id)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			pkField.SetUint(uint64(id))
+		}
+	}
+	return true, nil
+}
+
+// SaveMany saves every element of slice, chunk by chunk. Rows without a
+// primary key are created with Save. On MySQL and Postgres the rows with a
+// primary key are written by a single upsert per chunk, other dialects
+// update them one by one with Save. Each chunk runs in a transaction: by
+// default the first failure rolls back its chunk and stops, earlier chunks
+// stay committed. With ContinueOnError rows are saved independently and the
+// returned error is nil, failures are listed in the report. Validation and
+// timestamps run for every row.
+func (m *Model) SaveMany(ctx context.Context, slice interface{}, opts ...SaveOption) (*SaveReport, error) {
+	cfg := saveConfig{chunkSize: 100}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	v := reflect.ValueOf(slice)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.New("SaveMany expects a slice of structs")
+	}
+
+	report := &SaveReport{Results: make([]SaveResult, v.Len())}
+	for i := range report.Results {
+		report.Results[i] = SaveResult{Index: i, Status: SaveSkipped}
+	}
+
+	for start := 0; start < v.Len(); start += cfg.chunkSize {
+		end := start + cfg.chunkSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+
+		if cfg.continueOnError {
+			m.saveChunk(ctx, v, start, end, report.Results, false)
+			continue
+		}
+
+		failed := -1
+		err := m.inTransaction(ctx, func(tx *Model) error {
+			var err error
+			failed, err = tx.saveChunk(ctx, v, start, end, report.Results, true)
+			return err
+		})
+		if err != nil {
+			for i := start; i < end; i++ {
+				if report.Results[i].Status == SaveCreated || report.Results[i].Status == SaveUpdated {
+					report.Results[i].Status = SaveRolledBack
+				}
+			}
+			if failed < 0 {
+				return report, err
+			}
+			return report, fmt.Errorf("SaveMany stopped at row %d: %w", failed, err)
+		}
+	}
+
+	return report, nil
+}
+
+// saveChunk saves the rows of v from start to end. Rows with a primary key
+// are upserted together after the others when the dialect allows it. With
+// stop it returns the index and error of the first failure, -1 otherwise;
+// without it a failed upsert is retried row by row so failures are isolated.
+func (m *Model) saveChunk(ctx context.Context, v reflect.Value, start, end int, results []SaveResult, stop bool) (int, error) {
+	var pending []int
+	var rows []map[string]interface{}
+	for i := start; i < end; i++ {
+		row := saveTarget(v.Index(i))
+		if m.upsertsRows() && m.hasPrimaryKey(row) {
+			values, err := m.upsertValues(row)
+			if err == nil {
+				pending = append(pending, i)
+				rows = append(rows, values)
+				continue
+			}
+			results[i].Status, results[i].Err = SaveFailed, err
+			if stop {
+				return i, err
+			}
+			continue
+		}
+		if err := m.saveResult(ctx, row, &results[i]); err != nil && stop {
+			return i, err
+		}
+	}
+	if len(pending) == 0 {
+		return -1, nil
+	}
+
+	// Upsert needs the same columns in every row, omitempty fields may differ
+	uniform := true
+	for _, values := range rows[1:] {
+		uniform = uniform && sameKeys(values, rows[0])
+	}
+	if uniform {
+		err := m.writeQuery().Upsert(ctx, rows, []string{m.pk}, nil)
+		if err == nil {
+			for _, i := range pending {
+				results[i].Status = SaveUpdated
+			}
+			return -1, nil
+		}
+		if stop {
+			// The statement is atomic, no row of it was saved
+			for _, i := range pending {
+				results[i].Status, results[i].Err = SaveFailed, err
+			}
+			return pending[0], err
+		}
+	}
+
+	for _, i := range pending {
+		if err := m.saveResult(ctx, saveTarget(v.Index(i)), &results[i]); err != nil && stop {
+			return i, err
+		}
+	}
+	return -1, nil
+}
+
+// upsertsRows reports whether SaveMany can upsert existing rows in one
+// statement. Tracked models write only dirty columns and watched tables
+// publish the previous values, both need Update row by row.
+func (m *Model) upsertsRows() bool {
+	if m.tracker != nil || Events().watches(m.table) {
+		return false
+	}
+	switch m.builder.dialect.(type) {
+	case nil, mysqlDialect, postgresDialect:
+		return true
+	}
+	return false
+}
+
+// hasPrimaryKey reports whether the primary key of a struct row is set
+func (m *Model) hasPrimaryKey(row interface{}) bool {
+	v := reflect.Indirect(reflect.ValueOf(row))
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	pkField := v.FieldByName(getPkFieldName(m.fields, m.pk))
+	return pkField.IsValid() && !pkField.IsZero()
+}
+
+// upsertValues validates an existing row and returns the columns it is
+// upserted with, its updated at timestamp refreshed
+func (m *Model) upsertValues(row interface{}) (map[string]interface{}, error) {
+	if err := m.validate(row); err != nil {
+		return nil, err
+	}
+	values, err := m.extractValues(row, false)
+	if err != nil {
+		return nil, err
+	}
+	m.touch(row, values, m.timestampField(true), time.Now(), false)
+	return values, nil
+}
+
+// saveResult saves one row and records its outcome
+func (m *Model) saveResult(ctx context.Context, row interface{}, result *SaveResult) error {
+	created, err := m.Save(ctx, row)
+	switch {
+	case err != nil:
+		result.Status = SaveFailed
+		result.Err = err
+	case created:
+		result.Status = SaveCreated
+	default:
+		result.Status = SaveUpdated
+	}
+	return err
+}
+
+// inTransaction runs fn in a transaction when the connection supports one
+func (m *Model) inTransaction(ctx context.Context, fn func(*Model) error) error {
+	switch m.builder.db.(type) {
+	case *sql.Tx, TxDB:
+		return m.Transaction(ctx, fn)
+	}
+	return fn(m)
+}
+
+// saveTarget returns an addressable row so generated ids can be written back
+func saveTarget(row reflect.Value) interface{} {
+	if row.Kind() != reflect.Ptr && row.CanAddr() {
+		return row.Addr().Interface()
+	}
+	return row.Interface()
+}
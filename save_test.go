@@ -0,0 +1,190 @@
+package qix
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ImportRow is a model used by the batch save tests
+type ImportRow struct {
+	ID   int    `db:"id,pk,auto"`
+	Name string `db:"name" validate:"required"`
+}
+
+// insertResult reports a generated id from the mock driver
+type insertResult int64
+
+func (r insertResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r insertResult) RowsAffected() (int64, error) { return 1, nil }
+
+// newImportMock fails every statement that binds the name "boom"
+func newImportMock() *MockSQL {
+	var nextID int64 = 100
+	return NewMockSQL().OnExec(func(ctx context.Context, query string, args []interface{}) (driver.Result, error) {
+		for _, arg := range args {
+			if arg == "boom" {
+				return nil, errors.New("duplicate entry")
+			}
+		}
+		nextID++
+		return insertResult(nextID), nil
+	})
+}
+
+func importRows() []ImportRow {
+	return []ImportRow{
+		{Name: "a"},
+		{ID: 7, Name: "b"},
+		{Name: "boom"},
+		{Name: "d"},
+		{Name: "e"},
+	}
+}
+
+func TestSaveManyAbortsOnFailure(t *testing.T) {
+	mock := newImportMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, ImportRow{})
+	rows := importRows()
+
+	report, err := model.SaveMany(context.Background(), rows, SaveChunkSize(2))
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("Expected failure at row 2, got %v", err)
+	}
+
+	expected := []SaveStatus{SaveCreated, SaveUpdated, SaveFailed, SaveSkipped, SaveSkipped}
+	for i, status := range expected {
+		if report.Results[i].Status != status {
+			t.Errorf("Row %d: expected %s, got %s", i, status, report.Results[i].Status)
+		}
+	}
+	if report.Results[2].Err == nil {
+		t.Error("Expected the failed row to carry its error")
+	}
+	if rows[0].ID == 0 {
+		t.Error("Expected the generated id to be written back")
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if mock.commits != 1 || mock.rollbacks != 1 {
+		t.Errorf("Expected 1 commit and 1 rollback, got %d and %d", mock.commits, mock.rollbacks)
+	}
+}
+
+func TestSaveManyRollsBackChunk(t *testing.T) {
+	mock := newImportMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, ImportRow{})
+	rows := []ImportRow{{Name: "a"}, {Name: "boom"}}
+
+	report, err := model.SaveMany(context.Background(), &rows)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if report.Results[0].Status != SaveRolledBack {
+		t.Errorf("Expected row 0 to be rolled back, got %s", report.Results[0].Status)
+	}
+}
+
+func TestSaveManyContinueOnError(t *testing.T) {
+	mock := newImportMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, ImportRow{})
+	rows := importRows()
+	rows[4].Name = "" // fails validation before reaching the database
+
+	report, err := model.SaveMany(context.Background(), rows, SaveChunkSize(2), ContinueOnError())
+	if err != nil {
+		t.Fatalf("Expected no error in continue mode, got %v", err)
+	}
+
+	expected := []SaveStatus{SaveCreated, SaveUpdated, SaveFailed, SaveCreated, SaveFailed}
+	for i, status := range expected {
+		if report.Results[i].Status != status {
+			t.Errorf("Row %d: expected %s, got %s", i, status, report.Results[i].Status)
+		}
+	}
+
+	failed := report.Failed()
+	if len(failed) != 2 || failed[0].Index != 2 || failed[1].Index != 4 {
+		t.Fatalf("Unexpected failures: %+v", failed)
+	}
+	var validation ValidationErrors
+	if !errors.As(failed[1].Err, &validation) {
+		t.Errorf("Expected a validation error for row 4, got %v", failed[1].Err)
+	}
+	if report.Count(SaveCreated) != 2 {
+		t.Errorf("Expected 2 created rows, got %d", report.Count(SaveCreated))
+	}
+
+	for _, call := range mock.Calls() {
+		if strings.Count(call.Query, "?") != len(call.Args) {
+			t.Errorf("Placeholder/argument mismatch in %s %v", call.Query, call.Args)
+		}
+	}
+}
+
+func TestSaveManyUpsertsExistingRows(t *testing.T) {
+	mock := newImportMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, ImportRow{}, PostgresDialect)
+	rows := []ImportRow{{Name: "a"}, {ID: 7, Name: "b"}, {ID: 8, Name: "c"}, {ID: 9, Name: "boom"}}
+
+	report, err := model.SaveMany(context.Background(), rows[:3])
+	if err != nil {
+		t.Fatalf("SaveMany failed: %v", err)
+	}
+	expected := []SaveStatus{SaveCreated, SaveUpdated, SaveUpdated}
+	for i, status := range expected {
+		if report.Results[i].Status != status {
+			t.Errorf("Row %d: expected %s, got %s", i, status, report.Results[i].Status)
+		}
+	}
+
+	calls := mock.Calls()
+	upsert := "INSERT INTO import_row (id, name) VALUES ($1, $2), ($3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name"
+	if len(calls) != 2 || !strings.HasPrefix(calls[0].Query, "INSERT INTO import_row (name)") || calls[1].Query != upsert {
+		t.Fatalf("Expected an insert and one upsert, got %+v", calls)
+	}
+
+	// A failed upsert is retried row by row in continue mode
+	report, err = model.SaveMany(context.Background(), rows[1:], ContinueOnError())
+	if err != nil {
+		t.Fatalf("Expected no error in continue mode, got %v", err)
+	}
+	expected = []SaveStatus{SaveUpdated, SaveUpdated, SaveFailed}
+	for i, status := range expected {
+		if report.Results[i].Status != status {
+			t.Errorf("Row %d: expected %s, got %s", i, status, report.Results[i].Status)
+		}
+	}
+}
+
+func TestSaveManyUpdatesRowByRowOnSQLite(t *testing.T) {
+	mock := newImportMock()
+	defer mock.DB.Close()
+
+	model, _ := NewModel(mock.DB, ImportRow{}, SQLiteDialect)
+	rows := []ImportRow{{ID: 7, Name: "b"}, {ID: 8, Name: "c"}}
+
+	if _, err := model.SaveMany(context.Background(), rows); err != nil {
+		t.Fatalf("SaveMany failed: %v", err)
+	}
+	calls := mock.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected one update per row, got %+v", calls)
+	}
+	for _, call := range calls {
+		if !strings.HasPrefix(call.Query, "UPDATE ") {
+			t.Errorf("Expected an update, got %s", call.Query)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ScanOne advances rows to the next row and scans it into dest, a pointer
+// to a struct. Columns are matched to fields by db tag, or the snake_case
+// field name when the tag has no name; unmatched columns are ignored.
+// NULL leaves value fields at their zero value and pointer fields nil.
+// It returns sql.ErrNoRows when there is no row. The caller closes rows.
+func ScanOne(rows *sql.Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("destination must be a pointer to struct")
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	return scanStruct(rows, columns, structColumns(v.Elem().Type()), v.Elem())
+}
+
+// ScanAll scans every remaining row into dest, a pointer to a slice of
+// structs or struct pointers, using the same mapping as ScanOne. The
+// caller closes rows.
+func ScanAll(rows *sql.Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.New("destination must be a pointer to slice")
+	}
+
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct elements, got %s", structType.Kind())
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	fields := structColumns(structType)
+
+	for rows.Next() {
+		item := reflect.New(structType)
+		if err := scanStruct(rows, columns, fields, item.Elem()); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// GetInto runs the query and scans every row into dest, a pointer to a
+// slice of structs or struct pointers, mapping columns as ScanAll does.
+// dest is replaced, and is an empty slice when no row matches.
+func (b *Builder) GetInto(ctx context.Context, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.New("destination must be a pointer to slice")
+	}
+
+	rows, err := b.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	slice := v.Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	return ScanAll(rows, dest)
+}
+
+// GetTyped runs the query and returns its rows as T values, a struct
+// mapped as ScanAll does. No match returns an empty slice.
+func GetTyped[T any](ctx context.Context, b *Builder) ([]T, error) {
+	items := []T{}
+	if err := b.GetInto(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FirstTyped returns the first row of the query as a T, a struct mapped
+// as ScanOne does. It returns sql.ErrNoRows when nothing matches.
+func FirstTyped[T any](ctx context.Context, b *Builder) (T, error) {
+	var item T
+	rows, err := b.First(ctx)
+	if err != nil {
+		return item, err
+	}
+	defer rows.Close()
+
+	if err := ScanOne(rows, &item); err != nil {
+		var zero T
+		return zero, err
+	}
+	return item, nil
+}
+
+// structColumns maps column names to the index path of the struct field
+// they are scanned into, embedded structs included
+func structColumns(t reflect.Type) map[string][]int {
+	columns := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for column, index := range structColumns(field.Type) {
+				if _, ok := columns[column]; !ok {
+					columns[column] = append([]int{i}, index...)
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = toSnakeCase(field.Name)
+		}
+		columns[name] = []int{i}
+	}
+	return columns
+}
+
+// scanStruct scans the current row into the fields of v
+func scanStruct(rows *sql.Rows, columns []string, fields map[string][]int, v reflect.Value) error {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		index, ok := fields[column]
+		if !ok {
+			values[i] = new(interface{})
+			continue
+		}
+
+		field := v.FieldByIndex(index)
+		if field.Kind() == reflect.Ptr {
+			// database/sql sets pointer fields to nil on NULL
+			values[i] = field.Addr().Interface()
+		} else {
+			values[i] = reflect.New(reflect.PointerTo(field.Type())).Interface()
+		}
+	}
+
+	if err := rows.Scan(values...); err != nil {
+		return err
+	}
+
+	for i, column := range columns {
+		index, ok := fields[column]
+		if !ok {
+			continue
+		}
+		field := v.FieldByIndex(index)
+		if field.Kind() == reflect.Ptr {
+			continue
+		}
+
+		scanned := reflect.ValueOf(values[i]).Elem()
+		if scanned.IsNil() {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(scanned.Elem())
+		}
+	}
+	return nil
+}
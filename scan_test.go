@@ -0,0 +1,186 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type scanAudit struct {
+	CreatedBy string
+}
+
+type scanReport struct {
+	scanAudit
+	ID      int64   `db:"id"`
+	Region  string  `db:"region"`
+	Total   float64 `db:"total_amount"`
+	Note    *string `db:"note"`
+	Skipped string  `db:"-"`
+}
+
+// scanRows returns real *sql.Rows over the given result set
+func scanRows(t *testing.T, set *MockResultSet) *sql.Rows {
+	t.Helper()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		return set, nil
+	})
+	t.Cleanup(func() { mock.DB.Close() })
+
+	rows, err := mock.DB.QueryContext(context.Background(), "SELECT")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	t.Cleanup(func() { rows.Close() })
+	return rows
+}
+
+func TestScanOne(t *testing.T) {
+	rows := scanRows(t, &MockResultSet{
+		Columns: []string{"id", "region", "total_amount", "note", "created_by", "unknown"},
+		Rows:    [][]interface{}{{int64(7), "eu", 12.5, "late", "ops", "x"}},
+	})
+
+	report := scanReport{Skipped: "keep"}
+	if err := ScanOne(rows, &report); err != nil {
+		t.Fatalf("ScanOne failed: %v", err)
+	}
+
+	note := "late"
+	expected := scanReport{scanAudit: scanAudit{CreatedBy: "ops"}, ID: 7, Region: "eu", Total: 12.5, Note: &note, Skipped: "keep"}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, report)
+	}
+
+	if err := ScanOne(rows, &report); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+	if err := ScanOne(rows, report); err == nil {
+		t.Error("Expected error for non-pointer destination")
+	}
+}
+
+func TestScanAll(t *testing.T) {
+	set := &MockResultSet{
+		Columns: []string{"id", "region", "total_amount", "note"},
+		Rows: [][]interface{}{
+			{int64(1), "eu", 3.0, "first"},
+			{int64(2), nil, nil, nil},
+		},
+	}
+	note := "first"
+	expected := []scanReport{
+		{ID: 1, Region: "eu", Total: 3, Note: &note},
+		{ID: 2},
+	}
+
+	var reports []scanReport
+	if err := ScanAll(scanRows(t, set), &reports); err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, reports)
+	}
+
+	var pointers []*scanReport
+	if err := ScanAll(scanRows(t, set), &pointers); err != nil {
+		t.Fatalf("ScanAll failed: %v", err)
+	}
+	if len(pointers) != 2 || !reflect.DeepEqual(*pointers[1], expected[1]) {
+		t.Errorf("Unexpected pointer results %+v", pointers)
+	}
+
+	var ids []int64
+	if err := ScanAll(scanRows(t, set), &ids); err == nil {
+		t.Error("Expected error for non-struct elements")
+	}
+}
+
+func TestGetInto(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	set := &MockResultSet{
+		Columns: []string{"id", "name", "email", "created_at", "login_count"},
+		Rows: [][]interface{}{
+			{int64(1), "Ann", "ann@example.com", created, int64(3)},
+			{int64(2), "Ben", "ben@example.com", created, int64(5)},
+		},
+	}
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		return set, nil
+	})
+	defer mock.DB.Close()
+
+	users := []TestUser{{Name: "stale"}}
+	if err := New(mock.DB).Table("users").OrderBy("id", "ASC").GetInto(ctx, &users); err != nil {
+		t.Fatalf("GetInto failed: %v", err)
+	}
+	expected := []TestUser{
+		{ID: 1, Name: "Ann", Email: "ann@example.com", CreatedAt: created},
+		{ID: 2, Name: "Ben", Email: "ben@example.com", CreatedAt: created},
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, users)
+	}
+
+	set.Rows = nil
+	var none []*TestUser
+	if err := New(mock.DB).Table("users").GetInto(ctx, &none); err != nil {
+		t.Fatalf("GetInto failed: %v", err)
+	}
+	if none == nil || len(none) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", none)
+	}
+
+	if err := New(mock.DB).Table("users").GetInto(ctx, users); err == nil {
+		t.Error("Expected an error for a non-pointer destination")
+	}
+}
+
+func TestTypedQueries(t *testing.T) {
+	ctx := context.Background()
+	set := &MockResultSet{
+		Columns: []string{"id", "name", "email"},
+		Rows: [][]interface{}{
+			{int64(1), "Ann", "ann@example.com"},
+			{int64(2), "Ben", "ben@example.com"},
+		},
+	}
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		return set, nil
+	})
+	defer mock.DB.Close()
+
+	users, err := GetTyped[TestUser](ctx, New(mock.DB).Table("users"))
+	if err != nil {
+		t.Fatalf("GetTyped failed: %v", err)
+	}
+	if reflect.TypeOf(users) != reflect.TypeOf([]TestUser{}) {
+		t.Fatalf("Expected []TestUser, got %T", users)
+	}
+	if len(users) != 2 || users[1].Name != "Ben" || users[1].ID != 2 {
+		t.Errorf("Unexpected users %+v", users)
+	}
+
+	user, err := FirstTyped[TestUser](ctx, New(mock.DB).Table("users").Where("id", "=", 1))
+	if err != nil {
+		t.Fatalf("FirstTyped failed: %v", err)
+	}
+	if user.Name != "Ann" || user.Email != "ann@example.com" {
+		t.Errorf("Unexpected user %+v", user)
+	}
+	if calls := mock.Calls(); calls[1].Query != "SELECT * FROM users WHERE id = ? LIMIT ?" {
+		t.Errorf("Unexpected FirstTyped SQL: %s", calls[1].Query)
+	}
+
+	set.Rows = nil
+	if _, err := FirstTyped[TestUser](ctx, New(mock.DB).Table("users")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+	if users, err := GetTyped[TestUser](ctx, New(mock.DB).Table("users")); err != nil || users == nil || len(users) != 0 {
+		t.Errorf("Expected an empty slice, got %#v (%v)", users, err)
+	}
+}
@@ -0,0 +1,62 @@
+package qix
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InSchema qualifies the table of the query, and of later Table calls,
+// with a schema (a database on MySQL): Table("events").InSchema("analytics")
+// reads analytics.events. Tables that are already qualified are kept.
+func (b *Builder) InSchema(schema string) *Builder {
+	if cr, err := ParseColumnRef(schema); err != nil || len(cr.Parts) != 1 || cr.Alias != "" {
+		err := fmt.Errorf("%w: schema %q", ErrInvalidIdentifier, schema)
+		if b.strictIdentifiers {
+			panic(err)
+		}
+		b.setErr(err)
+		return b
+	}
+	b.schema = schema
+	if b.table != "" {
+		b.table = qualifyTable(schema, b.table)
+	}
+	return b
+}
+
+// qualifyTable prefixes an unqualified table reference with schema, an
+// alias is kept: users u becomes schema.users u
+func qualifyTable(schema, table string) string {
+	if schema == "" {
+		return table
+	}
+	cr, err := ParseColumnRef(table)
+	if err != nil || len(cr.Parts) != 1 {
+		return table
+	}
+	return schema + "." + strings.TrimSpace(table)
+}
+
+// SetSchema sets the schema of the model table, queries then read
+// schema.table
+func (m *Model) SetSchema(schema string) *Model {
+	m.schema = schema
+	return m
+}
+
+// from returns the schema qualified table of the model
+func (m *Model) from() string {
+	return qualifyTable(m.schema, m.table)
+}
+
+// relationTable returns the schema qualified table of a relation target:
+// the schema: tag option, or else the schema of the registered target model
+func (m *Model) relationTable(rel *relation) string {
+	schema := rel.schema
+	if schema == "" && m.relManager != nil {
+		if related, ok := m.relManager.registry[rel.modelType]; ok {
+			schema = related.schema
+		}
+	}
+	return qualifyTable(schema, rel.targetTable)
+}
@@ -0,0 +1,106 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInSchema(t *testing.T) {
+	db := &MockDB{}
+	tests := []struct {
+		name     string
+		builder  *Builder
+		expected string
+	}{
+		{"Unquoted", New(db).Table("events").InSchema("analytics"), "SELECT * FROM analytics.events"},
+		{"Quoted", New(db, WithQuotedIdentifiers(true)).Table("events").InSchema("analytics").Where("events.id", "=", 1),
+			"SELECT * FROM `analytics`.`events` WHERE `events`.`id` = ?"},
+		{"Postgres", New(db, PostgresDialect, WithQuotedIdentifiers(true)).InSchema("analytics").Table("events e").Select("e.id"),
+			`SELECT "e"."id" FROM "analytics"."events" "e"`},
+		{"Already qualified", New(db).InSchema("analytics").Table("public.users"), "SELECT * FROM public.users"},
+		{"Cross-schema join", New(db, PostgresDialect, WithQuotedIdentifiers(true)).Table("events").InSchema("analytics").
+			Join("crm.users AS u", "u.id = events.user_id"),
+			`SELECT * FROM "analytics"."events" INNER JOIN "crm"."users" AS "u" ON "u"."id" = "events"."user_id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.builder.Err(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql := tt.builder.ToSQL(); sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+		})
+	}
+
+	if err := New(db).Table("events").InSchema("analytics; DROP TABLE x").Err(); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("Expected ErrInvalidIdentifier, got %v", err)
+	}
+}
+
+// SchemaMember lives in the crm schema
+type SchemaMember struct {
+	ID   int    `db:"id,pk,auto"`
+	Name string `db:"name"`
+}
+
+// SchemaVisit lives in the analytics schema and belongs to a crm member
+type SchemaVisit struct {
+	ID       int          `db:"id,pk,auto"`
+	MemberID int          `db:"member_id"`
+	Member   SchemaMember `rel:"belongsTo,localKey:member_id,foreignKey:id,schema:crm"`
+}
+
+func TestModelSchema(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnQuery(func(ctx context.Context, query string, args []interface{}) (*MockResultSet, error) {
+		if strings.Contains(query, "schema_member.*") || strings.Contains(query, "FROM crm.schema_member WHERE") {
+			return &MockResultSet{Columns: []string{"id", "name"}, Rows: [][]interface{}{{int64(5), "ann"}}}, nil
+		}
+		return &MockResultSet{
+			Columns: []string{"id", "member_id", "member__id", "member__name"},
+			Rows:    [][]interface{}{{int64(1), int64(5), int64(5), "ann"}},
+		}, nil
+	})
+	defer mock.DB.Close()
+
+	if _, err := NewModel(mock.DB, SchemaMember{}); err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	newVisits := func() *Model {
+		model, err := NewModel(mock.DB, SchemaVisit{})
+		if err != nil {
+			t.Fatalf("Failed to create model: %v", err)
+		}
+		return model.SetSchema("analytics")
+	}
+
+	if _, err := newVisits().WithJoinStrategy().With("Member").Find(ctx, 1); err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	visit, err := newVisits().With("Member").Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if member := visit.(*SchemaVisit).Member; member.Name != "ann" {
+		t.Errorf("Expected the member to be loaded, got %+v", member)
+	}
+
+	calls := mock.Calls()
+	expected := []string{
+		"SELECT schema_visit.*, member.id AS member__id, member.name AS member__name FROM analytics.schema_visit " +
+			"LEFT JOIN crm.schema_member AS member ON member.id = schema_visit.member_id WHERE schema_visit.id = ? LIMIT ?",
+		"SELECT * FROM analytics.schema_visit WHERE id = ? LIMIT ?",
+		"SELECT * FROM crm.schema_member WHERE id IN (?)",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %d queries, got %v", len(expected), calls)
+	}
+	for i, query := range expected {
+		if calls[i].Query != query {
+			t.Errorf("Query %d: expected %s\nGot: %s", i, query, calls[i].Query)
+		}
+	}
+}
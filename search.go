@@ -0,0 +1,89 @@
+package qix
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchNormalize transforms the bound pattern of a LIKE search.
+// Normalization only touches the bound value, never the column SQL.
+type SearchNormalize func(*searchNormalizer)
+
+// searchNormalizer applies the unicode form, accent folding and lowercasing in that order
+type searchNormalizer struct {
+	form      *norm.Form
+	fold      func(string) string
+	lowercase bool
+}
+
+// NormalizeNFC composes characters, e.g. "e" + U+0301 becomes "é"
+func NormalizeNFC() SearchNormalize {
+	return func(n *searchNormalizer) {
+		form := norm.NFC
+		n.form = &form
+	}
+}
+
+// NormalizeNFKC also folds compatibility characters, e.g. full-width "Ａ" becomes "A"
+func NormalizeNFKC() SearchNormalize {
+	return func(n *searchNormalizer) {
+		form := norm.NFKC
+		n.form = &form
+	}
+}
+
+// FoldAccents removes accents with fold, or with StripAccents when fold is nil.
+// A custom fold allows language specific rules without full ICU support.
+func FoldAccents(fold func(string) string) SearchNormalize {
+	return func(n *searchNormalizer) {
+		if fold == nil {
+			fold = StripAccents
+		}
+		n.fold = fold
+	}
+}
+
+// FoldCase lowercases the pattern to match a case-insensitive search
+func FoldCase() SearchNormalize {
+	return func(n *searchNormalizer) {
+		n.lowercase = true
+	}
+}
+
+// StripAccents removes combining marks, "Crème Brûlée" becomes "Creme Brulee"
+func StripAccents(s string) string {
+	decomposed := norm.NFD.String(s)
+	var b strings.Builder
+	b.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// normalizeSearch applies the options to a search pattern
+func normalizeSearch(pattern string, opts []SearchNormalize) string {
+	if len(opts) == 0 {
+		return pattern
+	}
+
+	n := &searchNormalizer{}
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	if n.form != nil {
+		pattern = n.form.String(pattern)
+	}
+	if n.fold != nil {
+		pattern = n.fold(pattern)
+	}
+	if n.lowercase {
+		pattern = strings.ToLower(pattern)
+	}
+	return pattern
+}
@@ -0,0 +1,101 @@
+package qix
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWhereLikeNormalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		opts     []SearchNormalize
+		expected string
+	}{
+		{
+			name:     "No options keeps the pattern",
+			pattern:  "%Café%",
+			expected: "%Café%",
+		},
+		{
+			name:     "NFC composes combining accents",
+			pattern:  "%Cafe\u0301%",
+			opts:     []SearchNormalize{NormalizeNFC()},
+			expected: "%Caf\u00e9%",
+		},
+		{
+			name:     "NFKC folds full-width characters",
+			pattern:  "%ＱＩＸ１２３%",
+			opts:     []SearchNormalize{NormalizeNFKC()},
+			expected: "%QIX123%",
+		},
+		{
+			name:     "Accent folding with lowercase",
+			pattern:  "%Crème Brûlée%",
+			opts:     []SearchNormalize{FoldAccents(nil), FoldCase()},
+			expected: "%creme brulee%",
+		},
+		{
+			name:     "Full-width accented input with every option",
+			pattern:  "%ＣＡＦÉ%",
+			opts:     []SearchNormalize{FoldCase(), NormalizeNFKC(), FoldAccents(nil)},
+			expected: "%cafe%",
+		},
+		{
+			name:    "Custom fold hook",
+			pattern: "%Straße%",
+			opts: []SearchNormalize{FoldAccents(func(s string) string {
+				return strings.ReplaceAll(s, "ß", "ss")
+			})},
+			expected: "%Strasse%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := New(&MockDB{}).Table("products").WhereLike("name", tt.pattern, tt.opts...)
+
+			if sql := builder.ToSQL(); sql != "SELECT * FROM products WHERE name LIKE ?" {
+				t.Errorf("Normalization must not change the SQL, got %s", sql)
+			}
+			bindings := builder.GetBindings()
+			if len(bindings) != 1 || bindings[0] != tt.expected {
+				t.Errorf("Expected binding %q, got %q", tt.expected, bindings)
+			}
+		})
+	}
+}
+
+func TestWhereLikeVariants(t *testing.T) {
+	build := func(opts ...Option) *Builder {
+		return New(&MockDB{}, opts...).Table("products").
+			Where("active", "=", true).
+			WhereNotLike("sku", "TMP-%").
+			WhereLikeInsensitive("name", "%CAFÉ%", FoldCase()).
+			OrWhereLike("code", "A%").
+			OrWhereNotLike("tag", "%old%")
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Option
+		expected string
+	}{
+		{"MySQL", MySQLDialect, "SELECT * FROM products WHERE active = ? AND sku NOT LIKE ? AND name LIKE ? COLLATE utf8mb4_general_ci OR code LIKE ? OR tag NOT LIKE ?"},
+		{"Postgres", PostgresDialect, "SELECT * FROM products WHERE active = $1 AND sku NOT LIKE $2 AND name ILIKE $3 OR code LIKE $4 OR tag NOT LIKE $5"},
+		{"SQLite", SQLiteDialect, "SELECT * FROM products WHERE active = ? AND sku NOT LIKE ? AND name LIKE ? OR code LIKE ? OR tag NOT LIKE ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := build(tt.dialect)
+			if sql := builder.ToSQL(); sql != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, sql)
+			}
+			want := []interface{}{true, "TMP-%", "%café%", "A%", "%old%"}
+			if got := builder.GetBindings(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected bindings %v, got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package qix
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+// SoftPost is soft deleted through its deleted_at column
+type SoftPost struct {
+	ID        int64      `db:"id,pk,auto"`
+	Title     string     `db:"title"`
+	DeletedAt *time.Time `db:"deleted_at,softDelete"`
+}
+
+func TestModelSoftDeletes(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().
+		Returning([]string{"id", "title", "deleted_at"}, []interface{}{int64(1), "hello", nil}).
+		OnExec(func(ctx context.Context, query string, args []interface{}) (driver.Result, error) {
+			return driver.RowsAffected(1), nil
+		})
+	defer mock.DB.Close()
+
+	// Every case uses a fresh model, reads accumulate on the model's builder
+	newPosts := func() *Model {
+		m, err := NewModel(mock.DB, SoftPost{})
+		if err != nil {
+			t.Fatalf("Failed to create model: %v", err)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		run      func(m *Model) error
+		expected []string
+	}{
+		{
+			name: "All",
+			run: func(m *Model) error {
+				if _, err := m.All(ctx); err != nil {
+					return err
+				}
+				_, err := m.All(ctx)
+				return err
+			},
+			expected: []string{
+				"SELECT * FROM soft_post WHERE deleted_at IS NULL",
+				"SELECT * FROM soft_post WHERE deleted_at IS NULL",
+			},
+		},
+		{
+			name:     "Find",
+			run:      func(m *Model) error { _, err := m.Find(ctx, 1); return err },
+			expected: []string{"SELECT * FROM soft_post WHERE id = ? AND deleted_at IS NULL LIMIT ?"},
+		},
+		{
+			name:     "Where",
+			run:      func(m *Model) error { _, err := m.Where(ctx, "title", "=", "hello"); return err },
+			expected: []string{"SELECT * FROM soft_post WHERE title = ? AND deleted_at IS NULL"},
+		},
+		{
+			name:     "First",
+			run:      func(m *Model) error { _, err := m.First(ctx); return err },
+			expected: []string{"SELECT * FROM soft_post WHERE deleted_at IS NULL LIMIT ?"},
+		},
+		{
+			name:     "WithTrashed",
+			run:      func(m *Model) error { _, err := m.WithTrashed().Find(ctx, 1); return err },
+			expected: []string{"SELECT * FROM soft_post WHERE id = ? LIMIT ?"},
+		},
+		{
+			name:     "OnlyTrashed",
+			run:      func(m *Model) error { _, err := m.OnlyTrashed().All(ctx); return err },
+			expected: []string{"SELECT * FROM soft_post WHERE deleted_at IS NOT NULL"},
+		},
+		{
+			name:     "Delete",
+			run:      func(m *Model) error { _, err := m.Delete(ctx, 1); return err },
+			expected: []string{"UPDATE soft_post SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"},
+		},
+		{
+			name:     "ForceDelete",
+			run:      func(m *Model) error { _, err := m.ForceDelete(ctx, 1); return err },
+			expected: []string{"DELETE FROM soft_post WHERE id = ?"},
+		},
+		{
+			name:     "Restore",
+			run:      func(m *Model) error { _, err := m.Restore(ctx, 1); return err },
+			expected: []string{"UPDATE soft_post SET deleted_at = ? WHERE id = ? AND deleted_at IS NOT NULL"},
+		},
+		{
+			name:     "Disabled",
+			run:      func(m *Model) error { _, err := m.SetSoftDeleteColumn("").Delete(ctx, 1); return err },
+			expected: []string{"DELETE FROM soft_post WHERE id = ?"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(mock.Calls())
+			if err := tt.run(newPosts()); err != nil {
+				t.Fatalf("%s failed: %v", tt.name, err)
+			}
+			calls := mock.Calls()[before:]
+			if len(calls) != len(tt.expected) {
+				t.Fatalf("Expected %d statements, got %+v", len(tt.expected), calls)
+			}
+			for i, call := range calls {
+				if call.Query != tt.expected[i] {
+					t.Errorf("Expected SQL: %s\nGot: %s", tt.expected[i], call.Query)
+				}
+			}
+		})
+	}
+
+	calls := mock.Calls()
+	for _, call := range calls {
+		switch call.Query {
+		case "UPDATE soft_post SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL":
+			if _, ok := call.Args[0].(time.Time); !ok {
+				t.Errorf("Expected Delete to bind the deletion time, got %v", call.Args)
+			}
+		case "UPDATE soft_post SET deleted_at = ? WHERE id = ? AND deleted_at IS NOT NULL":
+			if call.Args[0] != nil {
+				t.Errorf("Expected Restore to bind NULL, got %v", call.Args)
+			}
+		}
+	}
+}
+
+// SoftPinnedPost always shows pinned posts through an OR in its hook
+type SoftPinnedPost struct {
+	ID        int64      `db:"id,pk,auto"`
+	Pinned    bool       `db:"pinned"`
+	DeletedAt *time.Time `db:"deleted_at,softDelete"`
+}
+
+func (SoftPinnedPost) BeforeSelect(q *Builder) {
+	q.OrWhere("pinned", "=", true)
+}
+
+func TestModelSoftDeleteGroupsOrConditions(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"id"}, []interface{}{int64(1)})
+	defer mock.DB.Close()
+
+	posts, err := NewModel(mock.DB, SoftPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	posts.Query().Where("title", "=", "a").OrWhere("title", "=", "b")
+	if _, err := posts.All(ctx); err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	if _, err := posts.OnlyTrashed().First(ctx); err != nil {
+		t.Fatalf("First failed: %v", err)
+	}
+
+	pinned, err := NewModel(mock.DB, SoftPinnedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if _, err := pinned.Find(ctx, 1); err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	expected := []string{
+		"SELECT * FROM soft_post WHERE (title = ? OR title = ?) AND deleted_at IS NULL",
+		"SELECT * FROM soft_post WHERE (title = ? OR title = ?) AND deleted_at IS NOT NULL LIMIT ?",
+		"SELECT * FROM soft_pinned_post WHERE (id = ? OR pinned = ?) AND deleted_at IS NULL LIMIT ?",
+	}
+	calls := mock.Calls()
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %d queries, got %+v", len(expected), calls)
+	}
+	for i, call := range calls {
+		if call.Query != expected[i] {
+			t.Errorf("Expected SQL: %s\nGot: %s", expected[i], call.Query)
+		}
+	}
+	if args := calls[2].Args; len(args) != 3 || args[1] != true {
+		t.Errorf("Unexpected bindings %v", args)
+	}
+}
+
+func TestModelSoftDeleteColumn(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().Returning([]string{"count"}, []interface{}{int64(2)})
+	defer mock.DB.Close()
+
+	m, err := NewModel(mock.DB, TestUser{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if _, err := m.SetSoftDeleteColumn("removed_at").Paginate(ctx, 1, 10); err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Query != "SELECT COUNT(*) FROM test_user WHERE removed_at IS NULL LIMIT ?" ||
+		calls[1].Query != "SELECT * FROM test_user WHERE removed_at IS NULL LIMIT ? OFFSET ?" {
+		t.Errorf("Unexpected paginate SQL: %+v", calls)
+	}
+}
@@ -0,0 +1,132 @@
+package qix
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// changeTracker keeps the column values of loaded rows by primary key
+type changeTracker struct {
+	mu        sync.Mutex
+	originals map[interface{}]map[string]interface{}
+}
+
+// TrackChanges enables dirty tracking: rows loaded through the model are
+// remembered by primary key and Update only writes the columns that changed.
+// An update without changes is skipped and reports 0 affected rows.
+// Snapshots are kept for the lifetime of the model.
+func (m *Model) TrackChanges() *Model {
+	if m.tracker == nil {
+		m.tracker = &changeTracker{originals: make(map[interface{}]map[string]interface{})}
+	}
+	return m
+}
+
+// Dirty returns the columns of data that differ from the loaded row.
+// ok is false when data was not loaded through the model with tracking enabled.
+func (m *Model) Dirty(data interface{}) (changes map[string]interface{}, ok bool) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if m.tracker == nil || v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	original, ok := m.tracker.get(m.pkValue(v))
+	if !ok {
+		return nil, false
+	}
+
+	changes = make(map[string]interface{})
+	for column, value := range m.columnValues(v) {
+		if !sameValue(original[column], value) {
+			changes[column] = value
+		}
+	}
+	return changes, true
+}
+
+// remember stores the current values of a scanned row
+func (m *Model) remember(v reflect.Value) {
+	if m.tracker == nil {
+		return
+	}
+	if pk := m.pkValue(v); pk != nil {
+		m.tracker.set(pk, m.columnValues(v))
+	}
+}
+
+// pkValue returns the primary key of a struct value, nil when it has none
+func (m *Model) pkValue(v reflect.Value) interface{} {
+	field := v.FieldByName(getPkFieldName(m.fields, m.pk))
+	if !field.IsValid() {
+		return nil
+	}
+	return normalizeKey(field.Interface())
+}
+
+// columnValues returns the persisted columns of a struct value
+func (m *Model) columnValues(v reflect.Value) map[string]interface{} {
+	values := make(map[string]interface{}, len(m.fields))
+	for _, f := range m.fields {
+		if f.omit || f.relation != nil {
+			continue
+		}
+		if field := v.FieldByName(f.name); field.IsValid() {
+			values[f.column] = field.Interface()
+		}
+	}
+	return values
+}
+
+func (t *changeTracker) get(pk interface{}) (map[string]interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	values, ok := t.originals[pk]
+	return values, ok
+}
+
+func (t *changeTracker) set(pk interface{}, values map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.originals[pk] = values
+}
+
+// sameValue compares column values, times are compared by instant
+func sameValue(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// timestampField returns the field flagged with the given timestamp option
+func (m *Model) timestampField(updated bool) *Field {
+	for i := range m.fields {
+		if (updated && m.fields[i].updatedAt) || (!updated && m.fields[i].createdAt) {
+			return &m.fields[i]
+		}
+	}
+	return nil
+}
+
+// touch sets a timestamp column in values and on data when it is addressable
+func (m *Model) touch(data interface{}, values map[string]interface{}, f *Field, now time.Time, onlyZero bool) {
+	if f == nil {
+		return
+	}
+
+	field := reflect.Indirect(reflect.ValueOf(data)).FieldByName(f.name)
+	if onlyZero && field.IsValid() && !field.IsZero() {
+		return
+	}
+
+	var value interface{} = now
+	if field.IsValid() && field.Kind() == reflect.Ptr {
+		value = &now
+	}
+	values[f.column] = value
+	if field.IsValid() && field.CanSet() {
+		field.Set(reflect.ValueOf(value))
+	}
+}
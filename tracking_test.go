@@ -0,0 +1,154 @@
+package qix
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TrackedPost is a model with timestamp columns
+type TrackedPost struct {
+	ID        int       `db:"id,pk,auto"`
+	Title     string    `db:"title"`
+	CreatedAt time.Time `db:"created_at,created"`
+	UpdatedAt time.Time `db:"updated_at,updated"`
+}
+
+func newTrackedPostMock(stamp time.Time) *MockSQL {
+	return NewMockSQL().
+		Returning([]string{"id", "title", "created_at", "updated_at"},
+			[]interface{}{int64(1), "hello", stamp, stamp}).
+		OnExec(func(ctx context.Context, query string, args []interface{}) (driver.Result, error) {
+			return insertResult(1), nil
+		})
+}
+
+func TestModelUpdateTracksChanges(t *testing.T) {
+	ctx := context.Background()
+	stamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := newTrackedPostMock(stamp)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	model.TrackChanges()
+
+	found, err := model.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	post := found.(*TrackedPost)
+
+	if changes, ok := model.Dirty(post); !ok || len(changes) != 0 {
+		t.Fatalf("Expected a clean tracked row, got %v (tracked: %v)", changes, ok)
+	}
+
+	post.Title = "updated"
+	affected, err := model.Update(ctx, post)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("Expected 1 affected row, got %d", affected)
+	}
+
+	calls := mock.Calls()
+	last := calls[len(calls)-1]
+	if !strings.HasPrefix(last.Query, "UPDATE tracked_post SET ") {
+		t.Fatalf("Expected an update, got %s", last.Query)
+	}
+	if !strings.Contains(last.Query, "title = ?") || !strings.Contains(last.Query, "updated_at = ?") {
+		t.Errorf("Expected title and updated_at to be written, got %s", last.Query)
+	}
+	if strings.Contains(last.Query, "created_at") {
+		t.Errorf("Expected unchanged columns to be left out, got %s", last.Query)
+	}
+	if !post.UpdatedAt.After(stamp) {
+		t.Errorf("Expected updated_at to be bumped, got %v", post.UpdatedAt)
+	}
+	if !post.CreatedAt.Equal(stamp) {
+		t.Errorf("Expected created_at to be untouched, got %v", post.CreatedAt)
+	}
+}
+
+func TestModelUpdateSkipsCleanRow(t *testing.T) {
+	ctx := context.Background()
+	stamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := newTrackedPostMock(stamp)
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	model.TrackChanges()
+
+	found, err := model.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	post := found.(*TrackedPost)
+	post.Title = "hello"
+
+	before := len(mock.Calls())
+	affected, err := model.Update(ctx, post)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if affected != 0 {
+		t.Errorf("Expected 0 affected rows, got %d", affected)
+	}
+	if calls := mock.Calls(); len(calls) != before {
+		t.Errorf("Expected no statement to be executed, got %s", calls[len(calls)-1].Query)
+	}
+	if !post.UpdatedAt.Equal(stamp) {
+		t.Errorf("Expected updated_at to be untouched, got %v", post.UpdatedAt)
+	}
+}
+
+func TestModelCreateSetsTimestamps(t *testing.T) {
+	ctx := context.Background()
+	mock := newTrackedPostMock(time.Time{})
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	post := &TrackedPost{Title: "new"}
+	if _, err := model.Create(ctx, post); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if post.CreatedAt.IsZero() || !post.CreatedAt.Equal(post.UpdatedAt) {
+		t.Errorf("Expected both timestamps to be set, got %v and %v", post.CreatedAt, post.UpdatedAt)
+	}
+}
+
+func TestModelUpdateBindsSetBeforeWhere(t *testing.T) {
+	mock := newTrackedPostMock(time.Now())
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TrackedPost{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	if _, err := model.Update(context.Background(), &TrackedPost{ID: 7, Title: "hello"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	calls := mock.Calls()
+	last := calls[len(calls)-1]
+	expected := "UPDATE tracked_post SET created_at = ?, id = ?, title = ?, updated_at = ? WHERE id = ?"
+	if last.Query != expected {
+		t.Fatalf("Expected SQL: %s\nGot: %s", expected, last.Query)
+	}
+	if len(last.Args) != 5 || last.Args[2] != "hello" || last.Args[4] != int64(7) {
+		t.Errorf("Expected title before the primary key, got %v", last.Args)
+	}
+}
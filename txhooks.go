@@ -0,0 +1,122 @@
+package qix
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// txHooks collects the callbacks registered during a transaction, shared by
+// every builder of the transaction
+type txHooks struct {
+	mu         sync.Mutex
+	commit     []func(context.Context)
+	rollback   []func(context.Context)
+	savepoints []txHookMark // Open savepoints, innermost last
+}
+
+// txHookMark is the number of hooks registered before a savepoint
+type txHookMark struct {
+	commit, rollback int
+}
+
+// AfterCommit registers fn to run once the transaction of the builder
+// commits, in registration order. Hooks registered in a nested Model
+// transaction wait for the outermost commit and are dropped when their
+// savepoint is rolled back. Outside Transaction fn runs immediately.
+func (b *Builder) AfterCommit(fn func(ctx context.Context)) *Builder {
+	if b.txHooks == nil {
+		runTxHooks(context.Background(), "after commit", []func(context.Context){fn})
+		return b
+	}
+	b.txHooks.mu.Lock()
+	b.txHooks.commit = append(b.txHooks.commit, fn)
+	b.txHooks.mu.Unlock()
+	return b
+}
+
+// AfterRollback registers fn to run once the transaction of the builder
+// is rolled back, in registration order. Hooks registered in a nested Model
+// transaction run when their savepoint is rolled back. Outside Transaction
+// fn is never called.
+func (b *Builder) AfterRollback(fn func(ctx context.Context)) *Builder {
+	if b.txHooks == nil {
+		return b
+	}
+	b.txHooks.mu.Lock()
+	b.txHooks.rollback = append(b.txHooks.rollback, fn)
+	b.txHooks.mu.Unlock()
+	return b
+}
+
+// AfterCommit registers fn on the transaction of the model, see Builder.AfterCommit
+func (m *Model) AfterCommit(fn func(ctx context.Context)) *Model {
+	m.builder.AfterCommit(fn)
+	return m
+}
+
+// AfterRollback registers fn on the transaction of the model, see Builder.AfterRollback
+func (m *Model) AfterRollback(fn func(ctx context.Context)) *Model {
+	m.builder.AfterRollback(fn)
+	return m
+}
+
+// committed runs the commit hooks of the transaction
+func (h *txHooks) committed(ctx context.Context) {
+	h.mu.Lock()
+	hooks := h.commit
+	h.commit, h.rollback = nil, nil
+	h.mu.Unlock()
+	runTxHooks(ctx, "after commit", hooks)
+}
+
+// rolledBack runs the rollback hooks of the transaction
+func (h *txHooks) rolledBack(ctx context.Context) {
+	h.mu.Lock()
+	hooks := h.rollback
+	h.commit, h.rollback = nil, nil
+	h.mu.Unlock()
+	runTxHooks(ctx, "after rollback", hooks)
+}
+
+// savepoint marks the hooks registered before a nested transaction
+func (h *txHooks) savepoint() {
+	h.mu.Lock()
+	h.savepoints = append(h.savepoints, txHookMark{len(h.commit), len(h.rollback)})
+	h.mu.Unlock()
+}
+
+// releaseSavepoint keeps the hooks of a nested transaction for the outer one
+func (h *txHooks) releaseSavepoint() {
+	h.mu.Lock()
+	h.savepoints = h.savepoints[:len(h.savepoints)-1]
+	h.mu.Unlock()
+}
+
+// rollbackSavepoint drops the commit hooks of a nested transaction and
+// runs its rollback hooks
+func (h *txHooks) rollbackSavepoint(ctx context.Context) {
+	h.mu.Lock()
+	mark := h.savepoints[len(h.savepoints)-1]
+	h.savepoints = h.savepoints[:len(h.savepoints)-1]
+	hooks := slices.Clone(h.rollback[mark.rollback:])
+	h.commit, h.rollback = h.commit[:mark.commit], h.rollback[:mark.rollback]
+	h.mu.Unlock()
+	runTxHooks(ctx, "after rollback", hooks)
+}
+
+// runTxHooks calls each hook, a panicking hook is logged and doesn't stop
+// the others or change the result of the transaction
+func runTxHooks(ctx context.Context, name string, hooks []func(context.Context)) {
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					currentLogger().Warnf("%s hook panicked: %v", name, fmt.Sprint(r))
+				}
+			}()
+			hook(ctx)
+		}()
+	}
+}
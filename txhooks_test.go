@@ -0,0 +1,111 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAfterCommitHooks(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	var calls []string
+	err := New(mock.DB).Transaction(ctx, func(tx *Builder) error {
+		tx.AfterCommit(func(context.Context) { calls = append(calls, "first") })
+		tx.Table("users").AfterCommit(func(context.Context) { calls = append(calls, "second") })
+		tx.AfterRollback(func(context.Context) { calls = append(calls, "rollback") })
+		if len(calls) != 0 {
+			t.Error("Hooks ran before the commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"first", "second"}) {
+		t.Errorf("Expected the commit hooks in order, got %v", calls)
+	}
+
+	calls = nil
+	New(mock.DB).AfterCommit(func(context.Context) { calls = append(calls, "now") })
+	if !reflect.DeepEqual(calls, []string{"now"}) {
+		t.Errorf("Expected the hook to run outside a transaction, got %v", calls)
+	}
+}
+
+func TestAfterRollbackHooks(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	logs := &recordingLogger{}
+	SetLogger(logs)
+	defer SetLogger(nil)
+
+	failure := errors.New("failure")
+	var calls []string
+	err := New(mock.DB).Transaction(ctx, func(tx *Builder) error {
+		tx.AfterCommit(func(context.Context) { calls = append(calls, "commit") })
+		tx.AfterRollback(func(context.Context) { panic("boom") })
+		tx.AfterRollback(func(context.Context) { calls = append(calls, "rollback") })
+		return failure
+	})
+	if err != failure {
+		t.Errorf("Expected the transaction error, got %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"rollback"}) {
+		t.Errorf("Expected only the rollback hook, got %v", calls)
+	}
+	if warnings := logs.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "boom") {
+		t.Errorf("Expected the panic to be logged, got %v", warnings)
+	}
+}
+
+func TestNestedTransactionHooks(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL()
+	defer mock.DB.Close()
+
+	model, err := NewModel(mock.DB, TestUser{})
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+
+	var calls []string
+	record := func(name string) func(context.Context) {
+		return func(context.Context) { calls = append(calls, name) }
+	}
+	err = model.Transaction(ctx, func(tx *Model) error {
+		tx.AfterCommit(record("outer"))
+
+		err := tx.Transaction(ctx, func(inner *Model) error {
+			inner.AfterCommit(record("released"))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		tx.Transaction(ctx, func(inner *Model) error {
+			inner.AfterCommit(record("discarded"))
+			inner.AfterRollback(record("savepoint rollback"))
+			return errors.New("undo")
+		})
+		if !reflect.DeepEqual(calls, []string{"savepoint rollback"}) {
+			t.Errorf("Expected only the savepoint rollback hook before the commit, got %v", calls)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	expected := []string{"savepoint rollback", "outer", "released"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+}
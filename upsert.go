@@ -0,0 +1,128 @@
+package qix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Upsert inserts rows and updates the existing ones that conflict on the
+// uniqueBy columns, using ON DUPLICATE KEY UPDATE for MySQL and
+// ON CONFLICT ... DO UPDATE for Postgres and SQLite. updateColumns lists the
+// columns refreshed on conflict, nil updates every column that isn't in
+// uniqueBy. With nothing left to update conflicting rows are kept as they
+// are: INSERT IGNORE for MySQL, ON CONFLICT DO NOTHING otherwise. Columns
+// are taken from the first row in sorted order, so every row binds its
+// values in the same order; every row must have the same columns, including
+// the uniqueBy ones. Empty data is a no-op.
+func (b *Builder) Upsert(ctx context.Context, data []map[string]interface{}, uniqueBy []string, updateColumns []string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if b.table == "" {
+		return errors.New("table name is required")
+	}
+	if len(uniqueBy) == 0 {
+		return errors.New("upsert requires at least one unique column")
+	}
+
+	columns := sortedKeys(data[0])
+	for i, values := range data[1:] {
+		// A missing key would bind NULL and overwrite the existing value
+		if !sameKeys(values, data[0]) {
+			return fmt.Errorf("upsert row %d has different columns than the first row", i+1)
+		}
+	}
+	unique := make(map[string]bool, len(uniqueBy))
+	for _, column := range uniqueBy {
+		if _, ok := data[0][column]; !ok {
+			return fmt.Errorf("unique column %q is missing from the rows", column)
+		}
+		unique[column] = true
+	}
+	if updateColumns == nil {
+		updateColumns = columns
+	}
+
+	var update []string
+	for _, column := range updateColumns {
+		if !unique[column] {
+			update = append(update, b.quote(column))
+		}
+	}
+
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	rows := make([]string, len(data))
+	args := make([]interface{}, 0, len(data)*len(columns))
+	for i, values := range data {
+		normalized, err := normalizeRow(columns, values)
+		if err != nil {
+			return err
+		}
+		rows[i] = row
+		args = append(args, normalized...)
+	}
+
+	dialect := b.dialect
+	if dialect == nil {
+		dialect = MySQLDialect
+	}
+
+	insert := "INSERT INTO "
+	if _, ok := dialect.(mysqlDialect); ok && len(update) == 0 {
+		insert = "INSERT IGNORE INTO "
+	}
+
+	query := insert + b.quote(b.table) +
+		" (" + strings.Join(b.quoteAll(columns), ", ") + ") VALUES " + strings.Join(rows, ", ") +
+		dialect.UpsertClause(b.quoteAll(uniqueBy), update)
+
+	_, err := b.execContext(ctx, query, args...)
+	return err
+}
+
+// InsertOrIgnore inserts rows in a single statement and skips those
+// conflicting with an existing unique key: INSERT IGNORE for MySQL,
+// INSERT OR IGNORE for SQLite and ON CONFLICT DO NOTHING for Postgres. It
+// returns the number of rows actually inserted. Columns are taken from the
+// first row in sorted order, as in BatchInsert.
+func (b *Builder) InsertOrIgnore(ctx context.Context, data ...map[string]interface{}) (int64, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if b.table == "" {
+		return 0, errors.New("table name is required")
+	}
+
+	insert, conflict := "INSERT IGNORE INTO ", ""
+	switch b.dialect.(type) {
+	case sqliteDialect:
+		insert = "INSERT OR IGNORE INTO "
+	case postgresDialect:
+		insert, conflict = "INSERT INTO ", " ON CONFLICT DO NOTHING"
+	}
+
+	query, args, err := b.insertSQL(insert, sortedKeys(data[0]), data)
+	if err != nil {
+		return 0, err
+	}
+	result, err := b.execContext(ctx, query+conflict, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// sameKeys reports whether a and b have the same keys
+func sameKeys(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	ctx := context.Background()
+	rows := []map[string]interface{}{
+		{"email": "ann@example.com", "name": "Ann", "visits": 1},
+		{"email": "ben@example.com", "name": "Ben", "visits": 2},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		update   []string
+		expected string
+	}{
+		{
+			name:     "MySQL",
+			dialect:  MySQLDialect,
+			expected: "INSERT INTO users (email, name, visits) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name), visits = VALUES(visits)",
+		},
+		{
+			name:     "Postgres",
+			dialect:  PostgresDialect,
+			expected: "INSERT INTO users (email, name, visits) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, visits = EXCLUDED.visits",
+		},
+		{
+			name:     "UniqueColumnsNotUpdated",
+			dialect:  PostgresDialect,
+			update:   []string{"email", "visits"},
+			expected: "INSERT INTO users (email, name, visits) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (email) DO UPDATE SET visits = EXCLUDED.visits",
+		},
+		{
+			name:     "MySQLInsertIgnore",
+			dialect:  MySQLDialect,
+			update:   []string{},
+			expected: "INSERT IGNORE INTO users (email, name, visits) VALUES (?, ?, ?), (?, ?, ?)",
+		},
+		{
+			name:     "DefaultDialectInsertIgnore",
+			update:   []string{"email"},
+			expected: "INSERT IGNORE INTO users (email, name, visits) VALUES (?, ?, ?), (?, ?, ?)",
+		},
+		{
+			name:     "SQLiteDoNothing",
+			dialect:  SQLiteDialect,
+			update:   []string{},
+			expected: "INSERT INTO users (email, name, visits) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (email) DO NOTHING",
+		},
+		{
+			name:     "NothingToUpdate",
+			dialect:  PostgresDialect,
+			update:   []string{"email"},
+			expected: "INSERT INTO users (email, name, visits) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (email) DO NOTHING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			var args []interface{}
+			db := &MockDB{
+				execFunc: func(ctx context.Context, q string, a ...interface{}) (sql.Result, error) {
+					query, args = q, a
+					return MockResult{rowsAffected: 2}, nil
+				},
+			}
+
+			err := New(db, WithDialect(tt.dialect)).Table("users").Upsert(ctx, rows, []string{"email"}, tt.update)
+			if err != nil {
+				t.Fatalf("Upsert failed: %v", err)
+			}
+			if query != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, query)
+			}
+			if len(args) != 6 || args[0] != "ann@example.com" || args[5] != 2 {
+				t.Errorf("Unexpected bindings: %v", args)
+			}
+		})
+	}
+}
+
+func TestUpsertEmptyData(t *testing.T) {
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			t.Errorf("Expected no statement, got %s", query)
+			return MockResult{}, nil
+		},
+	}
+	if err := New(db).Table("users").Upsert(context.Background(), nil, []string{"email"}, nil); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestUpsertRequiresUniqueColumns(t *testing.T) {
+	rows := []map[string]interface{}{{"email": "ann@example.com"}}
+	if err := New(&MockDB{}).Table("users").Upsert(context.Background(), rows, nil, nil); err == nil {
+		t.Error("Expected error without unique columns")
+	}
+}
+
+func TestUpsertRejectsMismatchedRows(t *testing.T) {
+	ctx := context.Background()
+	builder := New(&MockDB{}).Table("users")
+
+	rows := []map[string]interface{}{
+		{"email": "ann@example.com", "name": "Ann"},
+		{"email": "ben@example.com"},
+	}
+	if err := builder.Upsert(ctx, rows, []string{"email"}, nil); err == nil {
+		t.Error("Expected error for a row missing a column")
+	}
+
+	rows = []map[string]interface{}{{"name": "Ann"}}
+	if err := builder.Upsert(ctx, rows, []string{"email"}, nil); err == nil {
+		t.Error("Expected error for a unique column missing from the rows")
+	}
+}
+
+func TestUpsertInTransaction(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockSQL().OnExec(func(ctx context.Context, query string, args []interface{}) (driver.Result, error) {
+		return driver.RowsAffected(1), nil
+	})
+	defer mock.DB.Close()
+
+	err := New(mock.DB).Transaction(ctx, func(tx *Builder) error {
+		return tx.Table("users").Upsert(ctx, []map[string]interface{}{{"email": "ann@example.com", "name": "Ann"}}, []string{"email"}, nil)
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	if mock.begins != 1 || mock.commits != 1 {
+		t.Errorf("Expected 1 begin and 1 commit, got %d and %d", mock.begins, mock.commits)
+	}
+	calls := mock.Calls()
+	if len(calls) != 1 || calls[0].Query != "INSERT INTO users (email, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)" {
+		t.Errorf("Unexpected calls: %+v", calls)
+	}
+}
+
+func TestInsertOrIgnore(t *testing.T) {
+	ctx := context.Background()
+	rows := []map[string]interface{}{
+		{"name": "Ann", "email": "ann@example.com"},
+		{"name": "Ben", "email": "ben@example.com"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected string
+	}{
+		{
+			name:     "Default",
+			expected: "INSERT IGNORE INTO users (email, name) VALUES (?, ?), (?, ?)",
+		},
+		{
+			name:     "SQLite",
+			dialect:  SQLiteDialect,
+			expected: "INSERT OR IGNORE INTO users (email, name) VALUES (?, ?), (?, ?)",
+		},
+		{
+			name:     "Postgres",
+			dialect:  PostgresDialect,
+			expected: "INSERT INTO users (email, name) VALUES ($1, $2), ($3, $4) ON CONFLICT DO NOTHING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			var args []interface{}
+			db := &MockDB{
+				execFunc: func(ctx context.Context, q string, a ...interface{}) (sql.Result, error) {
+					query, args = q, a
+					return MockResult{rowsAffected: 1}, nil
+				},
+			}
+
+			inserted, err := New(db, WithDialect(tt.dialect)).Table("users").InsertOrIgnore(ctx, rows...)
+			if err != nil || inserted != 1 {
+				t.Fatalf("Expected 1 inserted row, got %d (%v)", inserted, err)
+			}
+			if query != tt.expected {
+				t.Errorf("Expected SQL: %s\nGot: %s", tt.expected, query)
+			}
+			if len(args) != 4 || args[0] != "ann@example.com" || args[3] != "Ben" {
+				t.Errorf("Unexpected bindings: %v", args)
+			}
+		})
+	}
+
+	inserted, err := New(&MockDB{}).Table("users").InsertOrIgnore(ctx)
+	if err != nil || inserted != 0 {
+		t.Errorf("Expected no-op for no rows, got %d (%v)", inserted, err)
+	}
+}
@@ -0,0 +1,199 @@
+package qix
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidatorFunc reports whether value satisfies a rule, param is the text
+// after "=" in the tag (empty when the rule has no parameter)
+type ValidatorFunc func(value interface{}, param string) bool
+
+// ValidationError describes a single failed rule
+type ValidationError struct {
+	Field string
+	Rule  string
+	Param string
+	Value interface{}
+}
+
+func (e ValidationError) Error() string {
+	rule := e.Rule
+	if e.Param != "" {
+		rule += "=" + e.Param
+	}
+	return fmt.Sprintf("field %s failed %s validation (value: %v)", e.Field, rule, e.Value)
+}
+
+// ValidationErrors lists every failed rule of a model
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// validationRule is one entry of a validate tag
+type validationRule struct {
+	name  string
+	param string
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]ValidatorFunc{
+		"required": validateRequired,
+		"min":      validateMin,
+		"max":      validateMax,
+		"email":    validateEmail,
+		"oneof":    validateOneOf,
+	}
+)
+
+// RegisterValidator adds or replaces a rule usable in validate tags
+func RegisterValidator(name string, fn ValidatorFunc) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+func lookupValidator(name string) (ValidatorFunc, bool) {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	fn, ok := validators[name]
+	return fn, ok
+}
+
+// parseValidateTag parses a tag like "required,max=255,oneof=a b c"
+func parseValidateTag(tag string) []validationRule {
+	if tag == "" || tag == "-" {
+		return nil
+	}
+
+	var rules []validationRule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, validationRule{name: name, param: param})
+	}
+	return rules
+}
+
+// validate runs the validate tag rules of every field against data
+func (m *Model) validate(data interface{}) error {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var failures ValidationErrors
+	for _, f := range m.fields {
+		if len(f.rules) == 0 {
+			continue
+		}
+
+		fieldVal := v.FieldByName(f.name)
+		if !fieldVal.IsValid() {
+			continue
+		}
+
+		isNil := fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil()
+		var value interface{}
+		if !isNil {
+			value = reflect.Indirect(fieldVal).Interface()
+		}
+
+		for _, rule := range f.rules {
+			fn, ok := lookupValidator(rule.name)
+			if !ok {
+				return fmt.Errorf("unknown validation rule %q on field %s", rule.name, f.name)
+			}
+			// Only required applies to a nil pointer, other rules need a value
+			if isNil && rule.name != "required" {
+				continue
+			}
+			if !fn(value, rule.param) {
+				failures = append(failures, ValidationError{
+					Field: f.name,
+					Rule:  rule.name,
+					Param: rule.param,
+					Value: value,
+				})
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return failures
+	}
+	return nil
+}
+
+func validateRequired(value interface{}, _ string) bool {
+	return value != nil && !reflect.ValueOf(value).IsZero()
+}
+
+func validateMin(value interface{}, param string) bool {
+	size, limit, ok := measure(value, param)
+	return !ok || size >= limit
+}
+
+func validateMax(value interface{}, param string) bool {
+	size, limit, ok := measure(value, param)
+	return !ok || size <= limit
+}
+
+// measure returns the length of strings and collections or the value of
+// numbers, ok is false for types the size rules don't apply to
+func measure(value interface{}, param string) (size, limit float64, ok bool) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), limit, true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), limit, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), limit, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), limit, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), limit, true
+	}
+	return 0, 0, false
+}
+
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+func validateEmail(value interface{}, _ string) bool {
+	s, ok := value.(string)
+	return ok && emailPattern.MatchString(s)
+}
+
+func validateOneOf(value interface{}, param string) bool {
+	actual := fmt.Sprint(value)
+	for _, option := range strings.Fields(param) {
+		if actual == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package qix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Signup struct {
+	ID       int     `db:"id,pk,auto"`
+	Name     string  `db:"name" validate:"required,max=10"`
+	Email    string  `db:"email" validate:"required,email"`
+	Age      int     `db:"age" validate:"min=18,max=120"`
+	Plan     string  `db:"plan" validate:"oneof=free pro team"`
+	Referrer *string `db:"referrer" validate:"min=3"`
+	Handle   string  `db:"handle" validate:"slug"`
+}
+
+// registerSlugValidator registers the slug rule of Signup for the duration
+// of the test, restoring the previous registry afterwards
+func registerSlugValidator(t *testing.T) {
+	t.Helper()
+	previous, existed := lookupValidator("slug")
+	RegisterValidator("slug", func(value interface{}, _ string) bool {
+		s, _ := value.(string)
+		return s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyz0123456789-") == ""
+	})
+	t.Cleanup(func() {
+		validatorsMu.Lock()
+		defer validatorsMu.Unlock()
+		if existed {
+			validators["slug"] = previous
+		} else {
+			delete(validators, "slug")
+		}
+	})
+}
+
+func TestModelValidationCollectsAllFailures(t *testing.T) {
+	registerSlugValidator(t)
+
+	var executed bool
+	db := &MockDB{
+		execFunc: func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			executed = true
+			return MockResult{lastID: 1, rowsAffected: 1}, nil
+		},
+	}
+	model, err := NewModel(db, Signup{})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	invalid := Signup{
+		Name:   "A name that is far too long",
+		Email:  "not-an-email",
+		Age:    12,
+		Plan:   "enterprise",
+		Handle: "Not A Slug",
+	}
+
+	_, err = model.Create(context.Background(), &invalid)
+
+	var failures ValidationErrors
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	if executed {
+		t.Error("Expected no statement to reach the database")
+	}
+
+	expected := []struct{ field, rule string }{
+		{"Name", "max"},
+		{"Email", "email"},
+		{"Age", "min"},
+		{"Plan", "oneof"},
+		{"Handle", "slug"},
+	}
+	if len(failures) != len(expected) {
+		t.Fatalf("Expected %d failures, got %d: %v", len(expected), len(failures), failures)
+	}
+	for i, want := range expected {
+		if failures[i].Field != want.field || failures[i].Rule != want.rule {
+			t.Errorf("Failure %d: expected %s/%s, got %s/%s", i, want.field, want.rule, failures[i].Field, failures[i].Rule)
+		}
+	}
+	if failures[2].Value != 12 {
+		t.Errorf("Expected failing value 12, got %v", failures[2].Value)
+	}
+
+	short := "ab"
+	valid := Signup{Name: "Ana", Email: "ana@example.com", Age: 30, Plan: "pro", Handle: "ana-1"}
+	if _, err := model.Create(context.Background(), &valid); err != nil {
+		t.Errorf("Expected valid signup to pass, got %v", err)
+	}
+
+	valid.ID = 1
+	valid.Referrer = &short
+	if _, err := model.Update(context.Background(), &valid); !errors.As(err, &failures) || len(failures) != 1 || failures[0].Field != "Referrer" {
+		t.Errorf("Expected Referrer min failure on update, got %v", err)
+	}
+}
+
+func TestModelValidationRequired(t *testing.T) {
+	registerSlugValidator(t)
+	model, _ := NewModel(&MockDB{}, Signup{})
+
+	_, err := model.Create(context.Background(), Signup{Age: 20, Plan: "free", Handle: "x"})
+
+	var failures ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 3 {
+		t.Fatalf("Expected 3 failures, got %v", err)
+	}
+	if failures[0].Field != "Name" || failures[0].Rule != "required" {
+		t.Errorf("Expected Name required failure first, got %+v", failures[0])
+	}
+}